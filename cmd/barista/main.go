@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -17,12 +19,26 @@ import (
 	"github.com/indeedhat/barista/internal/coffee"
 	"github.com/indeedhat/barista/internal/coffee/controllers"
 	"github.com/indeedhat/barista/internal/database"
+	"github.com/indeedhat/barista/internal/equipment"
+	"github.com/indeedhat/barista/internal/equipment/controllers"
+	"github.com/indeedhat/barista/internal/photo"
+	"github.com/indeedhat/barista/internal/photo/controllers"
+	"github.com/indeedhat/barista/internal/roasting"
+	"github.com/indeedhat/barista/internal/roasting/controllers"
 	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/storage"
+	"github.com/indeedhat/barista/internal/tasting"
+	"github.com/indeedhat/barista/internal/tasting/controllers"
+	"github.com/indeedhat/barista/internal/types"
+	"github.com/indeedhat/barista/internal/uploads"
+	"github.com/indeedhat/barista/internal/uploads/controllers"
 	_ "github.com/indeedhat/dotenv/autoload"
 )
 
 func main() {
-	firstRun := !database.Exists()
+	if err := storage.Init(); err != nil {
+		log.Fatalf("Failed to init storage: %s", err)
+	}
 
 	db, err := database.Connect()
 	if err != nil {
@@ -33,35 +49,113 @@ func main() {
 		coffee.Coffee{},
 		coffee.Roaster{},
 		coffee.FlavourProfile{},
+		coffee.BlendComponent{},
+		coffee.Cupping{},
+		coffee.Shot{},
+		coffee.MilkType{},
+		types.BrewerType{},
+		types.DrinkType{},
 		coffee.Recipe{},
 		auth.User{},
+		auth.Session{},
+		auth.RecoveryCode{},
+		auth.Invite{},
+		auth.Settings{},
 		brewer.Brewer{},
 		brewer.Basket{},
+		brewer.MaintenanceTask{},
+		brewer.MaintenanceLog{},
+		brewer.Brew{},
+		equipment.Equipment{},
+		photo.Photo{},
+		tasting.Session{},
+		tasting.Sample{},
+		tasting.Participant{},
+		tasting.Score{},
+		roasting.GreenBean{},
+		roasting.RoastBatch{},
+		roasting.RoastProfile{},
 	)
 
 	authRepo := auth.NewSqliteRepo(db)
 	coffeeRepo := coffee.NewSqliteRepo(db)
 	brewerRepo := brewer.NewSqliteRepo(db)
+	photoRepo := photo.NewSqliteRepo(db)
+	tastingRepo := tasting.NewSqliteRepo(db)
+	roastingRepo := roasting.NewSqliteRepo(db)
+	typeRepo := types.NewSqliteRepo(db)
+	equipmentRepo := equipment.NewSqliteRepo(db)
 
-	authController := auth_controllers.New(authRepo)
-	coffeeController := coffee_controllers.New(coffeeRepo)
-	brewerController := brewer_controllers.New(brewerRepo)
+	authController := auth_controllers.New(authRepo, auth.NewOidcProvider())
+	coffeeController := coffee_controllers.New(coffeeRepo, typeRepo, equipmentRepo, brewerRepo)
+	brewerController := brewer_controllers.New(brewerRepo, typeRepo)
+	photoOwners := photo.Owners{
+		photo.OwnerCoffee: uploads.Resolve(coffeeRepo.FindCoffee),
+		photo.OwnerRecipe: uploads.Resolve(coffeeRepo.FindRecipe),
+		photo.OwnerBrewer: uploads.Resolve(brewerRepo.FindBrewer),
+	}
+	photoController := photo_controllers.New(photoRepo, photoOwners)
+	tastingController := tasting_controllers.New(tastingRepo, authRepo, coffeeRepo)
+	roastingController := roasting_controllers.New(roastingRepo, coffeeRepo)
+	equipmentController := equipment_controllers.New(equipmentRepo)
+	uploadResolvers := uploads.Resolvers{
+		coffee_controllers.CoffeeImagePath:  uploads.Resolve(coffeeRepo.FindCoffee),
+		coffee_controllers.RoasterImagePath: uploads.Resolve(coffeeRepo.FindRoaster),
+		brewer_controllers.BrewerImagePath:  uploads.Resolve(brewerRepo.FindBrewer),
+		photo_controllers.PhotoImagePath:    photo.Resolver(photoRepo, photoOwners),
+	}
+	uploadsController := uploads_controllers.New(uploadResolvers)
 
-	if firstRun {
-		if err := authRepo.CreateRootUser(); err != nil {
-			log.Fatalf("Failed to create root user: %s", err)
-		}
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1], os.Args[2:], uploadResolvers)
+		return
 	}
 
-	router := server.NewRouter(server.ServerConfig{
-		MaxBodySize: 1 << 20,
-	})
+	if err := auth.LoadSettings(authRepo); err != nil {
+		log.Fatalf("Failed to load settings: %s", err)
+	}
+
+	if err := coffeeRepo.SeedFlavourProfiles(coffee.FlavourWheel); err != nil {
+		log.Printf("Failed to seed flavour wheel: %s", err)
+	}
+
+	if err := coffeeRepo.SeedMilkTypes(coffee.DefaultMilkTypes); err != nil {
+		log.Printf("Failed to seed milk types: %s", err)
+	}
+
+	if err := typeRepo.SeedBrewerTypes(types.DefaultBrewerTypes); err != nil {
+		log.Printf("Failed to seed brewer types: %s", err)
+	}
+
+	if err := typeRepo.SeedDrinkTypes(types.DefaultDrinkTypes); err != nil {
+		log.Printf("Failed to seed drink types: %s", err)
+	}
+
+	if err := brewerRepo.LinkBrewerTypes(); err != nil {
+		log.Printf("Failed to link brewers to their types: %s", err)
+	}
+
+	if err := coffeeRepo.LinkDrinkTypes(); err != nil {
+		log.Printf("Failed to link recipes to their drink types: %s", err)
+	}
+
+	router := server.NewRouter(
+		server.ServerConfig{
+			MaxBodySize: 1 << 20,
+		},
+		auth.SetupRequiredMiddleware(authRepo),
+	)
 
 	mux := internal.BuildRoutes(
 		router,
 		coffeeController,
 		authController,
 		brewerController,
+		uploadsController,
+		photoController,
+		tastingController,
+		roastingController,
+		equipmentController,
 		authRepo,
 	)
 
@@ -77,6 +171,11 @@ func main() {
 		log.Printf("ListenAndServer: %v", svr.ListenAndServe())
 	}()
 
+	gcCtx, stopGC := context.WithCancel(context.Background())
+	defer stopGC()
+	go uploads.ScheduleGC(gcCtx, uploadResolvers)
+	go auth.ScheduleSessionPrune(gcCtx, authRepo)
+
 	<-quit
 	log.Print("Shutting down server...")
 
@@ -87,3 +186,78 @@ func main() {
 		log.Print("Server forced to shutdown after timeout")
 	}
 }
+
+// runCommand runs one of the cli sub commands rather than starting the server
+func runCommand(cmd string, args []string, resolvers uploads.Resolvers) {
+	switch cmd {
+	case "migrate-storage":
+		migrateStorage(args)
+	case "gc-uploads":
+		gcUploads(args, resolvers)
+	default:
+		log.Fatalf("Unknown command %s, available commands: migrate-storage, gc-uploads", cmd)
+	}
+}
+
+// migrateStorage moves uploaded files between storage backends
+func migrateStorage(args []string) {
+	flags := flag.NewFlagSet("migrate-storage", flag.ExitOnError)
+	from := flags.String("from", storage.BackendFilesystem, "backend to copy files from (fs|s3)")
+	to := flags.String("to", storage.BackendS3, "backend to copy files to (fs|s3)")
+	remove := flags.Bool("delete", false, "delete files from the source backend once copied")
+	flags.Parse(args)
+
+	if *from == *to {
+		log.Fatal("from and to must be different backends")
+	}
+
+	src, err := storage.New(*from)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	dst, err := storage.New(*to)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	count, err := storage.Migrate(context.Background(), src, dst, *remove, func(key string) {
+		fmt.Println(key)
+	})
+	if err != nil {
+		log.Fatalf("Migrated %d files before failing: %s", count, err)
+	}
+
+	log.Printf("Migrated %d files from %s to %s", count, *from, *to)
+}
+
+// gcUploads removes uploaded files that no longer belong to anything
+func gcUploads(args []string, resolvers uploads.Resolvers) {
+	flags := flag.NewFlagSet("gc-uploads", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "report orphaned files without removing them")
+	flags.Parse(args)
+
+	report, err := uploads.GC(context.Background(), storage.Default(), resolvers, *dryRun)
+	if report != nil {
+		for _, obj := range report.Removed {
+			fmt.Printf("%s (%s)\n", obj.Key, uploads.FormatBytes(obj.Size))
+		}
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	verb := "Removed"
+	if *dryRun {
+		verb = "Would remove"
+	}
+
+	log.Printf(
+		"%s %d of %d files, %s reclaimed",
+		verb,
+		len(report.Removed),
+		report.Scanned,
+		uploads.FormatBytes(report.Reclaimed),
+	)
+}
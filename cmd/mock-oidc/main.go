@@ -0,0 +1,37 @@
+// mock-oidc runs a local OpenID Connect identity provider for trying out sso logins without a real
+// one, every login is approved straight away as the identity given on the command line
+//
+// See the Single sign on section of the README for the matching barista config
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/indeedhat/barista/internal/auth/oidctest"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:9999", "address to listen on")
+	clientId := flag.String("client-id", "barista", "client id that barista is configured with")
+	subject := flag.String("sub", "mock-user", "subject claim of the logged in user")
+	name := flag.String("name", "mock", "preferred_username claim of the logged in user")
+	groups := flag.String("groups", "", "comma separated groups claim of the logged in user")
+	flag.Parse()
+
+	provider, err := oidctest.NewProvider("http://"+*addr, *clientId)
+	if err != nil {
+		log.Fatalf("Failed to create provider: %s", err)
+	}
+
+	provider.Subject = *subject
+	provider.Name = *name
+	if *groups != "" {
+		provider.Groups = strings.Split(*groups, ",")
+	}
+
+	log.Printf("Mock OIDC issuer listening on http://%s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, provider))
+}
@@ -0,0 +1,71 @@
+package auth_controllers
+
+import (
+	"net/http"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+func newInstanceSettingsPageData(user *auth.User) ui.PageData {
+	settings := auth.CurrentSettings()
+
+	data := ui.NewPageData("Instance Settings", "instance-settings", user)
+	data.Form = instanceSettingsRequest{
+		RegisterMode:     string(settings.RegisterMode),
+		MembersCanInvite: settings.MembersCanInvite,
+		RequireAdminTotp: settings.AdminTotpRequired(),
+	}
+
+	return data
+}
+
+func (c Controller) ViewInstanceSettings(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	ui.RenderUser(rw, r, newInstanceSettingsPageData(user))
+}
+
+type instanceSettingsRequest struct {
+	RegisterMode     string `json:"register_mode" validate:"oneof=closed invite open"`
+	MembersCanInvite bool   `json:"members_can_invite"`
+	RequireAdminTotp bool   `json:"require_admin_totp"`
+}
+
+func (c Controller) UpdateInstanceSettings(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	pageData := newInstanceSettingsPageData(user)
+	defer func() {
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	var req instanceSettingsRequest
+	if err := server.UnmarshalBody(r, &req, &pageData); err != nil {
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	if err := server.ValidateRequest(req, &pageData); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to save settings")
+		return
+	}
+
+	settings, err := c.repo.FindSettings()
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to save settings")
+		return
+	}
+
+	settings.RegisterMode = auth.RegisterMode(req.RegisterMode)
+	settings.MembersCanInvite = req.MembersCanInvite
+	settings.RequireAdminTotp = &req.RequireAdminTotp
+
+	if err := c.repo.SaveSettings(settings); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to save settings")
+		return
+	}
+
+	auth.SetSettings(*settings)
+
+	ui.Toast(rw, ui.Success, "Settings saved")
+}
@@ -0,0 +1,22 @@
+package auth_controllers
+
+import (
+	"net/http"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+type viewUsersData struct {
+	ui.PageData
+	Users []auth.User
+}
+
+func (c Controller) ViewUsers(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	ui.RenderUser(rw, r, viewUsersData{
+		PageData: ui.NewPageData("Users", "users", user),
+		Users:    c.repo.IndexUsers(),
+	})
+}
@@ -6,8 +6,9 @@ import (
 
 type Controller struct {
 	repo auth.Repository
+	oidc *auth.OidcProvider
 }
 
-func New(repo auth.Repository) Controller {
-	return Controller{repo}
+func New(repo auth.Repository, oidc *auth.OidcProvider) Controller {
+	return Controller{repo, oidc}
 }
@@ -0,0 +1,146 @@
+package auth_controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+type invitesPageData struct {
+	ui.PageData
+	Invites []auth.Invite
+	Open    bool
+}
+
+func (c Controller) newInvitesPageData(user *auth.User) invitesPageData {
+	data := invitesPageData{PageData: ui.NewPageData("Invites", "invites", user)}
+	data.Form = createInviteRequest{}
+	data.Invites = c.indexInvites(user)
+
+	return data
+}
+
+func (c Controller) ViewInvites(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	if !user.CanInvite() {
+		ui.Redirect(rw, "/")
+		return
+	}
+
+	ui.RenderUser(rw, r, c.newInvitesPageData(user))
+}
+
+type createInviteRequest struct {
+	Level     uint8 `json:"level"`
+	MaxUses   uint  `json:"max_uses"`
+	ExpiresIn uint  `json:"expires_in"` // days
+}
+
+func (c Controller) CreateInvite(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	if !user.CanInvite() {
+		ui.Redirect(rw, "/")
+		return
+	}
+
+	pageData := c.newInvitesPageData(user)
+	pageData.Open = true
+	defer func() {
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	var req createInviteRequest
+	if err := server.UnmarshalBody(r, &req, &pageData); err != nil {
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	level := auth.Level(req.Level)
+	if !user.IsAdmin() || level == 0 {
+		level = auth.LevelMember
+	}
+
+	if level != auth.LevelAdmin && level != auth.LevelMember {
+		pageData.FieldErrors["level"] = []string{"Invalid level"}
+		ui.Toast(rw, ui.Warning, "Failed to create invite")
+		return
+	}
+
+	invite, err := auth.NewInvite(user, level, req.MaxUses, time.Duration(req.ExpiresIn)*24*time.Hour)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to create invite")
+		return
+	}
+
+	if err := c.repo.SaveInvite(invite); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to create invite")
+		return
+	}
+
+	pageData.Invites = c.indexInvites(user)
+	pageData.Open = false
+	pageData.Form = createInviteRequest{}
+
+	ui.Toast(rw, ui.Success, "Invite created")
+}
+
+func (c Controller) DeleteInvite(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	if !user.CanInvite() {
+		ui.Redirect(rw, "/")
+		return
+	}
+
+	pageData := c.newInvitesPageData(user)
+	defer func() {
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	id, err := server.PathID(r)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Invite not found")
+		return
+	}
+
+	invite, err := c.repo.FindInvite(id)
+	if err != nil || (!user.IsAdmin() && invite.CreatedByID != user.ID) {
+		ui.Toast(rw, ui.Warning, "Invite not found")
+		return
+	}
+
+	if err := c.repo.DeleteInvite(invite); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to delete invite")
+		return
+	}
+
+	pageData.Invites = c.indexInvites(user)
+
+	ui.Toast(rw, ui.Success, "Invite deleted")
+}
+
+// indexInvites lists every invite for admins but only their own for other users
+func (c Controller) indexInvites(user *auth.User) []auth.Invite {
+	if user.IsAdmin() {
+		return c.repo.IndexInvites()
+	}
+
+	return c.repo.IndexInvitesForUser(user)
+}
+
+// findValidInvite looks up an invite by its code, nil is returned if the invite does not exist or
+// can no longer be used
+func (c Controller) findValidInvite(code string) *auth.Invite {
+	if code == "" {
+		return nil
+	}
+
+	invite, err := c.repo.FindInviteByCode(code)
+	if err != nil || !invite.Valid() {
+		return nil
+	}
+
+	return invite
+}
@@ -1,6 +1,7 @@
 package auth_controllers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/indeedhat/barista/internal/auth"
@@ -12,6 +13,21 @@ import (
 type loginPageData struct {
 	ui.PageData
 	Register bool
+	Oidc     string
+	Error    string
+}
+
+func (c Controller) newLoginPageData(page string) loginPageData {
+	data := loginPageData{
+		PageData: ui.NewPageData("Login", page),
+		Register: auth.CurrentSettings().RegisterMode == auth.RegisterOpen,
+	}
+
+	if c.oidc.Enabled() {
+		data.Oidc = c.oidc.Name()
+	}
+
+	return data
 }
 
 func (c Controller) ViewLogin(rw http.ResponseWriter, r *http.Request) {
@@ -19,10 +35,7 @@ func (c Controller) ViewLogin(rw http.ResponseWriter, r *http.Request) {
 		ui.Toast(rw, ui.Success, "User Created, you may now login")
 	}
 
-	ui.RenderGuest(rw, r, loginPageData{
-		PageData: ui.NewPageData("Login", "login"),
-		Register: auth.EnvEnableRegister.Get(),
-	})
+	ui.RenderGuest(rw, r, c.newLoginPageData("login"))
 }
 
 type loginRequest struct {
@@ -32,10 +45,7 @@ type loginRequest struct {
 
 // Login handles user login attempts
 func (c Controller) Login(rw http.ResponseWriter, r *http.Request) {
-	pageData := loginPageData{
-		PageData: ui.NewPageData("Login", "login"),
-		Register: auth.EnvEnableRegister.Get(),
-	}
+	pageData := c.newLoginPageData("login")
 	defer func() {
 		ui.RenderGuest(rw, r, pageData)
 	}()
@@ -54,19 +64,133 @@ func (c Controller) Login(rw http.ResponseWriter, r *http.Request) {
 	}
 
 	user, err := c.repo.FindUserByLogin(req.Name, req.Password)
-	if user == nil {
+	if err != nil {
 		pageData.Form = req
 		ui.Toast(rw, ui.Warning, "Login failed")
 		return
 	}
 
-	jwt, err := auth.GenerateUserJwt(user.ID, user.Name, uint8(user.Level), user.JwtKillSwitch)
+	if user.TotpEnabled {
+		if err := c.startTotpLogin(rw, r, user); errors.Is(err, auth.ErrTotpLocked) {
+			pageData.Form = req
+			ui.Toast(rw, ui.Warning, err.Error())
+			return
+		} else if err != nil {
+			ui.Toast(rw, ui.Warning, "Failed to process login")
+			return
+		}
+
+		pageData.Page = "pages/login-totp"
+		pageData.Form = loginTotpRequest{}
+		return
+	}
+
+	if err := c.startSession(rw, r, user); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to process login")
+		return
+	}
+
+	ui.Redirect(rw, "/")
+}
+
+type loginTotpRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// LoginTotp handles the second step of the login process for users with 2fa enabled
+//
+// Either a totp code or one of the users recovery codes will be accepted, after too many wrong codes
+// 2fa logins are locked for a while and the user has to start again from the password step
+func (c Controller) LoginTotp(rw http.ResponseWriter, r *http.Request) {
+	pageData := c.newLoginPageData("login-totp")
+	pageData.Form = loginTotpRequest{}
+
+	user, err := auth.VerifyTwoFactorJwt(r, c.repo)
 	if err != nil {
+		cookie.Delete(rw, r, cookie.TwoFactorKey)
+		ui.Toast(rw, ui.Warning, "Login expired, please try again")
+		ui.Redirect(rw, "/login")
+		return
+	}
+
+	var req loginTotpRequest
+	if err := server.UnmarshalBody(r, &req); err != nil {
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		ui.RenderGuest(rw, r, pageData)
+		return
+	}
+
+	if err := server.ValidateRequest(req, &pageData); err != nil {
+		ui.Toast(rw, ui.Warning, "Login failed")
+		ui.RenderGuest(rw, r, pageData)
+		return
+	}
+
+	if !c.verifyTotpOrRecoveryCode(user, req.Code) {
+		if err := c.repo.RecordTotpFailure(user); err != nil || user.TotpLocked() {
+			cookie.Delete(rw, r, cookie.TwoFactorKey)
+			ui.Toast(rw, ui.Warning, auth.ErrTotpLocked.Error())
+			ui.Redirect(rw, "/login")
+			return
+		}
+
+		pageData.FieldErrors["code"] = []string{"Invalid code"}
+		ui.Toast(rw, ui.Warning, "Login failed")
+		ui.RenderGuest(rw, r, pageData)
+		return
+	}
+
+	if err := c.repo.ResetTotpFailures(user); err != nil {
 		ui.Toast(rw, ui.Warning, "Failed to process login")
+		ui.RenderGuest(rw, r, pageData)
 		return
 	}
 
-	cookie.Set(rw, r, cookie.SessionKey, jwt)
+	if err := c.startSession(rw, r, user); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to process login")
+		ui.RenderGuest(rw, r, pageData)
+		return
+	}
 
+	cookie.Delete(rw, r, cookie.TwoFactorKey)
 	ui.Redirect(rw, "/")
 }
+
+// startTotpLogin sets the cookie that identifies the user during the 2fa step of the login
+//
+// auth.ErrTotpLocked is returned if the user is locked out of 2fa logins
+func (c Controller) startTotpLogin(rw http.ResponseWriter, r *http.Request, user *auth.User) error {
+	if user.TotpLocked() {
+		return auth.ErrTotpLocked
+	}
+
+	token, err := auth.GenerateTwoFactorJwt(user.ID, user.JwtKillSwitch)
+	if err != nil {
+		return err
+	}
+
+	cookie.Set(rw, r, cookie.TwoFactorKey, token)
+
+	return nil
+}
+
+// startSession creates a new session for the user and sets the session cookie
+func (c Controller) startSession(rw http.ResponseWriter, r *http.Request, user *auth.User) error {
+	session, err := auth.NewSession(r, user)
+	if err != nil {
+		return err
+	}
+
+	if err := c.repo.SaveSession(session); err != nil {
+		return err
+	}
+
+	jwt, err := auth.GenerateUserJwt(user.ID, user.Name, uint8(user.Level), user.JwtKillSwitch, session.JwtID)
+	if err != nil {
+		return err
+	}
+
+	cookie.Set(rw, r, cookie.SessionKey, jwt)
+
+	return nil
+}
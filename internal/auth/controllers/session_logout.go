@@ -3,11 +3,16 @@ package auth_controllers
 import (
 	"net/http"
 
+	"github.com/indeedhat/barista/internal/auth"
 	"github.com/indeedhat/barista/internal/cookie"
 	"github.com/indeedhat/barista/internal/ui"
 )
 
 func (c Controller) Logout(rw http.ResponseWriter, r *http.Request) {
+	if session, ok := r.Context().Value("session").(*auth.Session); ok && session != nil {
+		_ = c.repo.DeleteSession(session)
+	}
+
 	cookie.Delete(rw, r, cookie.SessionKey)
 	ui.Redirect(rw, "/login")
 }
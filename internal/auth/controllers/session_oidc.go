@@ -0,0 +1,191 @@
+package auth_controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+// OidcLogin redirects the user to the identity provider to start an sso login
+func (c Controller) OidcLogin(rw http.ResponseWriter, r *http.Request) {
+	url, err := c.oidc.AuthCodeURL(rw, r, 0)
+	if err != nil {
+		c.renderLoginError(rw, r, "SSO login is unavailable")
+		return
+	}
+
+	http.Redirect(rw, r, url, http.StatusSeeOther)
+}
+
+// OidcCallback completes an sso login or account link after the user has authenticated with the
+// identity provider
+//
+// This is not behind any of the auth middleware as it handles both guests logging in and logged in
+// users linking their account
+func (c Controller) OidcCallback(rw http.ResponseWriter, r *http.Request) {
+	identity, state, err := c.oidc.Exchange(rw, r)
+	if err != nil {
+		c.renderLoginError(rw, r, err.Error())
+		return
+	}
+
+	level, allowed := identity.Level()
+	if !allowed {
+		c.renderLoginError(rw, r, auth.ErrOidcForbidden.Error())
+		return
+	}
+
+	if state.LinkUserId != 0 {
+		c.linkOidcIdentity(rw, r, state.LinkUserId, identity)
+		return
+	}
+
+	user, err := c.findOrProvisionOidcUser(identity, level)
+	if err != nil {
+		c.renderLoginError(rw, r, err.Error())
+		return
+	}
+
+	if level != 0 && user.Level != level {
+		user.Level = level
+		if err := c.repo.SaveUser(user); err != nil {
+			c.renderLoginError(rw, r, "Failed to process login")
+			return
+		}
+	}
+
+	if user.TotpEnabled {
+		if err := c.startTotpLogin(rw, r, user); errors.Is(err, auth.ErrTotpLocked) {
+			c.renderLoginError(rw, r, err.Error())
+			return
+		} else if err != nil {
+			c.renderLoginError(rw, r, "Failed to process login")
+			return
+		}
+
+		pageData := c.newLoginPageData("login-totp")
+		pageData.Form = loginTotpRequest{}
+		ui.RenderGuest(rw, r, pageData)
+		return
+	}
+
+	if err := c.startSession(rw, r, user); err != nil {
+		c.renderLoginError(rw, r, "Failed to process login")
+		return
+	}
+
+	server.Redirect(rw, r, "/")
+}
+
+// OidcLink starts the sso flow for a logged in user to link their account
+func (c Controller) OidcLink(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	url, err := c.oidc.AuthCodeURL(rw, r, user.ID)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "SSO login is unavailable")
+		ui.RenderUser(rw, r, c.newSettingsPageData(r))
+		return
+	}
+
+	ui.Redirect(rw, url)
+}
+
+// OidcUnlink removes the link between the logged in user and their sso account
+func (c Controller) OidcUnlink(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	pageData := c.newSettingsPageData(r)
+	defer func() {
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	user.OidcSubject = nil
+	if err := c.repo.SaveUser(user); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to unlink SSO account")
+		return
+	}
+
+	ui.Toast(rw, ui.Success, "SSO account unlinked")
+}
+
+func (c Controller) linkOidcIdentity(
+	rw http.ResponseWriter,
+	r *http.Request,
+	userId uint,
+	identity *auth.OidcIdentity,
+) {
+	user := auth.UserFromRequest(r, c.repo)
+	if user == nil || user.ID != userId {
+		server.Redirect(rw, r, "/login")
+		return
+	}
+
+	if existing, err := c.repo.FindUserByOidcSubject(identity.Subject); err == nil {
+		if existing.ID != user.ID {
+			server.Redirect(rw, r, "/user/settings?sso=in-use")
+			return
+		}
+	}
+
+	user.OidcSubject = &identity.Subject
+	if err := c.repo.SaveUser(user); err != nil {
+		server.Redirect(rw, r, "/user/settings?sso=failed")
+		return
+	}
+
+	server.Redirect(rw, r, "/user/settings?sso=linked")
+}
+
+// findOrProvisionOidcUser finds the user linked to the sso identity
+//
+// Depending on config a user may be linked by their verified email or created if no linked user
+// exists. The username claims are never used for linking as they can be changed by the user at the
+// identity provider
+func (c Controller) findOrProvisionOidcUser(identity *auth.OidcIdentity, level auth.Level) (*auth.User, error) {
+	if user, err := c.repo.FindUserByOidcSubject(identity.Subject); err == nil {
+		return user, nil
+	}
+
+	if auth.EnvOidcLinkByName.Get() && identity.VerifiedEmail != "" {
+		if user, err := c.repo.FindUserByName(identity.VerifiedEmail); err == nil && user.OidcSubject == nil {
+			user.OidcSubject = &identity.Subject
+			if err := c.repo.SaveUser(user); err != nil {
+				return nil, errors.New("Failed to link SSO account")
+			}
+
+			return user, nil
+		}
+	}
+
+	if identity.Name == "" {
+		return nil, errors.New("No account is linked to this SSO login")
+	}
+
+	if _, err := c.repo.FindUserByName(identity.Name); err == nil {
+		return nil, errors.New("An account with this name already exists, log in and link it from your settings")
+	}
+
+	if !auth.EnvOidcAutoProvision.Get() {
+		return nil, errors.New("No account is linked to this SSO login")
+	}
+
+	user := auth.NewOidcUser(identity, level)
+	if err := c.repo.SaveUser(&user); err != nil {
+		return nil, errors.New("Failed to create account")
+	}
+
+	return &user, nil
+}
+
+// renderLoginError renders the full login page with an error message
+//
+// The sso flow is made up of full page navigations so toasts cannot be used
+func (c Controller) renderLoginError(rw http.ResponseWriter, r *http.Request, message string) {
+	pageData := c.newLoginPageData("login")
+	pageData.Error = message
+
+	ui.RenderGuest(rw, r, pageData)
+}
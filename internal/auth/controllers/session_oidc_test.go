@@ -0,0 +1,127 @@
+package auth_controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/auth/oidctest"
+	"github.com/indeedhat/barista/internal/cookie"
+	"gorm.io/gorm"
+)
+
+// newTestController creates a controller backed by a fresh database and sso configured against a
+// mock identity provider
+func newTestController(t *testing.T) (Controller, *oidctest.Provider) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(auth.User{}, auth.Invite{}, auth.Session{}, auth.RecoveryCode{}); err != nil {
+		t.Fatal(err)
+	}
+
+	mock, err := oidctest.NewProvider("", "barista")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(mock)
+	t.Cleanup(server.Close)
+	mock.Issuer = server.URL
+
+	t.Setenv("JWT_SECRET", "oidc-test-secret")
+	t.Setenv("OIDC_ISSUER", server.URL)
+	t.Setenv("OIDC_CLIENT_ID", "barista")
+	t.Setenv("OIDC_REDIRECT_URL", "http://barista.test/login/oidc/callback")
+	t.Setenv(string(auth.EnvOidcLinkByName), "true")
+	t.Setenv(string(auth.EnvOidcAutoProvision), "false")
+
+	return New(auth.NewSqliteRepo(db), auth.NewOidcProvider()), mock
+}
+
+// login runs the sso flow against the mock provider and returns the identity it logged in as
+func login(t *testing.T, c Controller) *auth.OidcIdentity {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	authUrl, err := c.oidc.AuthCodeURL(rec, httptest.NewRequest(http.MethodGet, "/login/oidc", nil), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	res, err := client.Get(authUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	callback, err := res.Location()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, callback.String(), nil)
+	for _, ck := range rec.Result().Cookies() {
+		if ck.Name == cookie.OidcKey {
+			req.AddCookie(ck)
+		}
+	}
+
+	identity, _, err := c.oidc.Exchange(httptest.NewRecorder(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return identity
+}
+
+func TestOidcLinkByVerifiedEmail(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		username string
+		email    string
+		verified bool
+		linked   bool
+	}{
+		{"verified email", "someone", "admin@example.com", true, true},
+		{"case insensitive", "someone", "Admin@Example.com", true, true},
+		{"unverified email", "someone", "admin@example.com", false, false},
+		{"username only", "admin@example.com", "", false, false},
+		{"wildcard username", "%", "", false, false},
+		{"wildcard email", "someone", "%", true, false},
+		{"single char wildcard", "someone", "admin_example.com", true, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c, mock := newTestController(t)
+
+			admin := auth.User{Name: "admin@example.com", Password: "password", Level: auth.LevelAdmin}
+			if err := c.repo.SaveUser(&admin); err != nil {
+				t.Fatal(err)
+			}
+
+			mock.Subject = "attacker"
+			mock.Name = tc.username
+			mock.Email = tc.email
+			mock.EmailVerified = tc.verified
+
+			user, err := c.findOrProvisionOidcUser(login(t, c), 0)
+			if tc.linked != (err == nil && user.ID == admin.ID) {
+				t.Errorf("linked: got %v %v", user, err)
+			}
+
+			stored, _ := c.repo.FindUser(admin.ID)
+			if linked := stored.OidcSubject != nil; linked != tc.linked {
+				t.Errorf("stored subject: got %v", stored.OidcSubject)
+			}
+		})
+	}
+}
@@ -10,52 +10,67 @@ import (
 )
 
 func (c Controller) ViewRegister(rw http.ResponseWriter, r *http.Request) {
-	if !auth.EnvEnableRegister.Get() {
+	code := r.URL.Query().Get("invite")
+	invite := c.findValidInvite(code)
+
+	if !registerAllowed(invite) {
 		ui.Redirect(rw, "/")
 		return
 	}
 
-	ui.RenderGuest(rw, r, ui.PageData{
-		Title: "Register",
-		Page:  "pages/register",
-		Form:  registerRequest{},
-	})
+	pageData := ui.NewPageData("Register", "register")
+	pageData.Form = registerRequest{Invite: code}
+
+	if code != "" && invite == nil {
+		ui.Toast(rw, ui.Warning, "Invite is invalid or has expired")
+		pageData.Form = registerRequest{}
+	}
+
+	ui.RenderGuest(rw, r, pageData)
 }
 
 type registerRequest struct {
 	Name            string `json:"name" validate:"required"`
 	Password        string `json:"password" validate:"required"`
 	PasswordConfirm string `json:"password_conf" validate:"required"`
+	Invite          string `json:"invite"`
 }
 
 // Register handles user register attempts
 func (c Controller) Register(rw http.ResponseWriter, r *http.Request) {
-	if !auth.EnvEnableRegister.Get() {
+	var req registerRequest
+	if err := server.UnmarshalBody(r, &req); err != nil {
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	invite := c.findValidInvite(req.Invite)
+	if !registerAllowed(invite) {
 		ui.Redirect(rw, "/")
 		return
 	}
 
 	pageData := ui.NewPageData("Register", "register")
-	pageData.Form = registerRequest{}
+	pageData.Form = registerRequest{Invite: req.Invite}
 	defer func() {
 		ui.RenderGuest(rw, r, pageData)
 	}()
 
-	var req registerRequest
-	if err := server.UnmarshalBody(r, &req); err != nil {
-		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+	if req.Invite != "" && invite == nil {
+		pageData.Form = registerRequest{Name: req.Name}
+		ui.Toast(rw, ui.Warning, "Invite is invalid or has expired")
 		return
 	}
 
 	if err := server.ValidateRequest(req); err != nil {
 		pageData.FieldErrors = server.ExtractFIeldErrors(err).Fields
-		pageData.Form = registerRequest{Name: req.Name}
+		pageData.Form = registerRequest{Name: req.Name, Invite: req.Invite}
 		ui.Toast(rw, ui.Warning, "Register failed")
 		return
 	}
 
-	if user, _ := c.repo.FindUserByLogin(req.Name, req.Password); user != nil {
-		pageData.Form = registerRequest{Name: req.Name}
+	if user, _ := c.repo.FindUserByName(req.Name); user != nil {
+		pageData.Form = registerRequest{Name: req.Name, Invite: req.Invite}
 		ui.Toast(rw, ui.Warning, "Name in use")
 		return
 	}
@@ -73,10 +88,32 @@ func (c Controller) Register(rw http.ResponseWriter, r *http.Request) {
 		JwtKillSwitch: time.Now().Unix(),
 	}
 
-	if err := c.repo.SaveUser(&user); err != nil {
-		server.WriteResponse(rw, http.StatusInternalServerError, nil)
+	var err error
+	if invite != nil {
+		err = c.repo.RegisterWithInvite(&user, invite)
+	} else {
+		err = c.repo.SaveUser(&user)
+	}
+
+	if err != nil {
+		pageData.Form = registerRequest{Name: req.Name, Invite: req.Invite}
+		ui.Toast(rw, ui.Warning, "Register failed")
 		return
 	}
 
 	ui.Redirect(rw, "/login?register=true")
 }
+
+// registerAllowed checks if the instance register mode allows the user to register
+//
+// A valid invite code allows registration even when open registration is disabled
+func registerAllowed(invite *auth.Invite) bool {
+	switch auth.CurrentSettings().RegisterMode {
+	case auth.RegisterOpen:
+		return true
+	case auth.RegisterInvite:
+		return invite != nil
+	default:
+		return false
+	}
+}
@@ -8,9 +8,52 @@ import (
 	"github.com/indeedhat/barista/internal/ui"
 )
 
-func (c Controller) ViewSettings(rw http.ResponseWriter, r *http.Request) {
+type settingsPageData struct {
+	ui.PageData
+	Sessions []auth.Session
+	Session  *auth.Session
+
+	TotpSetup         *totpSetupData
+	TotpRequired      bool
+	RecoveryCodes     []string
+	RecoveryCodesLeft int64
+
+	Oidc   string
+	Notice string
+}
+
+func (c Controller) newSettingsPageData(r *http.Request) settingsPageData {
 	user := r.Context().Value("user").(*auth.User)
-	ui.RenderUser(rw, r, ui.NewPageData("User Settings", "user-settings", user))
+	session, _ := r.Context().Value("session").(*auth.Session)
+
+	data := settingsPageData{
+		PageData:          ui.NewPageData("User Settings", "user-settings", user),
+		Sessions:          c.repo.IndexSessionsForUser(user),
+		Session:           session,
+		TotpRequired:      user.RequiresTotpEnrolment(),
+		RecoveryCodesLeft: c.repo.CountRecoveryCodes(user),
+	}
+
+	if c.oidc.Enabled() {
+		data.Oidc = c.oidc.Name()
+	}
+
+	return data
+}
+
+func (c Controller) ViewSettings(rw http.ResponseWriter, r *http.Request) {
+	pageData := c.newSettingsPageData(r)
+
+	switch r.URL.Query().Get("sso") {
+	case "linked":
+		pageData.Notice = "SSO account linked"
+	case "in-use":
+		pageData.Notice = "That SSO account is already linked to another user"
+	case "failed":
+		pageData.Notice = "Failed to link SSO account"
+	}
+
+	ui.RenderUser(rw, r, pageData)
 }
 
 type changePasswordRequest struct {
@@ -21,7 +64,7 @@ type changePasswordRequest struct {
 
 func (c Controller) ChangePassword(rw http.ResponseWriter, r *http.Request) {
 	user := r.Context().Value("user").(*auth.User)
-	pageData := ui.NewPageData("User Settings", "user-settings", user)
+	pageData := c.newSettingsPageData(r)
 	defer func() {
 		ui.RenderUser(rw, r, pageData)
 	}()
@@ -0,0 +1,77 @@
+package auth_controllers
+
+import (
+	"net/http"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/cookie"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+// RevokeSession logs out a single device belonging to the logged in user
+func (c Controller) RevokeSession(rw http.ResponseWriter, r *http.Request) {
+	pageData := c.newSettingsPageData(r)
+
+	id, err := server.PathID(r)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Session not found")
+		ui.RenderUser(rw, r, pageData)
+		return
+	}
+
+	var session *auth.Session
+	for _, s := range pageData.Sessions {
+		if s.ID == id {
+			session = &s
+			break
+		}
+	}
+
+	if session == nil {
+		ui.Toast(rw, ui.Warning, "Session not found")
+		ui.RenderUser(rw, r, pageData)
+		return
+	}
+
+	if err := c.repo.DeleteSession(session); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to log out device")
+		ui.RenderUser(rw, r, pageData)
+		return
+	}
+
+	if pageData.Session != nil && pageData.Session.ID == session.ID {
+		cookie.Delete(rw, r, cookie.SessionKey)
+		ui.Redirect(rw, "/login")
+		return
+	}
+
+	pageData.Sessions = c.repo.IndexSessionsForUser(pageData.User.(*auth.User))
+
+	ui.Toast(rw, ui.Success, "Device logged out")
+	ui.RenderUser(rw, r, pageData)
+}
+
+// RevokeOtherSessions logs out every device belonging to the logged in user other than the one
+// making the request
+func (c Controller) RevokeOtherSessions(rw http.ResponseWriter, r *http.Request) {
+	pageData := c.newSettingsPageData(r)
+	defer func() {
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	if pageData.Session == nil {
+		ui.Toast(rw, ui.Warning, "Session not found")
+		return
+	}
+
+	user := pageData.User.(*auth.User)
+	if err := c.repo.DeleteOtherSessions(user, pageData.Session); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to log out other devices")
+		return
+	}
+
+	pageData.Sessions = c.repo.IndexSessionsForUser(user)
+
+	ui.Toast(rw, ui.Success, "Logged out everywhere else")
+}
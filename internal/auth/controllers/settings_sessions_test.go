@@ -0,0 +1,70 @@
+package auth_controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/indeedhat/barista/internal/auth"
+)
+
+func TestRevokeOtherSessions(t *testing.T) {
+	c, _ := newTestController(t)
+
+	alice := auth.User{Name: "alice", Level: auth.LevelMember}
+	bob := auth.User{Name: "bob", Level: auth.LevelMember}
+	for _, user := range []*auth.User{&alice, &bob} {
+		if err := c.repo.SaveUser(user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sessions := []*auth.Session{
+		{JwtID: "alice-current", UserID: alice.ID},
+		{JwtID: "alice-phone", UserID: alice.ID},
+		{JwtID: "alice-laptop", UserID: alice.ID},
+		{JwtID: "bob-current", UserID: bob.ID},
+	}
+	for _, session := range sessions {
+		if err := c.repo.SaveSession(session); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.WithValue(context.Background(), "user", &alice)
+	ctx = context.WithValue(ctx, "session", sessions[0])
+	req := httptest.NewRequestWithContext(ctx, http.MethodDelete, "/user/sessions", nil)
+	req.Header.Set("HX-Request", "true")
+
+	c.RevokeOtherSessions(httptest.NewRecorder(), req)
+
+	for _, tc := range []struct {
+		user *auth.User
+		left []string
+	}{
+		{&alice, []string{"alice-current"}},
+		{&bob, []string{"bob-current"}},
+	} {
+		var left []string
+		for _, session := range c.repo.IndexSessionsForUser(tc.user) {
+			left = append(left, session.JwtID)
+		}
+
+		if !slices.Equal(left, tc.left) {
+			t.Errorf("%s: got %v, want %v", tc.user.Name, left, tc.left)
+		}
+	}
+
+	// a request without a session does not log anything out
+	ctx = context.WithValue(context.Background(), "user", &bob)
+	req = httptest.NewRequestWithContext(ctx, http.MethodDelete, "/user/sessions", nil)
+	req.Header.Set("HX-Request", "true")
+
+	c.RevokeOtherSessions(httptest.NewRecorder(), req)
+
+	if left := c.repo.IndexSessionsForUser(&bob); len(left) != 1 {
+		t.Errorf("bob without a session: got %d sessions", len(left))
+	}
+}
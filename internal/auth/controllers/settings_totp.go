@@ -0,0 +1,234 @@
+package auth_controllers
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+type totpSetupData struct {
+	Secret string
+	QRCode template.URL
+}
+
+type totpCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// SetupTotp starts 2fa enrolment by generating a new secret for the user to add to their
+// authenticator app
+func (c Controller) SetupTotp(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	pageData := c.newSettingsPageData(r)
+	defer func() {
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	if user.TotpEnabled {
+		ui.Toast(rw, ui.Warning, "2FA is already enabled")
+		return
+	}
+
+	secret, err := auth.NewTotpSecret()
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to start 2FA setup")
+		return
+	}
+
+	user.TotpSecret = secret
+	user.TotpLastStep = 0
+	if err := c.repo.SaveUser(user); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to start 2FA setup")
+		return
+	}
+
+	if pageData.TotpSetup, err = newTotpSetupData(user); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to start 2FA setup")
+		return
+	}
+}
+
+// EnableTotp completes 2fa enrolment once the user has proven their authenticator app is
+// generating valid codes
+func (c Controller) EnableTotp(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	pageData := c.newSettingsPageData(r)
+	defer func() {
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	if user.TotpEnabled || user.TotpSecret == "" {
+		ui.Toast(rw, ui.Warning, "2FA setup has not been started")
+		return
+	}
+
+	pageData.TotpSetup, _ = newTotpSetupData(user)
+
+	var req totpCodeRequest
+	if err := server.UnmarshalBody(r, &req); err != nil {
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	if err := server.ValidateRequest(req, &pageData); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to enable 2FA")
+		return
+	}
+
+	step, ok := auth.VerifyTotp(user, user.TotpSecret, req.Code)
+	if !ok {
+		pageData.FieldErrors["code"] = []string{"Invalid code"}
+		ui.Toast(rw, ui.Warning, "Failed to enable 2FA")
+		return
+	}
+
+	user.TotpEnabled = true
+	user.TotpLastStep = step
+	if err := c.repo.SaveUser(user); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to enable 2FA")
+		return
+	}
+
+	if !c.issueRecoveryCodes(rw, user, &pageData) {
+		return
+	}
+
+	pageData.TotpSetup = nil
+	pageData.TotpRequired = false
+	ui.Toast(rw, ui.Success, "2FA enabled")
+}
+
+// DisableTotp turns off 2fa for the user, a valid code is required to do so
+func (c Controller) DisableTotp(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	pageData := c.newSettingsPageData(r)
+	defer func() {
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	if !user.TotpEnabled {
+		ui.Toast(rw, ui.Warning, "2FA is not enabled")
+		return
+	}
+
+	var req totpCodeRequest
+	if err := server.UnmarshalBody(r, &req); err != nil {
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	if err := server.ValidateRequest(req, &pageData); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to disable 2FA")
+		return
+	}
+
+	if user.TotpRequired() {
+		ui.Toast(rw, ui.Warning, "2FA is required for admin users")
+		return
+	}
+
+	if !c.verifyTotpOrRecoveryCode(user, req.Code) {
+		pageData.FieldErrors["code"] = []string{"Invalid code"}
+		ui.Toast(rw, ui.Warning, "Failed to disable 2FA")
+		return
+	}
+
+	user.TotpEnabled = false
+	user.TotpSecret = ""
+	user.TotpLastStep = 0
+	if err := c.repo.SaveUser(user); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to disable 2FA")
+		return
+	}
+
+	_ = c.repo.ReplaceRecoveryCodes(user, nil)
+
+	pageData.RecoveryCodesLeft = 0
+	ui.Toast(rw, ui.Success, "2FA disabled")
+}
+
+// RegenerateRecoveryCodes replaces all of the users recovery codes with a fresh set
+func (c Controller) RegenerateRecoveryCodes(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	pageData := c.newSettingsPageData(r)
+	defer func() {
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	if !user.TotpEnabled {
+		ui.Toast(rw, ui.Warning, "2FA is not enabled")
+		return
+	}
+
+	var req totpCodeRequest
+	if err := server.UnmarshalBody(r, &req); err != nil {
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	if err := server.ValidateRequest(req, &pageData); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to generate recovery codes")
+		return
+	}
+
+	if !c.verifyTotpOrRecoveryCode(user, req.Code) {
+		pageData.FieldErrors["code"] = []string{"Invalid code"}
+		ui.Toast(rw, ui.Warning, "Failed to generate recovery codes")
+		return
+	}
+
+	if !c.issueRecoveryCodes(rw, user, &pageData) {
+		return
+	}
+
+	ui.Toast(rw, ui.Success, "Recovery codes generated")
+}
+
+// verifyTotpOrRecoveryCode checks the code against both the users authenticator and their recovery
+// codes, any code that is accepted is used up
+func (c Controller) verifyTotpOrRecoveryCode(user *auth.User, code string) bool {
+	if step, ok := auth.VerifyTotp(user, user.TotpSecret, code); ok {
+		return c.repo.UseTotpStep(user, step) == nil
+	}
+
+	return c.repo.UseRecoveryCode(user, code) == nil
+}
+
+// issueRecoveryCodes generates and stores a new set of recovery codes for the user, the plain text
+// codes are added to the page data so they can be shown to the user
+func (c Controller) issueRecoveryCodes(
+	rw http.ResponseWriter,
+	user *auth.User,
+	pageData *settingsPageData,
+) bool {
+	codes, models, err := auth.NewRecoveryCodes(user)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to generate recovery codes")
+		return false
+	}
+
+	if err := c.repo.ReplaceRecoveryCodes(user, models); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to generate recovery codes")
+		return false
+	}
+
+	pageData.RecoveryCodes = codes
+	pageData.RecoveryCodesLeft = int64(len(codes))
+
+	return true
+}
+
+func newTotpSetupData(user *auth.User) (*totpSetupData, error) {
+	qr, err := auth.TotpQRCode(auth.TotpURI(user.Name, user.TotpSecret))
+	if err != nil {
+		return nil, err
+	}
+
+	return &totpSetupData{
+		Secret: user.TotpSecret,
+		QRCode: template.URL(qr),
+	}, nil
+}
@@ -0,0 +1,117 @@
+package auth_controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+type setupPageData struct {
+	ui.PageData
+	JwtSecret bool
+}
+
+func newSetupPageData() setupPageData {
+	data := setupPageData{
+		PageData:  ui.NewPageData("Setup", "setup"),
+		JwtSecret: auth.NeedsJwtSecret(),
+	}
+	data.Form = setupRequest{RegisterMode: string(auth.RegisterInvite)}
+
+	return data
+}
+
+// ViewSetup shows the first run setup wizard, it is only available until the first user is created
+func (c Controller) ViewSetup(rw http.ResponseWriter, r *http.Request) {
+	if c.repo.CountUsers() != 0 {
+		server.Redirect(rw, r, "/")
+		return
+	}
+
+	ui.RenderGuest(rw, r, newSetupPageData())
+}
+
+type setupRequest struct {
+	Name             string `json:"name" validate:"required"`
+	Password         string `json:"password" validate:"required,min=8"`
+	PasswordConfirm  string `json:"password_conf" validate:"required"`
+	RegisterMode     string `json:"register_mode" validate:"oneof=closed invite open"`
+	MembersCanInvite bool   `json:"members_can_invite"`
+}
+
+// Setup creates the first admin user and the instance settings
+func (c Controller) Setup(rw http.ResponseWriter, r *http.Request) {
+	if c.repo.CountUsers() != 0 {
+		ui.Redirect(rw, "/")
+		return
+	}
+
+	pageData := newSetupPageData()
+	defer func() {
+		ui.RenderGuest(rw, r, pageData)
+	}()
+
+	var req setupRequest
+	if err := server.UnmarshalBody(r, &req); err != nil {
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	pageData.Form = setupRequest{
+		Name:             req.Name,
+		RegisterMode:     req.RegisterMode,
+		MembersCanInvite: req.MembersCanInvite,
+	}
+
+	if err := server.ValidateRequest(req); err != nil {
+		pageData.FieldErrors = server.ExtractFIeldErrors(err).Fields
+		ui.Toast(rw, ui.Warning, "Setup failed")
+		return
+	}
+
+	if req.Password != req.PasswordConfirm {
+		pageData.FieldErrors["password_conf"] = []string{"Passwords do not match"}
+		ui.Toast(rw, ui.Warning, "Passwords do not match")
+		return
+	}
+
+	requireAdminTotp := auth.EnvRequireAdminTotp.Get()
+	settings := auth.Settings{
+		RegisterMode:     auth.RegisterMode(req.RegisterMode),
+		MembersCanInvite: req.MembersCanInvite,
+		RequireAdminTotp: &requireAdminTotp,
+	}
+
+	if auth.NeedsJwtSecret() {
+		secret, err := auth.NewJwtSecret()
+		if err != nil {
+			ui.Toast(rw, ui.Warning, "Setup failed")
+			return
+		}
+		settings.JwtSecret = secret
+	}
+
+	user := auth.User{
+		Name:          req.Name,
+		Password:      req.Password,
+		Level:         auth.LevelAdmin,
+		JwtKillSwitch: time.Now().Unix(),
+	}
+
+	if err := c.repo.CompleteSetup(&user, &settings); err != nil {
+		ui.Toast(rw, ui.Warning, "Setup failed")
+		return
+	}
+
+	auth.SetSettings(settings)
+
+	if err := c.startSession(rw, r, &user); err != nil {
+		ui.Redirect(rw, "/login")
+		return
+	}
+
+	ui.Redirect(rw, "/")
+}
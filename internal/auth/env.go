@@ -13,11 +13,37 @@ const (
 )
 
 const (
-	envRootUsername dotenv.String = "ROOT_USERNAME"
-	envRootPassword dotenv.String = "ROOT_PASSWORD"
-
-	defaultRootUsername = "admin"
-	defaultRootPassword = "admin"
+	// Registration settings are chosen during first run setup, these are only used as the defaults
+	// for instances that were set up before the settings existed
+	envEnableRegister   dotenv.Bool = "ENABLE_REGISTER"
+	envMembersCanInvite dotenv.Bool = "MEMBERS_CAN_INVITE"
 )
 
-const EnvEnableRegister dotenv.Bool = "ENABLE_REGISTER"
+// Force admin users to enable 2fa before they can use the app, this is only the default for new
+// instances as it can be changed from the instance settings page
+const EnvRequireAdminTotp dotenv.Bool = "REQUIRE_ADMIN_2FA"
+
+const (
+	// OpenID Connect single sign on, this is only enabled when OIDC_ISSUER is set
+	envOidcIssuer       dotenv.String = "OIDC_ISSUER"
+	envOidcClientId     dotenv.String = "OIDC_CLIENT_ID"
+	envOidcClientSecret dotenv.String = "OIDC_CLIENT_SECRET"
+	envOidcRedirectUrl  dotenv.String = "OIDC_REDIRECT_URL"
+	envOidcScopes       dotenv.String = "OIDC_SCOPES"
+	// Name of the identity provider as shown on the login button
+	envOidcName dotenv.String = "OIDC_NAME"
+	// Create users on first login if they do not already have an account
+	EnvOidcAutoProvision dotenv.Bool = "OIDC_AUTO_PROVISION"
+	// Link an sso login on first login to an existing user whose name is the verified email of the
+	// sso account
+	EnvOidcLinkByName dotenv.Bool = "OIDC_LINK_BY_NAME"
+	// Comma separated lists of groups from the id token that map to user levels, if either is set
+	// then the users level will be synced on every login and users in neither will be denied
+	envOidcGroupsClaim  dotenv.String = "OIDC_GROUPS_CLAIM"
+	envOidcAdminGroups  dotenv.String = "OIDC_ADMIN_GROUPS"
+	envOidcMemberGroups dotenv.String = "OIDC_MEMBER_GROUPS"
+
+	defaultOidcScopes      = "openid profile email"
+	defaultOidcName        = "SSO"
+	defaultOidcGroupsClaim = "groups"
+)
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+)
+
+// NewInvite creates a new invite with a random code
+//
+// A ttl of 0 creates an invite that never expires
+func NewInvite(creator *User, level Level, maxUses uint, ttl time.Duration) (*Invite, error) {
+	raw := make([]byte, 10)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, errors.New("failed to generate invite code")
+	}
+
+	invite := Invite{
+		Code:        strings.ToLower(totpEncoding.EncodeToString(raw)),
+		Level:       level,
+		MaxUses:     maxUses,
+		CreatedByID: creator.ID,
+	}
+
+	if ttl > 0 {
+		expires := time.Now().Add(ttl)
+		invite.ExpiresAt = &expires
+	}
+
+	return &invite, nil
+}
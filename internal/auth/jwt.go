@@ -3,7 +3,6 @@ package auth
 import (
 	"errors"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
@@ -25,14 +24,17 @@ type Claims struct {
 // GenerateJWT will generate a new JWT for the given account model
 func GenerateJWT(claims jwt.Claims) (string, error) {
 	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).
-		SignedString([]byte(envJwtSecret.Get()))
+		SignedString(jwtSecret())
 }
 
 // GenerateUserJwt genertes a new JWT specifically for a user login session
-func GenerateUserJwt(id uint, name string, level uint8, killSwitch int64) (string, error) {
+//
+// sessionId is used as the ID claim and must match the JwtID of a Session record for the jwt to be
+// accepted by the auth middleware
+func GenerateUserJwt(id uint, name string, level uint8, killSwitch int64, sessionId string) (string, error) {
 	return GenerateJWT(Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ID: strconv.Itoa(int(time.Now().Unix())),
+			ID: sessionId,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(
 				time.Duration(envJwtTTl.Get()) * time.Second,
 			)),
@@ -44,6 +46,67 @@ func GenerateUserJwt(id uint, name string, level uint8, killSwitch int64) (strin
 	})
 }
 
+// TwoFactorClaims identify a user that has passed the password check but has yet to provide their
+// totp code, they are not accepted anywhere a login session is required
+type TwoFactorClaims struct {
+	jwt.RegisteredClaims
+
+	UserId     uint  `json:"uid"`
+	KillSwitch int64 `json:"kil"`
+}
+
+const (
+	twoFactorSubject = "2fa"
+	twoFactorTTL     = 5 * time.Minute
+)
+
+// GenerateTwoFactorJwt generates a short lived JWT used to carry a user between the password and
+// totp steps of the login process
+func GenerateTwoFactorJwt(id uint, killSwitch int64) (string, error) {
+	return GenerateJWT(TwoFactorClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   twoFactorSubject,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(twoFactorTTL)),
+		},
+		UserId:     id,
+		KillSwitch: killSwitch,
+	})
+}
+
+// VerifyTwoFactorJwt finds the user that is part way through a 2fa login
+func VerifyTwoFactorJwt(r *http.Request, repo Repository) (*User, error) {
+	c, err := r.Cookie(cookie.TwoFactorKey)
+	if err != nil {
+		return nil, ErrInvalidJWT
+	}
+
+	var claims TwoFactorClaims
+	if err := parseClaims(c.Value, &claims); err != nil {
+		return nil, err
+	}
+
+	if claims.Subject != twoFactorSubject {
+		return nil, ErrInvalidJWT
+	}
+
+	user, err := repo.FindUser(claims.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.JwtKillSwitch != claims.KillSwitch || !user.TotpEnabled {
+		return nil, ErrInvalidJWT
+	}
+
+	// any token issued before a lockout is no longer valid, this includes the one used to trigger it
+	if claims.IssuedAt == nil || claims.IssuedAt.Before(user.TotpLockedUntil) {
+		return nil, ErrInvalidJWT
+	}
+
+	return user, nil
+}
+
 // extractJwtFromAuthHeader will verify that the Authorization header both exists and is in the
 // Bearer format, if so it will extract the token (hopefully this should be a valid JWT)
 func extractJwtFromAuthHeader(r *http.Request) string {
@@ -77,22 +140,35 @@ func extractJwtFromCookie(r *http.Request) string {
 
 // VerifyJwt will check that the JWT is both a jwt and valid
 func verifyJwt(jwtString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(jwtString, &Claims{}, func(token *jwt.Token) (any, error) {
+	var claims Claims
+	if err := parseClaims(jwtString, &claims); err != nil {
+		return nil, err
+	}
+
+	if claims.Subject == twoFactorSubject {
+		return nil, ErrInvalidJWT
+	}
+
+	return &claims, nil
+}
+
+// parseClaims validates the jwt string and populates the provided claims
+func parseClaims(jwtString string, claims jwt.Claims) error {
+	token, err := jwt.ParseWithClaims(jwtString, claims, func(token *jwt.Token) (any, error) {
 		if token.Method.Alg() != "HS256" {
 			return nil, ErrInvalidJWT
 		}
 
-		return []byte(envJwtSecret.Get()), nil
+		return jwtSecret(), nil
 	})
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	claims, ok := token.Claims.(*Claims)
-	if !ok || !token.Valid {
-		return nil, ErrInvalidJWT
+	if !token.Valid {
+		return ErrInvalidJWT
 	}
 
-	return claims, nil
+	return nil
 }
@@ -3,6 +3,8 @@ package auth
 import (
 	"context"
 	"net/http"
+	"strings"
+	"sync/atomic"
 
 	"github.com/indeedhat/barista/internal/server"
 )
@@ -21,11 +23,41 @@ func IsLoggedInMiddleware(rt RouteType, repo Repository) server.Middleware {
 	}
 }
 
+// SetupRequiredMiddleware redirects all requests to the setup wizard until the first user has been
+// created
+func SetupRequiredMiddleware(repo Repository) server.Middleware {
+	// once setup is complete it can never go back so there is no need to keep checking the db
+	var complete atomic.Bool
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(rw http.ResponseWriter, r *http.Request) {
+			if complete.Load() || r.URL.Path == "/setup" || strings.HasPrefix(r.URL.Path, "/assets/") {
+				next(rw, r)
+				return
+			}
+
+			if repo.CountUsers() != 0 {
+				complete.Store(true)
+				next(rw, r)
+				return
+			}
+
+			if r.Header.Get("HX-Request") != "" {
+				rw.Header().Set("HX-Redirect", "/setup")
+				rw.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			http.Redirect(rw, r, "/setup", http.StatusSeeOther)
+		}
+	}
+}
+
 // UserHasPermissionMiddleware checks if the logged in user has a specific permission level
 func UserHasPermissionMiddleware(rt RouteType, level Level, repo Repository) server.Middleware {
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(rw http.ResponseWriter, r *http.Request) {
-			user := parseJwt(r, repo)
+			user, session := parseJwt(r, repo)
 			if user == nil {
 				redirectOrHeader(rw, r, http.StatusUnauthorized, rt, "/login")
 				return
@@ -36,7 +68,15 @@ func UserHasPermissionMiddleware(rt RouteType, level Level, repo Repository) ser
 				return
 			}
 
-			r = r.WithContext(r.Context().(server.Context).WithValue("user", user))
+			if user.RequiresTotpEnrolment() && !allowedBeforeTotpEnrolment(r) {
+				redirectOrHeader(rw, r, http.StatusForbidden, rt, "/user/settings")
+				return
+			}
+
+			r = r.WithContext(r.Context().(server.Context).
+				WithValue("user", user).
+				WithValue("session", session),
+			)
 			next(rw, r)
 		}
 	}
@@ -51,7 +91,7 @@ func UserHasPermissionMiddleware(rt RouteType, level Level, repo Repository) ser
 func AdminOrSelfMiddleware(rt RouteType, repo Repository) server.Middleware {
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(rw http.ResponseWriter, r *http.Request) {
-			user := parseJwt(r, repo)
+			user, session := parseJwt(r, repo)
 			if user == nil {
 				redirectOrHeader(rw, r, http.StatusUnauthorized, rt, "/login")
 				return
@@ -68,7 +108,8 @@ func AdminOrSelfMiddleware(rt RouteType, repo Repository) server.Middleware {
 				return
 			}
 
-			r = r.WithContext(context.WithValue(r.Context(), "user", user))
+			ctx := context.WithValue(r.Context(), "user", user)
+			r = r.WithContext(context.WithValue(ctx, "session", session))
 			next(rw, r)
 		}
 	}
@@ -78,7 +119,7 @@ func AdminOrSelfMiddleware(rt RouteType, repo Repository) server.Middleware {
 func IsGuestMiddleware(rt RouteType, repo Repository) server.Middleware {
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(rw http.ResponseWriter, r *http.Request) {
-			user := parseJwt(r, repo)
+			user, _ := parseJwt(r, repo)
 			if user != nil {
 				redirectOrHeader(rw, r, http.StatusForbidden, rt, "/")
 				return
@@ -89,27 +130,47 @@ func IsGuestMiddleware(rt RouteType, repo Repository) server.Middleware {
 	}
 }
 
-func parseJwt(r *http.Request, repo Repository) *User {
+// UserFromRequest finds the logged in user for requests that are not behind one of the auth
+// middlewares
+func UserFromRequest(r *http.Request, repo Repository) *User {
+	user, _ := parseJwt(r, repo)
+	return user
+}
+
+func parseJwt(r *http.Request, repo Repository) (*User, *Session) {
 	jwt := extractJwtFromCookie(r)
 	if jwt == "" {
-		return nil
+		return nil, nil
 	}
 
 	claims, err := verifyJwt(jwt)
 	if err != nil {
-		return nil
+		return nil, nil
 	}
 
 	user, err := repo.FindUser(claims.UserId)
 	if err != nil {
-		return nil
+		return nil, nil
 	}
 
 	if user.JwtKillSwitch != claims.KillSwitch {
-		return nil
+		return nil, nil
 	}
 
-	return user
+	session, err := repo.FindSession(claims.ID, user.ID)
+	if err != nil {
+		return nil, nil
+	}
+
+	touchSession(r, session, repo)
+
+	return user, session
+}
+
+// allowedBeforeTotpEnrolment checks if the request can be made by a user that has been required to
+// enable 2fa but has not yet done so
+func allowedBeforeTotpEnrolment(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/user/") || r.URL.Path == "/logout"
 }
 
 func redirectOrHeader(rw http.ResponseWriter, r *http.Request, code int, rt RouteType, url string) {
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"time"
+
 	"github.com/indeedhat/barista/internal/database/model"
 )
 
@@ -20,6 +22,53 @@ type User struct {
 	Password      string `gorm:"->:false;<-:create"`
 	Level         Level
 	JwtKillSwitch int64
+
+	// TotpSecret is set as soon as the user starts 2fa enrolment but will only be checked on login
+	// once the enrolment is confirmed and TotpEnabled is set
+	TotpSecret   string `json:"-"`
+	TotpEnabled  bool
+	TotpLastStep int64 `json:"-"`
+	// TotpFailures is the number of wrong codes entered during login since the last successful one,
+	// once it reaches totpMaxFailures 2fa logins are locked until TotpLockedUntil
+	TotpFailures    uint      `json:"-"`
+	TotpLockedUntil time.Time `json:"-"`
+
+	// OidcSubject is the subject claim of the sso account linked to this user
+	OidcSubject *string `gorm:"uniqueIndex" json:"-"`
+
+	// InviteID is the invite used to register the user, if any
+	InviteID *uint
+	Invite   *Invite
+}
+
+// IsAdmin checks if the user has admin permissions
+func (u User) IsAdmin() bool {
+	return u.Level == LevelAdmin
+}
+
+// CanInvite checks if the user is allowed to create invites
+func (u User) CanInvite() bool {
+	settings := CurrentSettings()
+	if settings.RegisterMode == RegisterClosed {
+		return false
+	}
+
+	return u.Level == LevelAdmin || (u.Level == LevelMember && settings.MembersCanInvite)
+}
+
+// TotpRequired checks if the user is required to have 2fa enabled
+func (u User) TotpRequired() bool {
+	return u.Level == LevelAdmin && CurrentSettings().AdminTotpRequired()
+}
+
+// TotpLocked checks if 2fa logins are locked after too many wrong codes
+func (u User) TotpLocked() bool {
+	return time.Now().Before(u.TotpLockedUntil)
+}
+
+// RequiresTotpEnrolment checks if the user must enable 2fa before they can use the app
+func (u User) RequiresTotpEnrolment() bool {
+	return u.TotpRequired() && !u.TotpEnabled
 }
 
 // AuthUser is readonly and only used during the login check, it pulls back the users password hash
@@ -36,3 +85,92 @@ type AuthUser struct {
 func (AuthUser) TableName() string {
 	return "users"
 }
+
+// Session is the server side record of a login, it is keyed by the ID claim of the jwt issued to the
+// device so that individual devices can be logged out without touching the users JwtKillSwitch
+type Session struct {
+	model.SoftDelete
+
+	JwtID     string `gorm:"uniqueIndex"`
+	UserAgent string
+	IP        string
+	LastSeen  time.Time
+
+	UserID uint `gorm:"index"`
+	User   User
+}
+
+// RecoveryCode is a single use code that can be used in place of a totp code during login
+type RecoveryCode struct {
+	model.SoftDelete
+
+	Hash string `gorm:"index"`
+
+	UserID uint `gorm:"index"`
+	User   User
+}
+
+// Invite allows a user to register even when open registration is disabled
+type Invite struct {
+	model.SoftDelete
+
+	Code      string `gorm:"uniqueIndex"`
+	Level     Level
+	MaxUses   uint
+	Uses      uint
+	ExpiresAt *time.Time
+
+	CreatedByID uint `gorm:"index"`
+	CreatedBy   User `gorm:"foreignKey:CreatedByID"`
+}
+
+// Valid checks if the invite can still be used to register
+//
+// A MaxUses of 0 means the invite can be used an unlimited number of times
+func (i Invite) Valid() bool {
+	if i.ExpiresAt != nil && time.Now().After(*i.ExpiresAt) {
+		return false
+	}
+
+	return i.MaxUses == 0 || i.Uses < i.MaxUses
+}
+
+// LevelName returns the human readable name of the level the invite grants
+func (i Invite) LevelName() string {
+	if i.Level == LevelAdmin {
+		return "Admin"
+	}
+
+	return "Member"
+}
+
+type RegisterMode string
+
+const (
+	// RegisterClosed disables registration entirely, including via invites
+	RegisterClosed RegisterMode = "closed"
+	// RegisterInvite only allows users with a valid invite to register
+	RegisterInvite RegisterMode = "invite"
+	// RegisterOpen allows anyone to register
+	RegisterOpen RegisterMode = "open"
+)
+
+// Settings holds the instance wide settings chosen during first run setup
+//
+// There is only ever a single row in the settings table
+type Settings struct {
+	model.SoftDelete
+
+	// JwtSecret is only used if JWT_SECRET is not set in the env
+	JwtSecret        string `json:"-"`
+	RegisterMode     RegisterMode
+	MembersCanInvite bool
+	// RequireAdminTotp forces admin users to enable 2fa before they can use the app, it is nil for
+	// instances that were set up before the setting existed until it is defaulted from the env
+	RequireAdminTotp *bool
+}
+
+// AdminTotpRequired checks if admin users must have 2fa enabled
+func (s Settings) AdminTotpRequired() bool {
+	return s.RequireAdminTotp != nil && *s.RequireAdminTotp
+}
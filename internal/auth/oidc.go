@@ -0,0 +1,293 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/indeedhat/barista/internal/cookie"
+	"golang.org/x/oauth2"
+)
+
+var (
+	ErrOidcDisabled  = errors.New("sso login is not enabled")
+	ErrOidcForbidden = errors.New("sso account is not in an allowed group")
+)
+
+const (
+	oidcStateSubject = "oidc"
+	oidcStateTTL     = 10 * time.Minute
+)
+
+// OidcProvider handles the authorization code flow against the configured identity provider
+//
+// Discovery is done lazily on first use so that the app can still start if the identity provider
+// is unavailable
+type OidcProvider struct {
+	mux      sync.Mutex
+	verifier *oidc.IDTokenVerifier
+	config   *oauth2.Config
+}
+
+// NewOidcProvider creates a new provider from the env config
+//
+// If sso is not configured then nil is returned
+func NewOidcProvider() *OidcProvider {
+	if envOidcIssuer.Get() == "" {
+		return nil
+	}
+
+	return &OidcProvider{}
+}
+
+// Enabled checks if sso login is configured
+func (p *OidcProvider) Enabled() bool {
+	return p != nil
+}
+
+// Name returns the display name of the identity provider
+func (p *OidcProvider) Name() string {
+	return envOidcName.Get(defaultOidcName)
+}
+
+// OidcIdentity is the subset of the id token claims used by barista
+type OidcIdentity struct {
+	Subject string
+	Name    string
+	// VerifiedEmail is the email claim, it is only set when the identity provider has marked it as
+	// verified
+	VerifiedEmail string
+	Groups        []string
+}
+
+// Level maps the identities groups to a user level
+//
+// If no group mapping has been configured then ok will be true and level will be zero, the caller
+// should leave the users level unchanged
+func (i OidcIdentity) Level() (level Level, ok bool) {
+	admin := splitEnvList(envOidcAdminGroups.Get())
+	member := splitEnvList(envOidcMemberGroups.Get())
+
+	if len(admin) == 0 && len(member) == 0 {
+		return 0, true
+	}
+
+	for _, group := range i.Groups {
+		if slices.Contains(admin, group) {
+			return LevelAdmin, true
+		}
+	}
+
+	for _, group := range i.Groups {
+		if slices.Contains(member, group) {
+			return LevelMember, true
+		}
+	}
+
+	return 0, false
+}
+
+// NewOidcUser creates a user for an sso identity that does not yet have an account
+//
+// The user is given a random password, they will only be able to log in via sso
+func NewOidcUser(identity *OidcIdentity, level Level) User {
+	if level == 0 {
+		level = LevelMember
+	}
+
+	return User{
+		Name:          identity.Name,
+		Password:      randomString(),
+		Level:         level,
+		JwtKillSwitch: time.Now().Unix(),
+		OidcSubject:   &identity.Subject,
+	}
+}
+
+// OidcStateClaims carry the values needed to complete the login flow between the redirect to the
+// identity provider and the callback
+type OidcStateClaims struct {
+	jwt.RegisteredClaims
+
+	State    string `json:"sta"`
+	Nonce    string `json:"non"`
+	Verifier string `json:"ver"`
+	// LinkUserId is set when a logged in user is linking their account
+	LinkUserId uint `json:"lnk,omitempty"`
+}
+
+// AuthCodeURL starts the authorization code flow, the state of the flow is stored in a cookie and
+// the url of the identity providers login page is returned
+func (p *OidcProvider) AuthCodeURL(rw http.ResponseWriter, r *http.Request, linkUserId uint) (string, error) {
+	config, _, err := p.load(r.Context())
+	if err != nil {
+		return "", err
+	}
+
+	claims := OidcStateClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   oidcStateSubject,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(oidcStateTTL)),
+		},
+		State:      randomString(),
+		Nonce:      randomString(),
+		Verifier:   oauth2.GenerateVerifier(),
+		LinkUserId: linkUserId,
+	}
+
+	token, err := GenerateJWT(claims)
+	if err != nil {
+		return "", err
+	}
+
+	cookie.Set(rw, r, cookie.OidcKey, token)
+
+	return config.AuthCodeURL(
+		claims.State,
+		oidc.Nonce(claims.Nonce),
+		oauth2.S256ChallengeOption(claims.Verifier),
+	), nil
+}
+
+// Exchange completes the authorization code flow from the callback request
+func (p *OidcProvider) Exchange(rw http.ResponseWriter, r *http.Request) (*OidcIdentity, *OidcStateClaims, error) {
+	config, verifier, err := p.load(r.Context())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c, err := r.Cookie(cookie.OidcKey)
+	if err != nil {
+		return nil, nil, errors.New("sso login state not found")
+	}
+	cookie.Delete(rw, r, cookie.OidcKey)
+
+	var state OidcStateClaims
+	if err := parseClaims(c.Value, &state); err != nil || state.Subject != oidcStateSubject {
+		return nil, nil, errors.New("sso login state is invalid")
+	}
+
+	query := r.URL.Query()
+	if query.Get("state") != state.State {
+		return nil, nil, errors.New("sso login state does not match")
+	}
+
+	if e := query.Get("error"); e != "" {
+		return nil, nil, errors.New("sso login failed: " + e)
+	}
+
+	token, err := config.Exchange(r.Context(), query.Get("code"), oauth2.VerifierOption(state.Verifier))
+	if err != nil {
+		return nil, nil, errors.New("sso code exchange failed")
+	}
+
+	rawIdToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, nil, errors.New("sso response did not contain an id token")
+	}
+
+	idToken, err := verifier.Verify(r.Context(), rawIdToken)
+	if err != nil {
+		return nil, nil, errors.New("sso id token is invalid")
+	}
+
+	if idToken.Nonce != state.Nonce {
+		return nil, nil, errors.New("sso id token nonce does not match")
+	}
+
+	identity, err := extractIdentity(idToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return identity, &state, nil
+}
+
+// load runs discovery against the identity provider if it has not already been done
+func (p *OidcProvider) load(ctx context.Context) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	if p == nil {
+		return nil, nil, ErrOidcDisabled
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if p.config != nil {
+		return p.config, p.verifier, nil
+	}
+
+	provider, err := oidc.NewProvider(context.WithoutCancel(ctx), envOidcIssuer.Get())
+	if err != nil {
+		return nil, nil, errors.New("sso provider discovery failed")
+	}
+
+	p.config = &oauth2.Config{
+		ClientID:     envOidcClientId.Get(),
+		ClientSecret: envOidcClientSecret.Get(),
+		RedirectURL:  envOidcRedirectUrl.Get(),
+		Endpoint:     provider.Endpoint(),
+		Scopes:       strings.Fields(envOidcScopes.Get(defaultOidcScopes)),
+	}
+	p.verifier = provider.Verifier(&oidc.Config{ClientID: envOidcClientId.Get()})
+
+	return p.config, p.verifier, nil
+}
+
+func extractIdentity(idToken *oidc.IDToken) (*OidcIdentity, error) {
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, errors.New("sso id token claims could not be read")
+	}
+
+	identity := OidcIdentity{Subject: idToken.Subject}
+
+	for _, key := range []string{"preferred_username", "name", "email"} {
+		if name, ok := claims[key].(string); ok && name != "" {
+			identity.Name = name
+			break
+		}
+	}
+
+	if verified, _ := claims["email_verified"].(bool); verified {
+		identity.VerifiedEmail, _ = claims["email"].(string)
+	}
+
+	switch groups := claims[envOidcGroupsClaim.Get(defaultOidcGroupsClaim)].(type) {
+	case []any:
+		for _, group := range groups {
+			if g, ok := group.(string); ok {
+				identity.Groups = append(identity.Groups, g)
+			}
+		}
+	case string:
+		identity.Groups = splitEnvList(groups)
+	}
+
+	return &identity, nil
+}
+
+func splitEnvList(value string) []string {
+	var list []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+
+	return list
+}
+
+func randomString() string {
+	data := make([]byte, 16)
+	_, _ = rand.Read(data)
+
+	return hex.EncodeToString(data)
+}
@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/indeedhat/barista/internal/auth/oidctest"
+	"github.com/indeedhat/barista/internal/cookie"
+)
+
+const testRedirectUrl = "http://barista.test/login/oidc/callback"
+
+// newTestOidc starts a mock identity provider and configures sso against it
+func newTestOidc(t *testing.T) (*OidcProvider, *oidctest.Provider) {
+	t.Helper()
+
+	mock, err := oidctest.NewProvider("", "barista")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(mock)
+	t.Cleanup(server.Close)
+	mock.Issuer = server.URL
+
+	t.Setenv(string(envJwtSecret), "oidc-test-secret")
+	t.Setenv(string(envOidcIssuer), server.URL)
+	t.Setenv(string(envOidcClientId), "barista")
+	t.Setenv(string(envOidcClientSecret), "secret")
+	t.Setenv(string(envOidcRedirectUrl), testRedirectUrl)
+	t.Setenv(string(envOidcAdminGroups), "admins")
+	t.Setenv(string(envOidcMemberGroups), "members")
+
+	return NewOidcProvider(), mock
+}
+
+// authorize starts a login and follows the mock providers redirect, the callback request is returned
+// with the state cookie set
+func authorize(t *testing.T, provider *OidcProvider) (*http.Request, url.Values) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	authUrl, err := provider.AuthCodeURL(rec, httptest.NewRequest(http.MethodGet, "/login/oidc", nil), 0)
+	if err != nil {
+		t.Fatalf("auth code url: %s", err)
+	}
+
+	parsed, _ := url.Parse(authUrl)
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		t.Errorf("auth code url is missing the PKCE challenge: %s", authUrl)
+	}
+	if query.Get("state") == "" || query.Get("nonce") == "" {
+		t.Errorf("auth code url is missing the state or nonce: %s", authUrl)
+	}
+
+	client := http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	res, err := client.Get(authUrl)
+	if err != nil {
+		t.Fatalf("authorize: %s", err)
+	}
+	res.Body.Close()
+
+	callback, err := res.Location()
+	if err != nil {
+		t.Fatalf("authorize did not redirect: %d", res.StatusCode)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, callback.String(), nil)
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == cookie.OidcKey {
+			req.AddCookie(c)
+		}
+	}
+
+	return req, callback.Query()
+}
+
+func TestOidcLogin(t *testing.T) {
+	provider, mock := newTestOidc(t)
+	mock.Subject = "sub-1"
+	mock.Name = "alice"
+	mock.Groups = []string{"staff", "admins"}
+
+	req, _ := authorize(t, provider)
+
+	identity, state, err := provider.Exchange(httptest.NewRecorder(), req)
+	if err != nil {
+		t.Fatalf("exchange: %s", err)
+	}
+
+	if identity.Subject != "sub-1" || identity.Name != "alice" {
+		t.Errorf("identity: got %+v", identity)
+	}
+	if state.LinkUserId != 0 {
+		t.Errorf("link user: got %d", state.LinkUserId)
+	}
+	if level, ok := identity.Level(); !ok || level != LevelAdmin {
+		t.Errorf("level: got %d %v", level, ok)
+	}
+}
+
+func TestOidcStateMismatch(t *testing.T) {
+	provider, _ := newTestOidc(t)
+
+	req, query := authorize(t, provider)
+	query.Set("state", "tampered")
+	req.URL.RawQuery = query.Encode()
+
+	if _, _, err := provider.Exchange(httptest.NewRecorder(), req); err == nil {
+		t.Error("expected a state mismatch error")
+	}
+}
+
+func TestOidcMissingStateCookie(t *testing.T) {
+	provider, _ := newTestOidc(t)
+
+	req, _ := authorize(t, provider)
+	bare := httptest.NewRequest(http.MethodGet, req.URL.String(), nil)
+
+	if _, _, err := provider.Exchange(httptest.NewRecorder(), bare); err == nil {
+		t.Error("expected a missing state error")
+	}
+}
+
+func TestOidcPkceVerifier(t *testing.T) {
+	provider, _ := newTestOidc(t)
+
+	// the code from one login cannot be redeemed with the state, and so the verifier, of another
+	first, firstQuery := authorize(t, provider)
+	second, secondQuery := authorize(t, provider)
+	secondQuery.Set("code", firstQuery.Get("code"))
+	second.URL.RawQuery = secondQuery.Encode()
+
+	if _, _, err := provider.Exchange(httptest.NewRecorder(), second); err == nil {
+		t.Error("expected the code exchange to fail with the wrong verifier")
+	}
+
+	// the rejected exchange used up the code
+	if _, _, err := provider.Exchange(httptest.NewRecorder(), first); err == nil {
+		t.Error("expected the used code to be rejected")
+	}
+}
+
+func TestOidcNonceMismatch(t *testing.T) {
+	provider, mock := newTestOidc(t)
+	mock.Nonce = "replayed"
+
+	req, _ := authorize(t, provider)
+
+	if _, _, err := provider.Exchange(httptest.NewRecorder(), req); err == nil {
+		t.Error("expected a nonce mismatch error")
+	}
+}
+
+func TestOidcGroupMapping(t *testing.T) {
+	provider, mock := newTestOidc(t)
+
+	for _, tc := range []struct {
+		groups []string
+		level  Level
+		ok     bool
+	}{
+		{[]string{"admins"}, LevelAdmin, true},
+		{[]string{"members", "admins"}, LevelAdmin, true},
+		{[]string{"members"}, LevelMember, true},
+		{[]string{"other"}, 0, false},
+		{nil, 0, false},
+	} {
+		mock.Groups = tc.groups
+
+		req, _ := authorize(t, provider)
+		identity, _, err := provider.Exchange(httptest.NewRecorder(), req)
+		if err != nil {
+			t.Fatalf("%v: exchange: %s", tc.groups, err)
+		}
+
+		level, ok := identity.Level()
+		if level != tc.level || ok != tc.ok {
+			t.Errorf("%v: got %d %v", tc.groups, level, ok)
+		}
+	}
+
+	t.Setenv(string(envOidcAdminGroups), "")
+	t.Setenv(string(envOidcMemberGroups), "")
+	if level, ok := (OidcIdentity{Groups: []string{"other"}}).Level(); level != 0 || !ok {
+		t.Errorf("unmapped: got %d %v", level, ok)
+	}
+}
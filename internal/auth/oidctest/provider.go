@@ -0,0 +1,221 @@
+// Package oidctest is a minimal OpenID Connect identity provider for testing sso logins against
+//
+// It supports discovery, the authorization code flow with PKCE and RS256 signed id tokens. There is
+// no login page, every authorization request is approved straight away for the configured identity
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyId = "oidctest"
+
+// Provider is the mock identity provider, it is an http.Handler so it can be served from either
+// httptest or a real listener
+type Provider struct {
+	// Issuer is the base url the provider is served from, it must be set before the provider is used
+	Issuer   string
+	ClientID string
+
+	// Subject, Name, Email and Groups make up the identity that is returned in the id token, the
+	// email claims are left out when Email is empty
+	Subject       string
+	Name          string
+	Email         string
+	EmailVerified bool
+	Groups        []string
+
+	// Nonce replaces the nonce from the authorization request in the id token when set, this is used
+	// to test that mismatched nonces are rejected
+	Nonce string
+
+	key   *rsa.PrivateKey
+	mux   sync.Mutex
+	codes map[string]authRequest
+	http  *http.ServeMux
+}
+
+// authRequest is what the provider remembers about an authorization request until its code is
+// exchanged
+type authRequest struct {
+	challenge   string
+	nonce       string
+	redirectUri string
+}
+
+// NewProvider creates a mock provider for the given client with a freshly generated signing key
+func NewProvider(issuer, clientId string) (*Provider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Provider{
+		Issuer:   issuer,
+		ClientID: clientId,
+		Subject:  "oidctest-user",
+		Name:     "oidctest",
+		key:      key,
+		codes:    make(map[string]authRequest),
+		http:     http.NewServeMux(),
+	}
+
+	p.http.HandleFunc("GET /.well-known/openid-configuration", p.discovery)
+	p.http.HandleFunc("GET /jwks", p.jwks)
+	p.http.HandleFunc("GET /authorize", p.authorize)
+	p.http.HandleFunc("POST /token", p.token)
+
+	return p, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (p *Provider) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	p.http.ServeHTTP(rw, r)
+}
+
+func (p *Provider) discovery(rw http.ResponseWriter, r *http.Request) {
+	writeJson(rw, http.StatusOK, map[string]any{
+		"issuer":                                p.Issuer,
+		"authorization_endpoint":                p.Issuer + "/authorize",
+		"token_endpoint":                        p.Issuer + "/token",
+		"jwks_uri":                              p.Issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (p *Provider) jwks(rw http.ResponseWriter, r *http.Request) {
+	writeJson(rw, http.StatusOK, map[string]any{
+		"keys": []map[string]any{{
+			"kty": "RSA",
+			"kid": keyId,
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+// authorize approves the request and redirects straight back to the client with a code
+func (p *Provider) authorize(rw http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || query.Get("redirect_uri") == "" {
+		http.Error(rw, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	if query.Get("client_id") != p.ClientID {
+		http.Error(rw, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(rw, "PKCE with S256 is required", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+
+	p.mux.Lock()
+	p.codes[code] = authRequest{
+		challenge:   query.Get("code_challenge"),
+		nonce:       query.Get("nonce"),
+		redirectUri: query.Get("redirect_uri"),
+	}
+	p.mux.Unlock()
+
+	redirect.RawQuery = url.Values{
+		"code":  {code},
+		"state": {query.Get("state")},
+	}.Encode()
+
+	http.Redirect(rw, r, redirect.String(), http.StatusFound)
+}
+
+// token exchanges a code for an id token, the PKCE verifier must match the challenge that was sent
+// with the authorization request
+func (p *Provider) token(rw http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJson(rw, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	p.mux.Lock()
+	req, ok := p.codes[r.Form.Get("code")]
+	delete(p.codes, r.Form.Get("code"))
+	p.mux.Unlock()
+
+	sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+	if !ok ||
+		req.redirectUri != r.Form.Get("redirect_uri") ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != req.challenge {
+		writeJson(rw, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	nonce := req.nonce
+	if p.Nonce != "" {
+		nonce = p.Nonce
+	}
+
+	claims := jwt.MapClaims{
+		"iss":                p.Issuer,
+		"sub":                p.Subject,
+		"aud":                p.ClientID,
+		"iat":                time.Now().Unix(),
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"nonce":              nonce,
+		"preferred_username": p.Name,
+		"groups":             p.Groups,
+	}
+	if p.Email != "" {
+		claims["email"] = p.Email
+		claims["email_verified"] = p.EmailVerified
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyId
+
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		writeJson(rw, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJson(rw, http.StatusOK, map[string]any{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func writeJson(rw http.ResponseWriter, status int, data any) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_ = json.NewEncoder(rw).Encode(data)
+}
+
+func randomString() string {
+	data := make([]byte, 16)
+	_, _ = rand.Read(data)
+
+	return hex.EncodeToString(data)
+}
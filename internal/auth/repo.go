@@ -2,18 +2,46 @@ package auth
 
 import (
 	"errors"
-	"fmt"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 type Repository interface {
-	CreateRootUser() error
+	CountUsers() int64
+	IndexUsers() []User
 	FindUser(id uint) (*User, error)
 	FindUserByLogin(name, password string) (*User, error)
+	FindUserByName(name string) (*User, error)
+	FindUserByOidcSubject(subject string) (*User, error)
 	SaveUser(*User) error
 	UpdateUserPassword(*User, string) error
+	RecordTotpFailure(*User) error
+	ResetTotpFailures(*User) error
+	UseTotpStep(*User, int64) error
+
+	IndexSessionsForUser(*User) []Session
+	FindSession(jwtId string, userId uint) (*Session, error)
+	SaveSession(*Session) error
+	DeleteSession(*Session) error
+	DeleteOtherSessions(*User, *Session) error
+	DeleteExpiredSessions(before time.Time) (int64, error)
+
+	CountRecoveryCodes(*User) int64
+	ReplaceRecoveryCodes(*User, []RecoveryCode) error
+	UseRecoveryCode(*User, string) error
+
+	IndexInvites() []Invite
+	IndexInvitesForUser(*User) []Invite
+	FindInvite(id uint) (*Invite, error)
+	FindInviteByCode(code string) (*Invite, error)
+	SaveInvite(*Invite) error
+	DeleteInvite(*Invite) error
+	RegisterWithInvite(*User, *Invite) error
+
+	FindSettings() (*Settings, error)
+	SaveSettings(*Settings) error
+	CompleteSetup(*User, *Settings) error
 }
 
 type SqliteRepository struct {
@@ -24,26 +52,18 @@ func NewSqliteRepo(db *gorm.DB) Repository {
 	return SqliteRepository{db}
 }
 
-func (r SqliteRepository) CreateRootUser() error {
-	pwd, err := hashPassword(envRootPassword.Get(defaultRootPassword))
-	if err != nil {
-		return fmt.Errorf("failed to hash password: %w", err)
-	}
-
-	root := User{
-		Name:          envRootUsername.Get(defaultRootUsername),
-		Password:      pwd,
-		Level:         LevelAdmin,
-		JwtKillSwitch: time.Now().Unix(),
-	}
+// CountUsers implements Repository.
+func (r SqliteRepository) CountUsers() int64 {
+	var count int64
+	r.db.Model(&User{}).Count(&count)
 
-	return r.SaveUser(&root)
+	return count
 }
 
 // FindUserByLogin implements Repository.
 func (r SqliteRepository) FindUserByLogin(name string, password string) (*User, error) {
 	var authUser AuthUser
-	if err := r.db.Where("name LIKE ?", name).First(&authUser).Error; err != nil {
+	if err := r.db.Where("LOWER(name) = LOWER(?)", name).First(&authUser).Error; err != nil {
 		return nil, err
 	}
 
@@ -54,6 +74,49 @@ func (r SqliteRepository) FindUserByLogin(name string, password string) (*User,
 	return r.FindUser(authUser.ID)
 }
 
+// IndexUsers implements Repository.
+//
+// Deleted invites and their creators are still loaded so the users page can show how each user
+// registered
+func (r SqliteRepository) IndexUsers() []User {
+	var users []User
+
+	unscoped := func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}
+
+	r.db.Preload("Invite", unscoped).
+		Preload("Invite.CreatedBy", unscoped).
+		Order("name ASC").
+		Find(&users)
+
+	return users
+}
+
+// FindUserByName implements Repository.
+//
+// Names are matched exactly, ignoring case
+func (r SqliteRepository) FindUserByName(name string) (*User, error) {
+	var user User
+
+	if err := r.db.Where("LOWER(name) = LOWER(?)", name).First(&user).Error; err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// FindUserByOidcSubject implements Repository.
+func (r SqliteRepository) FindUserByOidcSubject(subject string) (*User, error) {
+	var user User
+
+	if err := r.db.Where("oidc_subject = ?", subject).First(&user).Error; err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
 // FindUser implements Repository.
 func (r SqliteRepository) FindUser(id uint) (*User, error) {
 	var user User
@@ -85,4 +148,265 @@ func (r SqliteRepository) UpdateUserPassword(user *User, password string) error
 	return r.db.Model(user).UpdateColumn("password", hash).Error
 }
 
+// RecordTotpFailure implements Repository.
+//
+// The count is incremented in the database so that parallel attempts cannot skip the limit, once it
+// is reached 2fa logins are locked and the count starts again. The users fields are updated to match
+func (r SqliteRepository) RecordTotpFailure(user *User) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&User{}).
+			Where("id = ?", user.ID).
+			UpdateColumn("totp_failures", gorm.Expr("totp_failures + 1")).
+			Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&User{}).
+			Where("id = ? AND totp_failures >= ?", user.ID, totpMaxFailures).
+			UpdateColumns(map[string]any{
+				"totp_failures":     0,
+				"totp_locked_until": time.Now().Add(totpLockout),
+			}).
+			Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Select("totp_failures", "totp_locked_until").First(user, user.ID).Error
+	})
+}
+
+// ResetTotpFailures implements Repository.
+func (r SqliteRepository) ResetTotpFailures(user *User) error {
+	user.TotpFailures = 0
+	return r.db.Model(user).UpdateColumn("totp_failures", 0).Error
+}
+
+// UseTotpStep implements Repository.
+//
+// The step is only saved if it is newer than the one in the database so that two logins racing
+// with the same code cannot both succeed
+func (r SqliteRepository) UseTotpStep(user *User, step int64) error {
+	tx := r.db.Model(&User{}).
+		Where("id = ? AND totp_last_step < ?", user.ID, step).
+		UpdateColumn("totp_last_step", step)
+
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if tx.RowsAffected == 0 {
+		return errors.New("totp code has already been used")
+	}
+
+	user.TotpLastStep = step
+	return nil
+}
+
+// IndexSessionsForUser implements Repository.
+func (r SqliteRepository) IndexSessionsForUser(user *User) []Session {
+	var sessions []Session
+
+	r.db.Where("user_id = ?", user.ID).
+		Order("last_seen DESC").
+		Find(&sessions)
+
+	return sessions
+}
+
+// FindSession implements Repository.
+func (r SqliteRepository) FindSession(jwtId string, userId uint) (*Session, error) {
+	var session Session
+
+	if err := r.db.Where("jwt_id = ? AND user_id = ?", jwtId, userId).First(&session).Error; err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// SaveSession implements Repository.
+func (r SqliteRepository) SaveSession(session *Session) error {
+	return r.db.Save(session).Error
+}
+
+// DeleteSession implements Repository.
+func (r SqliteRepository) DeleteSession(session *Session) error {
+	return r.db.Delete(session).Error
+}
+
+// DeleteOtherSessions implements Repository.
+func (r SqliteRepository) DeleteOtherSessions(user *User, keep *Session) error {
+	return r.db.Where("user_id = ? AND id != ?", user.ID, keep.ID).Delete(&Session{}).Error
+}
+
+// DeleteExpiredSessions implements Repository.
+//
+// Sessions created before the given time are removed along with any that have already been logged
+// out, neither can be used again so they are deleted for good
+func (r SqliteRepository) DeleteExpiredSessions(before time.Time) (int64, error) {
+	tx := r.db.Unscoped().
+		Where("julianday(created_at) < julianday(?) OR deleted_at IS NOT NULL", before).
+		Delete(&Session{})
+
+	return tx.RowsAffected, tx.Error
+}
+
+// CountRecoveryCodes implements Repository.
+func (r SqliteRepository) CountRecoveryCodes(user *User) int64 {
+	var count int64
+
+	r.db.Model(&RecoveryCode{}).Where("user_id = ?", user.ID).Count(&count)
+
+	return count
+}
+
+// ReplaceRecoveryCodes implements Repository.
+func (r SqliteRepository) ReplaceRecoveryCodes(user *User, codes []RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", user.ID).Delete(&RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		if len(codes) == 0 {
+			return nil
+		}
+
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode implements Repository.
+func (r SqliteRepository) UseRecoveryCode(user *User, code string) error {
+	tx := r.db.Where("user_id = ? AND hash = ?", user.ID, hashRecoveryCode(code)).
+		Delete(&RecoveryCode{})
+
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if tx.RowsAffected == 0 {
+		return errors.New("recovery code not found")
+	}
+
+	return nil
+}
+
+// IndexInvites implements Repository.
+func (r SqliteRepository) IndexInvites() []Invite {
+	var invites []Invite
+
+	r.db.Preload("CreatedBy").
+		Order("created_at DESC").
+		Find(&invites)
+
+	return invites
+}
+
+// IndexInvitesForUser implements Repository.
+func (r SqliteRepository) IndexInvitesForUser(user *User) []Invite {
+	var invites []Invite
+
+	r.db.Preload("CreatedBy").
+		Where("created_by_id = ?", user.ID).
+		Order("created_at DESC").
+		Find(&invites)
+
+	return invites
+}
+
+// FindInvite implements Repository.
+func (r SqliteRepository) FindInvite(id uint) (*Invite, error) {
+	var invite Invite
+
+	if err := r.db.First(&invite, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &invite, nil
+}
+
+// FindInviteByCode implements Repository.
+func (r SqliteRepository) FindInviteByCode(code string) (*Invite, error) {
+	var invite Invite
+
+	if err := r.db.Where("code = ?", code).First(&invite).Error; err != nil {
+		return nil, err
+	}
+
+	return &invite, nil
+}
+
+// SaveInvite implements Repository.
+func (r SqliteRepository) SaveInvite(invite *Invite) error {
+	return r.db.Save(invite).Error
+}
+
+// DeleteInvite implements Repository.
+func (r SqliteRepository) DeleteInvite(invite *Invite) error {
+	return r.db.Delete(invite).Error
+}
+
+// RegisterWithInvite implements Repository.
+//
+// The invite use is claimed in the same transaction that creates the user so that an invite cannot
+// be used more times than it allows
+func (r SqliteRepository) RegisterWithInvite(user *User, invite *Invite) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		claim := tx.Model(&Invite{}).
+			Where("id = ? AND (max_uses = 0 OR uses < max_uses)", invite.ID).
+			UpdateColumn("uses", gorm.Expr("uses + 1"))
+		if claim.Error != nil {
+			return claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			return errors.New("invite has been used up")
+		}
+
+		user.InviteID = &invite.ID
+		user.Level = invite.Level
+
+		return SqliteRepository{tx}.SaveUser(user)
+	})
+}
+
 var _ Repository = (*SqliteRepository)(nil)
+
+// FindSettings implements Repository.
+func (r SqliteRepository) FindSettings() (*Settings, error) {
+	var settings Settings
+
+	if err := r.db.First(&settings).Error; err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// SaveSettings implements Repository.
+func (r SqliteRepository) SaveSettings(settings *Settings) error {
+	return r.db.Save(settings).Error
+}
+
+// CompleteSetup implements Repository.
+//
+// The user count is checked inside the transaction so that setup can only ever be completed once
+func (r SqliteRepository) CompleteSetup(user *User, settings *Settings) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&User{}).Count(&count).Error; err != nil {
+			return err
+		}
+		if count != 0 {
+			return errors.New("setup has already been completed")
+		}
+
+		repo := SqliteRepository{tx}
+		if err := repo.SaveUser(user); err != nil {
+			return err
+		}
+
+		return repo.SaveSettings(settings)
+	})
+}
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestRepo(t *testing.T) (Repository, *User) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(User{}, Session{}, RecoveryCode{}, Invite{}); err != nil {
+		t.Fatal(err)
+	}
+
+	user := User{Name: "alice", Level: LevelMember}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return NewSqliteRepo(db), &user
+}
+
+func TestUseTotpStep(t *testing.T) {
+	repo, user := newTestRepo(t)
+
+	// both logins loaded the user before either saved the step
+	first, second := *user, *user
+
+	if err := repo.UseTotpStep(&first, 10); err != nil {
+		t.Fatalf("first use: %s", err)
+	}
+	if first.TotpLastStep != 10 {
+		t.Errorf("last step: got %d", first.TotpLastStep)
+	}
+
+	if err := repo.UseTotpStep(&second, 10); err == nil {
+		t.Error("expected the step to be rejected once used")
+	}
+	if err := repo.UseTotpStep(&second, 9); err == nil {
+		t.Error("expected an older step to be rejected")
+	}
+	if err := repo.UseTotpStep(&second, 11); err != nil {
+		t.Errorf("newer step: %s", err)
+	}
+}
+
+func TestUseRecoveryCode(t *testing.T) {
+	repo, user := newTestRepo(t)
+
+	codes, models, err := NewRecoveryCodes(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.ReplaceRecoveryCodes(user, models); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.UseRecoveryCode(user, codes[0]); err != nil {
+		t.Fatalf("first use: %s", err)
+	}
+	if err := repo.UseRecoveryCode(user, codes[0]); err == nil {
+		t.Error("expected the recovery code to be rejected once used")
+	}
+	if err := repo.UseRecoveryCode(user, "not-a-code"); err == nil {
+		t.Error("expected an unknown recovery code to be rejected")
+	}
+	if count := repo.CountRecoveryCodes(user); count != int64(len(codes)-1) {
+		t.Errorf("codes left: got %d", count)
+	}
+}
+
+func TestIndexUsersDeletedInvite(t *testing.T) {
+	repo, admin := newTestRepo(t)
+
+	invite := Invite{Code: "invite", Level: LevelMember, CreatedByID: admin.ID}
+	if err := repo.SaveInvite(&invite); err != nil {
+		t.Fatal(err)
+	}
+
+	user := User{Name: "bob", Level: LevelMember}
+	if err := repo.RegisterWithInvite(&user, &invite); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.DeleteInvite(&invite); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, u := range repo.IndexUsers() {
+		if u.ID != user.ID {
+			continue
+		}
+
+		if u.Invite == nil || u.Invite.Code != "invite" || u.Invite.CreatedBy.Name != admin.Name {
+			t.Errorf("invite: got %+v", u.Invite)
+		}
+		return
+	}
+
+	t.Error("registered user not found")
+}
+
+func TestPruneSessions(t *testing.T) {
+	repo, user := newTestRepo(t)
+	t.Setenv(string(envJwtTTl), "3600")
+
+	sessions := make(map[string]*Session)
+	for name, age := range map[string]time.Duration{
+		"current":    time.Minute,
+		"expired":    2 * time.Hour,
+		"logged out": time.Minute,
+	} {
+		session := &Session{JwtID: name, LastSeen: time.Now().Add(-age), UserID: user.ID}
+		session.CreatedAt = session.LastSeen
+		if err := repo.SaveSession(session); err != nil {
+			t.Fatal(err)
+		}
+
+		sessions[name] = session
+	}
+
+	if err := repo.DeleteSession(sessions["logged out"]); err != nil {
+		t.Fatal(err)
+	}
+
+	pruned, err := PruneSessions(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pruned != 2 {
+		t.Errorf("pruned: got %d, want 2", pruned)
+	}
+
+	var left []string
+	for _, session := range repo.IndexSessionsForUser(user) {
+		left = append(left, session.JwtID)
+	}
+	if !slices.Equal(left, []string{"current"}) {
+		t.Errorf("sessions left: got %v", left)
+	}
+}
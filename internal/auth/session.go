@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/indeedhat/barista/internal/server"
+)
+
+// sessionTouchInterval is the minimum time between LastSeen updates on a session, without it every
+// request would write to the database
+const sessionTouchInterval = time.Minute
+
+// sessionPruneInterval is the time between runs of the expired session cleanup
+const sessionPruneInterval = time.Hour
+
+// NewSession creates a session record for the user logging in via the given request
+//
+// The session is not persisted, that is left to the caller
+func NewSession(r *http.Request, user *User) (*Session, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, errors.New("failed to generate session id")
+	}
+
+	return &Session{
+		JwtID:     hex.EncodeToString(id),
+		UserAgent: r.UserAgent(),
+		IP:        server.ClientIP(r),
+		LastSeen:  time.Now(),
+		UserID:    user.ID,
+	}, nil
+}
+
+// touchSession updates the last seen details of the session if it has not been done recently
+func touchSession(r *http.Request, session *Session, repo Repository) {
+	if time.Since(session.LastSeen) < sessionTouchInterval {
+		return
+	}
+
+	session.LastSeen = time.Now()
+	session.IP = server.ClientIP(r)
+	session.UserAgent = r.UserAgent()
+
+	_ = repo.SaveSession(session)
+}
+
+// sessionTtl is how long a session lasts from login, it matches the expiry of the jwt issued with it
+func sessionTtl() time.Duration {
+	return time.Duration(envJwtTTl.Get()) * time.Second
+}
+
+// PruneSessions removes sessions that can no longer be used, either because the jwt they were issued
+// with has expired or because they have been logged out
+func PruneSessions(repo Repository) (int64, error) {
+	return repo.DeleteExpiredSessions(time.Now().Add(-sessionTtl()))
+}
+
+// ScheduleSessionPrune runs PruneSessions every sessionPruneInterval until the context is cancelled
+func ScheduleSessionPrune(ctx context.Context, repo Repository) {
+	ticker := time.NewTicker(sessionPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := PruneSessions(repo); err != nil {
+				log.Printf("session prune failed: %s", err)
+			}
+		}
+	}
+}
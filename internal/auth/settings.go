@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+var (
+	settingsMux sync.RWMutex
+	settings    = Settings{RegisterMode: RegisterInvite}
+)
+
+// LoadSettings loads the instance settings into memory
+//
+// Instances that were set up before settings were stored in the database will have their settings
+// created from the env
+func LoadSettings(repo Repository) error {
+	loaded, err := repo.FindSettings()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if repo.CountUsers() == 0 {
+			// settings will be created by the setup wizard
+			return nil
+		}
+
+		loaded = &Settings{
+			RegisterMode:     RegisterInvite,
+			MembersCanInvite: envMembersCanInvite.Get(),
+			RequireAdminTotp: ptr(EnvRequireAdminTotp.Get()),
+		}
+		if envEnableRegister.Get() {
+			loaded.RegisterMode = RegisterOpen
+		}
+
+		err = repo.SaveSettings(loaded)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if loaded.RequireAdminTotp == nil {
+		loaded.RequireAdminTotp = ptr(EnvRequireAdminTotp.Get())
+		if err := repo.SaveSettings(loaded); err != nil {
+			return err
+		}
+	}
+
+	if loaded.JwtSecret == "" && NeedsJwtSecret() {
+		if loaded.JwtSecret, err = NewJwtSecret(); err != nil {
+			return err
+		}
+
+		if err := repo.SaveSettings(loaded); err != nil {
+			return err
+		}
+	}
+
+	SetSettings(*loaded)
+
+	return nil
+}
+
+// CurrentSettings returns a copy of the in memory instance settings
+func CurrentSettings() Settings {
+	settingsMux.RLock()
+	defer settingsMux.RUnlock()
+
+	return settings
+}
+
+// SetSettings replaces the in memory instance settings, it should be called after the settings have
+// been saved
+func SetSettings(s Settings) {
+	settingsMux.Lock()
+	defer settingsMux.Unlock()
+
+	settings = s
+}
+
+// NeedsJwtSecret checks if a jwt secret needs to be generated during setup
+func NeedsJwtSecret() bool {
+	return envJwtSecret.Get() == ""
+}
+
+// NewJwtSecret generates a random secret for signing jwts
+func NewJwtSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", errors.New("failed to generate jwt secret")
+	}
+
+	return hex.EncodeToString(secret), nil
+}
+
+// jwtSecret returns the key used to sign jwts
+//
+// JWT_SECRET takes priority over the secret generated during setup
+func jwtSecret() []byte {
+	if secret := envJwtSecret.Get(); secret != "" {
+		return []byte(secret)
+	}
+
+	return []byte(CurrentSettings().JwtSecret)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
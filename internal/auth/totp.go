@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	totpIssuer = "Barista"
+	totpDigits = 6
+	totpPeriod = 30
+	// number of periods either side of the current one that will still be accepted to allow for
+	// clock drift between the server and the authenticator app
+	totpSkew = 1
+
+	recoveryCodeCount = 10
+
+	// totpMaxFailures is the number of wrong codes that can be entered during login before 2fa
+	// logins are locked for totpLockout
+	totpMaxFailures = 5
+	totpLockout     = 15 * time.Minute
+)
+
+// ErrTotpLocked is returned when a 2fa login is started while the user is locked out after too many
+// wrong codes
+var ErrTotpLocked = errors.New("Too many incorrect codes, please try again later")
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewTotpSecret generates a new random base32 encoded secret for use with an authenticator app
+func NewTotpSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", errors.New("failed to generate totp secret")
+	}
+
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// TotpURI generates the otpauth:// uri that authenticator apps use to enrol a secret
+func TotpURI(name, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", totpIssuer)
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+
+	return fmt.Sprintf(
+		"otpauth://totp/%s:%s?%s",
+		url.PathEscape(totpIssuer),
+		url.PathEscape(name),
+		params.Encode(),
+	)
+}
+
+// TotpQRCode generates a png data uri for the QR code of the given otpauth:// uri
+func TotpQRCode(uri string) (string, error) {
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		return "", errors.New("failed to generate qr code")
+	}
+
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(png), nil
+}
+
+// VerifyTotp checks the given code against the users secret
+//
+// On success the time step the code was valid for is returned, codes for a step at or before the
+// users TotpLastStep are rejected so that each code can only be used once
+func VerifyTotp(user *User, secret, code string) (int64, bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	now := time.Now().Unix() / totpPeriod
+	for step := now - totpSkew; step <= now+totpSkew; step++ {
+		if step <= user.TotpLastStep {
+			continue
+		}
+
+		if hmac.Equal([]byte(totpCode(key, step)), []byte(code)) {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// totpCode generates the code for a single time step as per RFC 6238
+func totpCode(key []byte, step int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%uint32(math.Pow10(totpDigits)))
+}
+
+// NewRecoveryCodes generates a fresh set of single use recovery codes
+//
+// The plain text codes are returned for displaying to the user once, the RecoveryCode models only
+// contain the hash
+func NewRecoveryCodes(user *User) ([]string, []RecoveryCode, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	models := make([]RecoveryCode, 0, recoveryCodeCount)
+
+	for range recoveryCodeCount {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, errors.New("failed to generate recovery codes")
+		}
+
+		code := strings.ToLower(totpEncoding.EncodeToString(raw))
+		code = code[:4] + "-" + code[4:]
+
+		codes = append(codes, code)
+		models = append(models, RecoveryCode{
+			UserID: user.ID,
+			Hash:   hashRecoveryCode(code),
+		})
+	}
+
+	return codes, models, nil
+}
+
+// hashRecoveryCode hashes a recovery code for storage
+//
+// recovery codes are random and high entropy so unlike passwords they do not need a slow hash
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	sum := sha256.Sum256([]byte(code))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,26 @@
+package auth
+
+import "testing"
+
+func TestTotpCode(t *testing.T) {
+	// test vectors from RFC 6238 appendix B for SHA1, the codes are truncated to the last totpDigits
+	// digits of the 8 digit values in the RFC
+	key := []byte("12345678901234567890")
+
+	for _, tc := range []struct {
+		time int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	} {
+		want := tc.code[len(tc.code)-totpDigits:]
+		if got := totpCode(key, tc.time/totpPeriod); got != want {
+			t.Errorf("%d: got %s, want %s", tc.time, got, want)
+		}
+	}
+}
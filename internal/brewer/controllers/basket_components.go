@@ -22,7 +22,7 @@ func (c Controller) BasketsSelect(rw http.ResponseWriter, r *http.Request) {
 		ui.Toast(rw, ui.Warning, "Brewer not found")
 		return
 	}
-	if brewer.Type != "Espresso" {
+	if !brewer.IsEspresso() {
 		return
 	}
 
@@ -33,5 +33,5 @@ func (c Controller) BasketsSelect(rw http.ResponseWriter, r *http.Request) {
 		ui.RenderComponent(rw, comData)
 	}()
 
-	comData["Baskets"] = brewer.Baskets
+	comData["Baskets"] = brewer.CompatibleBaskets()
 }
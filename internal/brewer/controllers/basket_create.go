@@ -25,6 +25,8 @@ func (c Controller) NewBasket(rw http.ResponseWriter, r *http.Request) {
 }
 
 type createBasketRequest struct {
+	basketSpecsRequest
+
 	Name  string  `json:"name" validate:"required"`
 	Brand string  `json:"brand" validate:"required"`
 	Dose  float64 `json:"dose" validate:"required"`
@@ -57,12 +59,19 @@ func (c Controller) CreateBasket(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if errs := req.validateSpecs(req.Dose, brewerModel); len(errs) > 0 {
+		comData.SetFieldErrors(errs)
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
 	basket := brewer.Basket{
 		Name:   req.Name,
 		Brand:  req.Brand,
 		Dose:   req.Dose,
 		Brewer: *brewerModel,
 	}
+	req.applySpecs(&basket)
 	brewerModel.AddBasket(basket)
 
 	if err := c.repo.SaveBrewer(brewerModel); err != nil {
@@ -70,7 +79,7 @@ func (c Controller) CreateBasket(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	comData["Basket"] = basket
+	comData["Basket"] = brewerModel.Baskets[len(brewerModel.Baskets)-1]
 	comData["edit"] = false
 	comData.SetForm(createBasketRequest{})
 
@@ -0,0 +1,55 @@
+package brewer_controllers
+
+import (
+	"fmt"
+
+	"github.com/indeedhat/barista/internal/brewer"
+)
+
+// basketSpecsRequest holds the basket specs shared by the create and update basket requests
+type basketSpecsRequest struct {
+	DoseMin     float64 `json:"dose_min" validate:"min=0"`
+	DoseMax     float64 `json:"dose_max" validate:"min=0"`
+	HolePattern string  `json:"hole_pattern" validate:"max=100"`
+	Precision   bool    `json:"precision"`
+	Headspace   float64 `json:"headspace" validate:"min=0"`
+	Diameter    float64 `json:"diameter" validate:"min=0"`
+}
+
+// validateSpecs checks that the dose range makes sense for the nominal dose and that the basket fits
+// the brewers portafilter
+func (s basketSpecsRequest) validateSpecs(dose float64, brewerModel *brewer.Brewer) map[string][]string {
+	errs := make(map[string][]string)
+
+	if s.DoseMin > 0 && s.DoseMax > 0 && s.DoseMin > s.DoseMax {
+		errs["dose_max"] = append(errs["dose_max"], "Max dose must not be less than the min dose")
+	} else if !s.basket().FitsDose(dose) {
+		errs["dose"] = append(errs["dose"], "Dose must be within the dose range")
+	}
+
+	if !brewerModel.Fits(s.basket()) {
+		errs["diameter"] = append(
+			errs["diameter"],
+			fmt.Sprintf("Basket does not fit the brewers %gmm portafilter", brewerModel.PortafilterSize),
+		)
+	}
+
+	return errs
+}
+
+// applySpecs sets the specs on the basket
+func (s basketSpecsRequest) applySpecs(basket *brewer.Basket) {
+	basket.DoseMin = s.DoseMin
+	basket.DoseMax = s.DoseMax
+	basket.HolePattern = s.HolePattern
+	basket.Precision = s.Precision
+	basket.Headspace = s.Headspace
+	basket.Diameter = s.Diameter
+}
+
+func (s basketSpecsRequest) basket() brewer.Basket {
+	var basket brewer.Basket
+	s.applySpecs(&basket)
+
+	return basket
+}
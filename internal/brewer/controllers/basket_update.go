@@ -9,6 +9,8 @@ import (
 )
 
 type updateBasketRequest struct {
+	basketSpecsRequest
+
 	Name  string  `json:"name" validate:"required"`
 	Brand string  `json:"brand" validate:"required"`
 	Dose  float64 `json:"dose" validate:"required"`
@@ -54,9 +56,16 @@ func (c Controller) UpdateBasket(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if errs := req.validateSpecs(req.Dose, brewer); len(errs) > 0 {
+		comData.SetFieldErrors(errs)
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
 	basket.Name = req.Name
 	basket.Brand = req.Brand
 	basket.Dose = req.Dose
+	req.applySpecs(basket)
 
 	brewer.AddBasket(*basket)
 
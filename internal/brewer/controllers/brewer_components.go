@@ -2,17 +2,27 @@ package brewer_controllers
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/indeedhat/barista/internal/auth"
 	"github.com/indeedhat/barista/internal/types"
 	"github.com/indeedhat/barista/internal/ui"
 )
 
+// BrewersSelect lists the users brewers that can make the drink, if the drink does not call for
+// any particular type of brewer then all of them are listed
 func (c Controller) BrewersSelect(rw http.ResponseWriter, r *http.Request) {
-	drink := r.URL.Query().Get("drink")
 	user := r.Context().Value("user").(*auth.User)
+
+	var brewerTypes []types.BrewerType
+	if drinkId, err := strconv.Atoi(r.URL.Query().Get("drink.int")); err == nil {
+		if drink, err := c.typeRepo.FindDrinkType(uint(drinkId), user.ID); err == nil {
+			brewerTypes = drink.Brewers
+		}
+	}
+
 	comData := ui.NewComponentData("brewers-select", ui.ComponentData{
-		"Brewers": c.repo.IndexBrewersForUser(user, types.DrinkType(drink).Brewers()...),
+		"Brewers": c.repo.IndexBrewersForUser(user, brewerTypes...),
 		"value":   r.URL.Query().Get("value"),
 	})
 
@@ -6,7 +6,6 @@ import (
 	"github.com/indeedhat/barista/internal/auth"
 	"github.com/indeedhat/barista/internal/brewer"
 	"github.com/indeedhat/barista/internal/server"
-	"github.com/indeedhat/barista/internal/types"
 	"github.com/indeedhat/barista/internal/ui"
 )
 
@@ -14,18 +13,23 @@ type createBrewerRequest struct {
 	Name        string `json:"name" validate:"required"`
 	Brand       string `json:"brand" validate:"required"`
 	ModelNumber string `json:"model" validate:"required"`
-	Type        string `json:"type" validate:"required"`
+	Type        uint   `json:"type" validate:"required"`
 }
 
 type createBrewerData struct {
 	ui.PageData
-	Brewers []brewer.Brewer
-	Open    bool
+	Brewers     []brewer.Brewer
+	BrewerTypes ui.ComponentData
+	Open        bool
 }
 
 func (c Controller) CreateBrewer(rw http.ResponseWriter, r *http.Request) {
 	user := r.Context().Value("user").(*auth.User)
-	pageData := createBrewerData{PageData: ui.NewPageData("Brewers", "brewers", user)}
+	pageData := createBrewerData{
+		PageData:    ui.NewPageData("Brewers", "brewers", user),
+		Brewers:     c.repo.IndexBrewersForUser(user),
+		BrewerTypes: c.newBrewerTypesData(user),
+	}
 	pageData.Open = true
 	defer func() {
 		ui.RenderUser(rw, r, pageData)
@@ -42,11 +46,18 @@ func (c Controller) CreateBrewer(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	brewerType, err := c.typeRepo.FindBrewerType(req.Type, user.ID)
+	if err != nil {
+		pageData.SetFieldErrors(map[string][]string{"type": {"Brewer type not found"}})
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
 	brewer := brewer.Brewer{
 		Name:        req.Name,
 		Brand:       req.Brand,
 		ModelNumber: req.ModelNumber,
-		Type:        types.BrewerType(req.Type),
+		TypeID:      brewerType.ID,
 		User:        *user,
 	}
 
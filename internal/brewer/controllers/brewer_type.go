@@ -0,0 +1,98 @@
+package brewer_controllers
+
+import (
+	"net/http"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/types"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+type createBrewerTypeRequest struct {
+	Name     string `json:"name" validate:"required,max=50"`
+	Espresso bool   `json:"espresso"`
+	Filter   bool   `json:"filter"`
+}
+
+// newBrewerTypesData builds the data for the brewer-types component
+func (c Controller) newBrewerTypesData(user *auth.User) ui.ComponentData {
+	return ui.ComponentData{
+		"Types": c.typeRepo.IndexBrewerTypes(user.ID),
+		"Form":  createBrewerTypeRequest{},
+	}
+}
+
+func (c Controller) CreateBrewerType(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	comData := ui.NewComponentData("brewer-types", c.newBrewerTypesData(user))
+	defer func() {
+		ui.RenderComponent(rw, comData)
+	}()
+
+	var req createBrewerTypeRequest
+	if err := server.UnmarshalBody(r, &req, &comData); err != nil {
+		comData["Open"] = true
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	if err := server.ValidateRequest(req, &comData); err != nil {
+		comData["Open"] = true
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	fieldErrors := make(map[string][]string)
+	if _, err := c.typeRepo.FindBrewerTypeByName(req.Name, user.ID); err == nil {
+		fieldErrors["name"] = append(fieldErrors["name"], "A brewer type with this name already exists")
+	}
+	if req.Espresso && req.Filter {
+		fieldErrors["filter"] = append(fieldErrors["filter"], "A brewer cannot be both an espresso machine and a filter brewer")
+	}
+	if len(fieldErrors) > 0 {
+		comData["Open"] = true
+		comData.SetFieldErrors(fieldErrors)
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	brewerType := types.BrewerType{
+		Name:     req.Name,
+		Espresso: req.Espresso,
+		Filter:   req.Filter,
+		UserID:   &user.ID,
+	}
+
+	if err := c.typeRepo.SaveBrewerType(&brewerType); err != nil {
+		comData["Open"] = true
+		ui.Toast(rw, ui.Warning, "Failed to create brewer type")
+		return
+	}
+
+	comData = ui.NewComponentData("brewer-types", c.newBrewerTypesData(user))
+	ui.Toast(rw, ui.Success, "Brewer type created")
+}
+
+// DeleteBrewerType removes one of the users own brewer types, the built in types cannot be removed
+func (c Controller) DeleteBrewerType(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	id, _ := server.PathID(r)
+	brewerType, err := c.typeRepo.FindBrewerType(id, user.ID)
+	if err != nil || brewerType.BuiltIn() {
+		ui.Toast(rw, ui.Warning, "Brewer type not found")
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if c.repo.CountBrewersOfType(brewerType.ID) > 0 {
+		ui.Toast(rw, ui.Warning, "Brewer type cannot be deleted while brewers still use it")
+	} else if err := c.typeRepo.DeleteBrewerType(brewerType); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to delete brewer type")
+	} else {
+		ui.Toast(rw, ui.Success, "Brewer type deleted")
+	}
+
+	ui.RenderComponent(rw, ui.NewComponentData("brewer-types", c.newBrewerTypesData(user)))
+}
@@ -6,7 +6,9 @@ import (
 
 	"github.com/indeedhat/barista/internal/auth"
 	"github.com/indeedhat/barista/internal/brewer"
+	"github.com/indeedhat/barista/internal/images"
 	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/storage"
 	"github.com/indeedhat/barista/internal/ui"
 )
 
@@ -14,6 +16,9 @@ type updateBrewerRequest struct {
 	Name        string `json:"name" validate:"required"`
 	Brand       string `json:"brand" validate:"required"`
 	ModelNumber string `json:"model" validate:"required"`
+	Public      bool   `json:"public"`
+	// PortafilterSize is only sent for espresso machines
+	PortafilterSize float64 `json:"portafilter_size" validate:"min=0,max=100"`
 }
 
 type updateBrewerData struct {
@@ -57,6 +62,8 @@ func (c Controller) UpdateBrewer(rw http.ResponseWriter, r *http.Request) {
 	brewer.Name = req.Name
 	brewer.Brand = req.Brand
 	brewer.ModelNumber = req.ModelNumber
+	brewer.Public = req.Public
+	brewer.PortafilterSize = req.PortafilterSize
 
 	if err := c.repo.SaveBrewer(brewer); err != nil {
 		ui.Toast(rw, ui.Warning, "Failed to update brewer")
@@ -89,17 +96,17 @@ func (c Controller) UpdateBrewerImage(rw http.ResponseWriter, r *http.Request) {
 	pageData.Title = brewer.Name
 	pageData.Brewer = brewer
 
-	savePath, err := server.UploadFile(r, "image", fmt.Sprint(BrewerImagePath, brewer.ID), &server.UploadProps{
+	key, err := server.UploadImage(r, "image", fmt.Sprint(BrewerImagePath, brewer.ID), &server.UploadProps{
 		Ext:  []string{".jpg", ".jpeg", ".png"},
 		Mime: []string{"image/png", "image/jpeg"},
-	})
+	}, images.DefaultVariants)
 	if err != nil {
 		ui.Toast(rw, ui.Warning, "Failed to upload image")
 		return
 	}
 
-	if savePath != "" {
-		brewer.Icon = savePath[5:]
+	if key != "" {
+		brewer.Icon = storage.ModelPath(key)
 		if err := c.repo.SaveBrewer(brewer); err != nil {
 			ui.Toast(rw, ui.Warning, "Failed to save image")
 			return
@@ -12,7 +12,11 @@ import (
 func (c Controller) ViewBrewers(rw http.ResponseWriter, r *http.Request) {
 	user := r.Context().Value("user").(*auth.User)
 
-	pageData := ui.NewPageData("Brewers", "brewers", user)
+	pageData := createBrewerData{
+		PageData:    ui.NewPageData("Brewers", "brewers", user),
+		Brewers:     c.repo.IndexBrewersForUser(user),
+		BrewerTypes: c.newBrewerTypesData(user),
+	}
 	pageData.Form = createBrewerRequest{}
 
 	ui.RenderUser(rw, r, pageData)
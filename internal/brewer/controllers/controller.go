@@ -9,14 +9,15 @@ import (
 	"github.com/indeedhat/barista/internal/ui"
 )
 
-const BrewerImagePath = "data/uploads/brewer/"
+const BrewerImagePath = "brewer/"
 
 type Controller struct {
-	repo brewer.Repository
+	repo     brewer.Repository
+	typeRepo types.Repository
 }
 
-func New(repo brewer.Repository) Controller {
-	return Controller{repo}
+func New(repo brewer.Repository, typeRepo types.Repository) Controller {
+	return Controller{repo, typeRepo}
 }
 
 func (c Controller) findEspressoBrewer(rw http.ResponseWriter, user *auth.User, id uint) *brewer.Brewer {
@@ -31,7 +32,7 @@ func (c Controller) findEspressoBrewer(rw http.ResponseWriter, user *auth.User,
 		return nil
 	}
 
-	if brewer.Type != types.BrewerEspresso {
+	if !brewer.IsEspresso() {
 		ui.Toast(rw, ui.Warning, "Only espresso machines can have baskets")
 		return nil
 	}
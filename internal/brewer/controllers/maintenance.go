@@ -0,0 +1,251 @@
+package brewer_controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/brewer"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+type createMaintenanceTaskRequest struct {
+	Name          string `json:"name" validate:"required,max=50"`
+	IntervalDays  uint   `json:"interval_days" validate:"max=3650"`
+	IntervalBrews uint   `json:"interval_brews" validate:"max=100000"`
+}
+
+type logMaintenanceRequest struct {
+	DoneAt string `json:"done_at"`
+	Notes  string `json:"notes"`
+}
+
+// newMaintenanceData builds the data for the brewer-maintenance component
+func newMaintenanceData(brewer *brewer.Brewer) ui.ComponentData {
+	return ui.ComponentData{
+		"Brewer": brewer,
+		"Today":  time.Now().Format(time.DateOnly),
+		"Form":   createMaintenanceTaskRequest{},
+	}
+}
+
+func (c Controller) ViewMaintenance(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	id, _ := server.PathID(r)
+	brewerModel, err := c.repo.FindBrewer(id, user.ID)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Brewer not found")
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	ui.RenderComponent(rw, ui.NewComponentData("brewer-maintenance", newMaintenanceData(brewerModel)))
+}
+
+func (c Controller) CreateMaintenanceTask(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	id, _ := server.PathID(r)
+	brewerModel, err := c.repo.FindBrewer(id, user.ID)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Brewer not found")
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	comData := ui.NewComponentData("brewer-maintenance", newMaintenanceData(brewerModel))
+	defer func() {
+		ui.RenderComponent(rw, comData)
+	}()
+
+	var req createMaintenanceTaskRequest
+	if err := server.UnmarshalBody(r, &req, &comData); err != nil {
+		comData["Open"] = true
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	if err := server.ValidateRequest(req, &comData); err != nil {
+		comData["Open"] = true
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	if req.IntervalDays == 0 && req.IntervalBrews == 0 {
+		comData["Open"] = true
+		comData.SetFieldErrors(map[string][]string{
+			"interval_days": {"The task must be scheduled by days, brews or both"},
+		})
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	task := brewer.MaintenanceTask{
+		Name:          req.Name,
+		IntervalDays:  req.IntervalDays,
+		IntervalBrews: req.IntervalBrews,
+		BrewerID:      brewerModel.ID,
+	}
+
+	if err := c.repo.SaveMaintenanceTask(&task); err != nil {
+		comData["Open"] = true
+		ui.Toast(rw, ui.Warning, "Failed to create maintenance task")
+		return
+	}
+
+	comData = c.reloadMaintenanceData(brewerModel)
+	ui.Toast(rw, ui.Success, "Maintenance task created")
+}
+
+func (c Controller) DeleteMaintenanceTask(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	brewerModel, task := c.findMaintenanceTask(rw, r, user)
+	if task == nil {
+		return
+	}
+
+	if err := c.repo.DeleteMaintenanceTask(task); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to delete maintenance task")
+	} else {
+		ui.Toast(rw, ui.Success, "Maintenance task deleted")
+	}
+
+	ui.RenderComponent(rw, c.reloadMaintenanceData(brewerModel))
+}
+
+// LogBrew records a use of the brewer that was not made from a recipe
+func (c Controller) LogBrew(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	id, _ := server.PathID(r)
+	brewerModel, err := c.repo.FindBrewer(id, user.ID)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Brewer not found")
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	brew := brewer.Brew{
+		BrewedAt: time.Now(),
+		BrewerID: brewerModel.ID,
+	}
+
+	if err := c.repo.SaveBrew(&brew); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to log brew")
+	} else {
+		ui.Toast(rw, ui.Success, "Brew logged")
+	}
+
+	ui.RenderComponent(rw, c.reloadMaintenanceData(brewerModel))
+}
+
+// LogMaintenance records that a maintenance task has been done, this resets its schedule
+func (c Controller) LogMaintenance(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	brewerModel, task := c.findMaintenanceTask(rw, r, user)
+	if task == nil {
+		return
+	}
+
+	comData := ui.NewComponentData("brewer-maintenance", newMaintenanceData(brewerModel))
+	defer func() {
+		ui.RenderComponent(rw, comData)
+	}()
+
+	var req logMaintenanceRequest
+	if err := server.UnmarshalBody(r, &req); err != nil {
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	doneAt := time.Now()
+	if req.DoneAt != "" && req.DoneAt != doneAt.Format(time.DateOnly) {
+		date, err := time.ParseInLocation(time.DateOnly, req.DoneAt, time.Local)
+		if err != nil || date.After(doneAt) {
+			ui.Toast(rw, ui.Warning, "Invalid date")
+			return
+		}
+		doneAt = date
+	}
+
+	log := brewer.MaintenanceLog{
+		DoneAt: doneAt,
+		Notes:  req.Notes,
+		TaskID: task.ID,
+	}
+
+	if err := c.repo.SaveMaintenanceLog(&log); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to log maintenance")
+		return
+	}
+
+	comData = c.reloadMaintenanceData(brewerModel)
+	ui.Toast(rw, ui.Success, task.Name+" logged")
+}
+
+func (c Controller) DeleteMaintenanceLog(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	brewerModel, task := c.findMaintenanceTask(rw, r, user)
+	if task == nil {
+		return
+	}
+
+	logId, _ := server.PathID(r, "log_id")
+	log := task.Log(logId)
+	if log == nil {
+		ui.Toast(rw, ui.Warning, "Log entry not found")
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if err := c.repo.DeleteMaintenanceLog(log); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to delete log entry")
+	} else {
+		ui.Toast(rw, ui.Success, "Log entry deleted")
+	}
+
+	ui.RenderComponent(rw, c.reloadMaintenanceData(brewerModel))
+}
+
+// findMaintenanceTask looks up the task from the request path, making sure it belongs to one of the
+// users brewers
+//
+// If the task cannot be found the response is written and nil is returned
+func (c Controller) findMaintenanceTask(
+	rw http.ResponseWriter,
+	r *http.Request,
+	user *auth.User,
+) (*brewer.Brewer, *brewer.MaintenanceTask) {
+	brewerId, _ := server.PathID(r, "brewer_id")
+	taskId, _ := server.PathID(r, "task_id")
+
+	brewerModel, err := c.repo.FindBrewer(brewerId, user.ID)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Brewer not found")
+		rw.WriteHeader(http.StatusNotFound)
+		return nil, nil
+	}
+
+	task := brewerModel.MaintenanceTask(taskId)
+	if task == nil {
+		ui.Toast(rw, ui.Warning, "Maintenance task not found")
+		rw.WriteHeader(http.StatusNotFound)
+		return nil, nil
+	}
+
+	return brewerModel, task
+}
+
+// reloadMaintenanceData fetches the brewer again so the component reflects the latest changes
+func (c Controller) reloadMaintenanceData(brewerModel *brewer.Brewer) ui.ComponentData {
+	if reloaded, err := c.repo.FindBrewer(brewerModel.ID); err == nil {
+		brewerModel = reloaded
+	}
+
+	return ui.NewComponentData("brewer-maintenance", newMaintenanceData(brewerModel))
+}
@@ -0,0 +1,138 @@
+package brewer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/indeedhat/barista/internal/database/model"
+)
+
+// MaintenanceTask is a bit of upkeep that needs doing on a brewer on a regular schedule, such as
+// descaling or back flushing
+//
+// A task can be scheduled by elapsed time, by the number of brews or by both in which case it is due
+// at whichever comes first
+type MaintenanceTask struct {
+	model.SoftDelete
+
+	Name string
+	// IntervalDays is the number of days between doing the task, 0 if it is not scheduled by time
+	IntervalDays uint
+	// IntervalBrews is the number of brews between doing the task, 0 if it is not scheduled by brews
+	IntervalBrews uint
+
+	BrewerID uint `gorm:"index"`
+
+	Logs []MaintenanceLog `gorm:"foreignKey:TaskID"`
+
+	// BrewsSinceDone is the number of brews logged against the brewer since the task was last done,
+	// this is counted by the repository when the task is loaded
+	BrewsSinceDone uint `gorm:"-"`
+}
+
+// LastDone is the time the task was last done
+//
+// If it has never been done then the time the task was added is used instead
+func (t MaintenanceTask) LastDone() time.Time {
+	lastDone := t.CreatedAt
+	for i, log := range t.Logs {
+		if i == 0 || log.DoneAt.After(lastDone) {
+			lastDone = log.DoneAt
+		}
+	}
+
+	return lastDone
+}
+
+// DueAt is the time that the task is next due, this is the zero time if the task is not scheduled by
+// time
+func (t MaintenanceTask) DueAt() time.Time {
+	if t.IntervalDays == 0 {
+		return time.Time{}
+	}
+
+	return t.LastDone().AddDate(0, 0, int(t.IntervalDays))
+}
+
+// BrewsUntilDue is the number of brews left before the task is due, this will be negative once the
+// task is overdue
+func (t MaintenanceTask) BrewsUntilDue() int {
+	return int(t.IntervalBrews) - int(t.BrewsSinceDone)
+}
+
+// Overdue checks if either the time or the brews since the task was last done have passed its
+// interval
+func (t MaintenanceTask) Overdue() bool {
+	if t.IntervalDays > 0 && !time.Now().Before(t.DueAt()) {
+		return true
+	}
+
+	return t.IntervalBrews > 0 && t.BrewsUntilDue() <= 0
+}
+
+// Schedule describes how often the task needs doing
+func (t MaintenanceTask) Schedule() string {
+	var parts []string
+
+	if t.IntervalDays > 0 {
+		switch {
+		case t.IntervalDays%365 == 0:
+			parts = append(parts, plural(t.IntervalDays/365, "year"))
+		case t.IntervalDays%7 == 0:
+			parts = append(parts, plural(t.IntervalDays/7, "week"))
+		default:
+			parts = append(parts, plural(t.IntervalDays, "day"))
+		}
+	}
+
+	if t.IntervalBrews > 0 {
+		parts = append(parts, plural(t.IntervalBrews, "brew"))
+	}
+
+	return "Every " + strings.Join(parts, " or ")
+}
+
+// Log finds one of the log entries for the task
+func (t MaintenanceTask) Log(id uint) *MaintenanceLog {
+	for _, log := range t.Logs {
+		if log.ID == id {
+			return &log
+		}
+	}
+
+	return nil
+}
+
+// MaintenanceLog records a time that a maintenance task was done
+type MaintenanceLog struct {
+	model.SoftDelete
+
+	DoneAt time.Time
+	Notes  string
+
+	TaskID uint `gorm:"index"`
+}
+
+// Brew records a single use of a brewer, brews are what the brew interval of maintenance tasks count
+//
+// They are logged from the brewed action on recipes, from the brewer page and whenever a shot is
+// imported for a recipe made with the brewer
+type Brew struct {
+	model.SoftDelete
+
+	BrewedAt time.Time
+
+	BrewerID uint `gorm:"index"`
+	// RecipeID is the recipe that was brewed, if any. It is a plain id as the coffee package depends
+	// on this one
+	RecipeID *uint
+}
+
+func plural(n uint, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+
+	return fmt.Sprintf("%d %ss", n, unit)
+}
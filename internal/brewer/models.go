@@ -1,6 +1,8 @@
 package brewer
 
 import (
+	"fmt"
+
 	"github.com/indeedhat/barista/internal/auth"
 	"github.com/indeedhat/barista/internal/database/model"
 	"github.com/indeedhat/barista/internal/types"
@@ -13,12 +15,33 @@ type Brewer struct {
 	Brand       string
 	ModelNumber string
 	Icon        string
-	Type        types.BrewerType
+	Public      bool
+	// PortafilterSize is the diameter of the portafilter in mm, only baskets of the same size fit
+	PortafilterSize float64
+
+	TypeID uint
+	Type   types.BrewerType
 
 	UserID uint
 	User   auth.User
 
-	Baskets []Basket
+	Baskets          []Basket
+	MaintenanceTasks []MaintenanceTask
+}
+
+// UploadOwner implements uploads.Owner.
+func (m Brewer) UploadOwner() (uint, bool) {
+	return m.UserID, m.Public
+}
+
+// UploadFiles implements uploads.Owner.
+func (m Brewer) UploadFiles() []string {
+	return []string{m.Icon}
+}
+
+// IsEspresso checks if the brewer is an espresso machine, only espresso machines have baskets
+func (m Brewer) IsEspresso() bool {
+	return m.Type.IsEspresso()
 }
 
 func (m *Brewer) Basket(id uint) *Basket {
@@ -51,13 +74,96 @@ func (m *Brewer) RemoveBasket(basket Basket) {
 	}
 }
 
+// Fits checks if the basket fits in the brewers portafilter
+//
+// If the size of either is not known then the basket is assumed to fit
+func (m Brewer) Fits(basket Basket) bool {
+	return m.PortafilterSize == 0 || basket.Diameter == 0 || m.PortafilterSize == basket.Diameter
+}
+
+// CompatibleBaskets lists the baskets that fit in the brewers portafilter
+func (m Brewer) CompatibleBaskets() []Basket {
+	var baskets []Basket
+	for _, basket := range m.Baskets {
+		if m.Fits(basket) {
+			baskets = append(baskets, basket)
+		}
+	}
+
+	return baskets
+}
+
+// MaintenanceTask finds one of the brewers maintenance tasks
+func (m Brewer) MaintenanceTask(id uint) *MaintenanceTask {
+	for _, task := range m.MaintenanceTasks {
+		if task.ID == id {
+			return &task
+		}
+	}
+
+	return nil
+}
+
+// OverdueTasks lists the maintenance tasks that are overdue on the brewer
+func (m Brewer) OverdueTasks() []MaintenanceTask {
+	var overdue []MaintenanceTask
+	for _, task := range m.MaintenanceTasks {
+		if task.Overdue() {
+			overdue = append(overdue, task)
+		}
+	}
+
+	return overdue
+}
+
 type Basket struct {
 	model.SoftDelete
 
-	Dose  float64
-	Brand string
-	Name  string
+	// Dose is the nominal dose the basket is sold as, DoseMin and DoseMax are the range of doses
+	// that work in it. All are in grams and the range is 0 where it is not known
+	Dose    float64
+	DoseMin float64
+	DoseMax float64
+	Brand   string
+	Name    string
+
+	HolePattern string
+	// Precision is set for baskets with tightly controlled hole sizes such as VST or IMS baskets
+	Precision bool
+	// Headspace is the space between the top of a tamped puck and the shower screen in mm
+	Headspace float64
+	// Diameter is the size of portafilter the basket fits in mm
+	Diameter float64
 
 	BrewerID uint
 	Brewer   Brewer `gorm:"foreignKey:BrewerID"`
 }
+
+// HasDoseRange checks if either end of the baskets dose range is known
+func (b Basket) HasDoseRange() bool {
+	return b.DoseMin > 0 || b.DoseMax > 0
+}
+
+// FitsDose checks if the dose is within the baskets dose range, either end of the range that is not
+// known is not checked
+func (b Basket) FitsDose(dose float64) bool {
+	if b.DoseMin > 0 && dose < b.DoseMin {
+		return false
+	}
+
+	return b.DoseMax == 0 || dose <= b.DoseMax
+}
+
+// DoseRange describes the range of doses that work in the basket
+func (b Basket) DoseRange() string {
+	switch {
+	case b.DoseMin > 0 && b.DoseMax > 0:
+		return fmt.Sprintf("%g-%gg", b.DoseMin, b.DoseMax)
+	case b.DoseMin > 0:
+		return fmt.Sprintf("%gg or more", b.DoseMin)
+	case b.DoseMax > 0:
+		return fmt.Sprintf("up to %gg", b.DoseMax)
+	default:
+		return ""
+	}
+}
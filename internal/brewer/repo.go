@@ -11,6 +11,14 @@ type Repository interface {
 	FindBrewer(uint, ...uint) (*Brewer, error)
 	SaveBrewer(*Brewer) error
 	DeleteBrewer(*Brewer) error
+	CountBrewersOfType(typeId uint) int64
+	LinkBrewerTypes() error
+
+	SaveMaintenanceTask(*MaintenanceTask) error
+	DeleteMaintenanceTask(*MaintenanceTask) error
+	SaveMaintenanceLog(*MaintenanceLog) error
+	DeleteMaintenanceLog(*MaintenanceLog) error
+	SaveBrew(*Brew) error
 }
 
 type SqliteRepository struct {
@@ -26,15 +34,25 @@ func (r SqliteRepository) IndexBrewersForUser(user *auth.User, types ...types.Br
 	var brewers []Brewer
 
 	tx := r.db.Preload("Baskets").
+		Preload("Type").
+		Scopes(preloadMaintenance).
 		Where("user_id = ?", user.ID).
 		Order("name ASC")
 
 	if len(types) > 0 {
-		tx = tx.Where("type IN ?", types)
+		ids := make([]uint, 0, len(types))
+		for _, brewerType := range types {
+			ids = append(ids, brewerType.ID)
+		}
+		tx = tx.Where("type_id IN ?", ids)
 	}
 
 	tx.Find(&brewers)
 
+	for i := range brewers {
+		r.countBrewsSinceDone(&brewers[i])
+	}
+
 	return brewers
 }
 
@@ -42,7 +60,7 @@ func (r SqliteRepository) IndexBrewersForUser(user *auth.User, types ...types.Br
 func (r SqliteRepository) FindBrewer(id uint, userId ...uint) (*Brewer, error) {
 	var brewer Brewer
 
-	tx := r.db.Preload("Baskets")
+	tx := r.db.Preload("Baskets").Preload("Type").Scopes(preloadMaintenance)
 
 	if len(userId) > 0 {
 		tx = tx.Where("user_id = ?", userId[0])
@@ -52,6 +70,8 @@ func (r SqliteRepository) FindBrewer(id uint, userId ...uint) (*Brewer, error) {
 		return nil, err
 	}
 
+	r.countBrewsSinceDone(&brewer)
+
 	return &brewer, nil
 }
 
@@ -73,8 +93,8 @@ func (r SqliteRepository) SaveBrewer(brewer *Brewer) error {
 	}()
 
 	if brewer.ID != 0 {
-		for _, basket := range brewer.Baskets {
-			if err := tx.Save(&basket).Error; err != nil {
+		for i := range brewer.Baskets {
+			if err = tx.Save(&brewer.Baskets[i]).Error; err != nil {
 				return err
 			}
 		}
@@ -83,8 +103,132 @@ func (r SqliteRepository) SaveBrewer(brewer *Brewer) error {
 		}
 	}
 
-	err = tx.Save(brewer).Error
+	err = tx.Omit("Type", "MaintenanceTasks").Save(brewer).Error
 	return err
 }
 
+// CountBrewersOfType implements Repository.
+func (r SqliteRepository) CountBrewersOfType(typeId uint) int64 {
+	var count int64
+
+	r.db.Model(&Brewer{}).Where("type_id = ?", typeId).Count(&count)
+
+	return count
+}
+
+// LinkBrewerTypes implements Repository.
+//
+// Brewers saved before brewer types were stored in the database only have the name of their type,
+// these are pointed at the matching type. Names that do not match any type are added as a type for
+// the owner of the brewer so nothing is lost
+func (r SqliteRepository) LinkBrewerTypes() error {
+	if !r.db.Migrator().HasColumn(&Brewer{}, "type") {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var unlinked []struct {
+			UserID uint
+			Type   string
+		}
+
+		err := tx.Model(&Brewer{}).
+			Unscoped().
+			Distinct("user_id", "type").
+			Where("type_id IS NULL AND type IS NOT NULL AND type != ''").
+			Scan(&unlinked).
+			Error
+		if err != nil {
+			return err
+		}
+
+		for _, brewer := range unlinked {
+			var brewerType types.BrewerType
+			err := tx.Where("user_id IS NULL OR user_id = ?", brewer.UserID).
+				Where("LOWER(name) = LOWER(?)", brewer.Type).
+				Order("user_id IS NOT NULL").
+				Attrs(types.BrewerType{Name: brewer.Type, UserID: &brewer.UserID}).
+				FirstOrCreate(&brewerType).
+				Error
+			if err != nil {
+				return err
+			}
+
+			err = tx.Model(&Brewer{}).
+				Unscoped().
+				Where("type_id IS NULL AND user_id = ? AND type = ?", brewer.UserID, brewer.Type).
+				UpdateColumn("type_id", brewerType.ID).
+				Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// SaveMaintenanceTask implements Repository.
+func (r SqliteRepository) SaveMaintenanceTask(task *MaintenanceTask) error {
+	return r.db.Omit("Logs").Save(task).Error
+}
+
+// DeleteMaintenanceTask implements Repository.
+//
+// The log of when the task was done is removed along with it
+func (r SqliteRepository) DeleteMaintenanceTask(task *MaintenanceTask) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("task_id = ?", task.ID).Delete(&MaintenanceLog{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(task).Error
+	})
+}
+
+// SaveMaintenanceLog implements Repository.
+func (r SqliteRepository) SaveMaintenanceLog(log *MaintenanceLog) error {
+	return r.db.Save(log).Error
+}
+
+// DeleteMaintenanceLog implements Repository.
+func (r SqliteRepository) DeleteMaintenanceLog(log *MaintenanceLog) error {
+	return r.db.Delete(log).Error
+}
+
+// SaveBrew implements Repository.
+func (r SqliteRepository) SaveBrew(brew *Brew) error {
+	return r.db.Save(brew).Error
+}
+
+// preloadMaintenance loads the brewers maintenance tasks along with the log of when they were done,
+// most recent first
+func preloadMaintenance(tx *gorm.DB) *gorm.DB {
+	return tx.Preload("MaintenanceTasks", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("name ASC")
+	}).Preload("MaintenanceTasks.Logs", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("done_at DESC, id DESC")
+	})
+}
+
+// countBrewsSinceDone fills in the number of brews logged on the brewer since each of its maintenance
+// tasks was last done
+//
+// Times are compared with julianday as they may have been stored with different offsets
+func (r SqliteRepository) countBrewsSinceDone(brewer *Brewer) {
+	for i, task := range brewer.MaintenanceTasks {
+		if task.IntervalBrews == 0 {
+			continue
+		}
+
+		var count int64
+		r.db.Model(&Brew{}).
+			Where("brewer_id = ?", brewer.ID).
+			Where("julianday(brewed_at) > julianday(?)", task.LastDone()).
+			Count(&count)
+
+		brewer.MaintenanceTasks[i].BrewsSinceDone = uint(count)
+	}
+}
+
 var _ Repository = (*SqliteRepository)(nil)
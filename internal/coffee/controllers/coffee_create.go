@@ -10,6 +10,8 @@ import (
 )
 
 type createCoffeeRequest struct {
+	coffeeOriginRequest
+
 	Name     string `json:"name" validate:"required"`
 	Roaster  uint   `json:"roaster" validate:"required"`
 	Roast    uint8  `json:"roast" validate:"required"`    // TODO: validate level
@@ -26,6 +28,7 @@ type createCoffeeData struct {
 	Coffee   coffee.Coffee
 	Coffees  []coffee.Coffee
 	Flavours []coffee.FlavourProfile
+	Filters  viewCoffeesFilters
 	Open     bool
 }
 
@@ -37,6 +40,7 @@ func (c Controller) CreateCoffee(rw http.ResponseWriter, r *http.Request) {
 	pageData.Flavours = c.repo.IndexFlavourProfiles()
 	pageData.Open = true
 	defer func() {
+		pageData.Filters = extractCoffeeFilters(pageData.Coffees)
 		ui.RenderUser(rw, r, pageData)
 	}()
 
@@ -51,6 +55,12 @@ func (c Controller) CreateCoffee(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if errs := req.validateOrigin(); len(errs) > 0 {
+		pageData.SetFieldErrors(errs)
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
 	roaster, err := c.repo.FindRoaster(req.Roaster)
 	if err != nil {
 		ui.Toast(rw, ui.Warning, "Roaster not found")
@@ -76,6 +86,7 @@ func (c Controller) CreateCoffee(rw http.ResponseWriter, r *http.Request) {
 		Notes:    req.Notes,
 		URL:      req.URL,
 	}
+	req.applyOrigin(&coffee)
 
 	if err := c.repo.SaveCoffee(&coffee); err != nil {
 		ui.Toast(rw, ui.Warning, "Failed to create coffee")
@@ -31,6 +31,9 @@ func (c Controller) DeleteCoffee(rw http.ResponseWriter, r *http.Request) {
 	pageData.Coffee = *coffee
 	pageData.Roasters = c.repo.IndexRoastersForUser(user)
 	pageData.Flavours = c.repo.IndexFlavourProfiles()
+	pageData.Cuppings = newCuppingsData(coffee.ID, c.repo.IndexCuppings(coffee.ID))
+	pageData.Drinks = c.typeRepo.IndexDrinkTypes(user.ID)
+	pageData.MilkTypes = c.repo.IndexMilkTypes(user)
 
 	if len(coffee.Recipes) > 0 {
 		ui.Toast(rw, ui.Warning, "Coffee cannot be deleted while it still has recipes")
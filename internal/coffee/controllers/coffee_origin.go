@@ -0,0 +1,114 @@
+package coffee_controllers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/indeedhat/barista/internal/coffee"
+	"github.com/indeedhat/barista/internal/types"
+)
+
+type blendComponentRequest struct {
+	Name       string `json:"name"`
+	Percentage uint8  `json:"percentage"`
+	Country    string `json:"country"`
+	Varietal   string `json:"varietal"`
+	Process    string `json:"process"`
+}
+
+// coffeeOriginRequest holds the origin details shared by the create and update coffee requests
+type coffeeOriginRequest struct {
+	Country     string                  `json:"country"`
+	Region      string                  `json:"region"`
+	Farm        string                  `json:"farm"`
+	Producer    string                  `json:"producer"`
+	Varietals   string                  `json:"varietals"` // comma separated
+	Process     string                  `json:"process"`
+	AltitudeMin uint                    `json:"altitude_min" validate:"max=10000"`
+	AltitudeMax uint                    `json:"altitude_max" validate:"max=10000"`
+	Harvest     string                  `json:"harvest"`
+	Components  []blendComponentRequest `json:"components"`
+}
+
+// validateOrigin runs the checks that cannot be expressed with validation tags
+//
+// Empty blend component rows are dropped before validating
+func (o *coffeeOriginRequest) validateOrigin() map[string][]string {
+	errs := make(map[string][]string)
+
+	if o.Process != "" && !slices.Contains(types.ProcessMethods, types.ProcessMethod(o.Process)) {
+		errs["process"] = append(errs["process"], "Unknown process method")
+	}
+
+	if o.AltitudeMax != 0 && o.AltitudeMax < o.AltitudeMin {
+		errs["altitude_max"] = append(errs["altitude_max"], "Max altitude must be above the min altitude")
+	}
+
+	o.Components = slices.DeleteFunc(o.Components, func(c blendComponentRequest) bool {
+		return strings.TrimSpace(c.Name) == "" && c.Percentage == 0
+	})
+
+	var total uint
+	for _, component := range o.Components {
+		total += uint(component.Percentage)
+	}
+
+	if slices.ContainsFunc(o.Components, func(c blendComponentRequest) bool {
+		return strings.TrimSpace(c.Name) == ""
+	}) {
+		errs["components"] = append(errs["components"], "Every blend component needs a name")
+	}
+
+	if slices.ContainsFunc(o.Components, func(c blendComponentRequest) bool {
+		return c.Process != "" && !slices.Contains(types.ProcessMethods, types.ProcessMethod(c.Process))
+	}) {
+		errs["components"] = append(errs["components"], "Unknown blend component process method")
+	}
+
+	if len(o.Components) > 0 && total != 100 {
+		errs["components"] = append(
+			errs["components"],
+			fmt.Sprintf("Blend components must add up to 100%%, not %d%%", total),
+		)
+	}
+
+	return errs
+}
+
+// applyOrigin copies the origin details from the request onto the coffee
+func (o coffeeOriginRequest) applyOrigin(c *coffee.Coffee) {
+	c.Country = strings.TrimSpace(o.Country)
+	c.Region = strings.TrimSpace(o.Region)
+	c.Farm = strings.TrimSpace(o.Farm)
+	c.Producer = strings.TrimSpace(o.Producer)
+	c.Varietals = splitList(o.Varietals)
+	c.Process = types.ProcessMethod(o.Process)
+	c.AltitudeMin = o.AltitudeMin
+	c.AltitudeMax = o.AltitudeMax
+	c.Harvest = strings.TrimSpace(o.Harvest)
+
+	c.Components = nil
+	for _, component := range o.Components {
+		c.Components = append(c.Components, coffee.BlendComponent{
+			Name:       strings.TrimSpace(component.Name),
+			Percentage: component.Percentage,
+			Country:    strings.TrimSpace(component.Country),
+			Varietal:   strings.TrimSpace(component.Varietal),
+			Process:    types.ProcessMethod(component.Process),
+		})
+	}
+}
+
+// splitList splits a comma separated list, dropping any empty or duplicate entries
+func splitList(value string) coffee.StringList {
+	var list coffee.StringList
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(v)
+		if v != "" && !slices.Contains(list, v) {
+			list = append(list, v)
+		}
+	}
+
+	return list
+}
@@ -6,11 +6,16 @@ import (
 
 	"github.com/indeedhat/barista/internal/auth"
 	"github.com/indeedhat/barista/internal/coffee"
+	"github.com/indeedhat/barista/internal/images"
 	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/storage"
+	"github.com/indeedhat/barista/internal/types"
 	"github.com/indeedhat/barista/internal/ui"
 )
 
 type updateCoffeeRequest struct {
+	coffeeOriginRequest
+
 	Name     string `json:"name" validate:"required"`
 	Roaster  uint   `json:"roaster" validate:"required"`
 	Roast    uint8  `json:"roast" validate:"required"`    // TODO: validate level
@@ -19,15 +24,19 @@ type updateCoffeeRequest struct {
 	Notes    string `json:"notes"`
 	URL      string `json:"url"`
 	Flavours []uint `json:"flavours"`
+	Public   bool   `json:"public"`
 }
 
 type updateCoffeeData struct {
 	ui.PageData
-	Roasters []coffee.Roaster
-	Coffee   coffee.Coffee
-	Coffees  []coffee.Coffee
-	Flavours []coffee.FlavourProfile
-	Open     bool
+	Roasters  []coffee.Roaster
+	Coffee    coffee.Coffee
+	Coffees   []coffee.Coffee
+	Flavours  []coffee.FlavourProfile
+	Cuppings  ui.ComponentData
+	Drinks    []types.DrinkType
+	MilkTypes []coffee.MilkType
+	Open      bool
 }
 
 func (c Controller) UpdateCoffee(rw http.ResponseWriter, r *http.Request) {
@@ -53,6 +62,9 @@ func (c Controller) UpdateCoffee(rw http.ResponseWriter, r *http.Request) {
 	pageData.Coffee = *coffeeModel
 	pageData.Roasters = c.repo.IndexRoastersForUser(user)
 	pageData.Flavours = c.repo.IndexFlavourProfiles()
+	pageData.Cuppings = newCuppingsData(coffeeModel.ID, c.repo.IndexCuppings(coffeeModel.ID))
+	pageData.Drinks = c.typeRepo.IndexDrinkTypes(user.ID)
+	pageData.MilkTypes = c.repo.IndexMilkTypes(user)
 
 	var req updateCoffeeRequest
 	if err := server.UnmarshalBody(r, &req, &pageData); err != nil {
@@ -65,6 +77,12 @@ func (c Controller) UpdateCoffee(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if errs := req.validateOrigin(); len(errs) > 0 {
+		pageData.SetFieldErrors(errs)
+		ui.Toast(rw, ui.Warning, "Failed to update coffee")
+		return
+	}
+
 	roaster, err := c.repo.FindRoaster(req.Roaster)
 	if err != nil {
 		ui.Toast(rw, ui.Warning, "Coffee not found")
@@ -87,6 +105,8 @@ func (c Controller) UpdateCoffee(rw http.ResponseWriter, r *http.Request) {
 	coffeeModel.Rating = req.Rating
 	coffeeModel.Notes = req.Notes
 	coffeeModel.URL = req.URL
+	coffeeModel.Public = req.Public
+	req.applyOrigin(coffeeModel)
 
 	if err := c.repo.SaveCoffee(coffeeModel); err != nil {
 		ui.Toast(rw, ui.Warning, "Failed to update coffee")
@@ -120,18 +140,21 @@ func (c Controller) UpdateCoffeeImage(rw http.ResponseWriter, r *http.Request) {
 	pageData.Coffee = *coffee
 	pageData.Roasters = c.repo.IndexRoastersForUser(user)
 	pageData.Flavours = c.repo.IndexFlavourProfiles()
+	pageData.Cuppings = newCuppingsData(coffee.ID, c.repo.IndexCuppings(coffee.ID))
+	pageData.Drinks = c.typeRepo.IndexDrinkTypes(user.ID)
+	pageData.MilkTypes = c.repo.IndexMilkTypes(user)
 
-	savePath, err := server.UploadFile(r, "image", fmt.Sprint(CoffeeImagePath, coffee.ID), &server.UploadProps{
+	key, err := server.UploadImage(r, "image", fmt.Sprint(CoffeeImagePath, coffee.ID), &server.UploadProps{
 		Ext:  []string{".jpg", ".jpeg", ".png"},
 		Mime: []string{"image/png", "image/jpeg"},
-	})
+	}, images.DefaultVariants)
 	if err != nil {
 		ui.Toast(rw, ui.Warning, "Failed to upload image")
 		return
 	}
 
-	if savePath != "" {
-		coffee.Icon = savePath[5:]
+	if key != "" {
+		coffee.Icon = storage.ModelPath(key)
 		if err := c.repo.SaveCoffee(coffee); err != nil {
 			ui.Toast(rw, ui.Warning, "Failed to save image")
 			return
@@ -2,10 +2,12 @@ package coffee_controllers
 
 import (
 	"net/http"
+	"slices"
 
 	"github.com/indeedhat/barista/internal/auth"
 	"github.com/indeedhat/barista/internal/coffee"
 	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/types"
 	"github.com/indeedhat/barista/internal/ui"
 )
 
@@ -14,9 +16,17 @@ type viewCoffeesData struct {
 	Roasters []coffee.Roaster
 	Coffees  []coffee.Coffee
 	Flavours []coffee.FlavourProfile
+	Filters  viewCoffeesFilters
 	Open     bool
 }
 
+type viewCoffeesFilters struct {
+	Roasters  []string
+	Countries []string
+	Processes []string
+	Varietals []string
+}
+
 func (c Controller) ViewCoffees(rw http.ResponseWriter, r *http.Request) {
 	user := r.Context().Value("user").(*auth.User)
 
@@ -25,16 +35,46 @@ func (c Controller) ViewCoffees(rw http.ResponseWriter, r *http.Request) {
 	pageData.Roasters = c.repo.IndexRoastersForUser(user)
 	pageData.Coffees = c.repo.IndexCoffeesForUser(user)
 	pageData.Flavours = c.repo.IndexFlavourProfiles()
+	pageData.Filters = extractCoffeeFilters(pageData.Coffees)
 
 	ui.RenderUser(rw, r, pageData)
 }
 
+// extractCoffeeFilters builds the sorted set of values for each of the coffees page filters
+func extractCoffeeFilters(coffees []coffee.Coffee) viewCoffeesFilters {
+	var filters viewCoffeesFilters
+	for _, c := range coffees {
+		filters.Roasters = append(filters.Roasters, c.Roaster.Name)
+		filters.Countries = append(filters.Countries, c.Countries()...)
+		filters.Processes = append(filters.Processes, c.Processes()...)
+		filters.Varietals = append(filters.Varietals, c.AllVarietals()...)
+	}
+
+	return viewCoffeesFilters{
+		Roasters:  uniqueSorted(filters.Roasters),
+		Countries: uniqueSorted(filters.Countries),
+		Processes: uniqueSorted(filters.Processes),
+		Varietals: uniqueSorted(filters.Varietals),
+	}
+}
+
+func uniqueSorted(values []string) []string {
+	values = slices.DeleteFunc(values, func(v string) bool { return v == "" })
+	slices.Sort(values)
+
+	return slices.Compact(values)
+}
+
 type viewCoffeeData struct {
 	ui.PageData
 	Coffee   *coffee.Coffee
+	Cuppings ui.ComponentData
 	Roasters []coffee.Roaster
 	Flavours []coffee.FlavourProfile
-	Open     bool
+	// Drinks and MilkTypes are offered on the recipe cards
+	Drinks    []types.DrinkType
+	MilkTypes []coffee.MilkType
+	Open      bool
 }
 
 func (c Controller) ViewCoffee(rw http.ResponseWriter, r *http.Request) {
@@ -56,8 +96,11 @@ func (c Controller) ViewCoffee(rw http.ResponseWriter, r *http.Request) {
 
 	pageData := viewCoffeeData{PageData: ui.NewPageData("Coffee", "coffee", user)}
 	pageData.Coffee = coffee
+	pageData.Cuppings = newCuppingsData(coffee.ID, c.repo.IndexCuppings(coffee.ID))
 	pageData.Roasters = c.repo.IndexRoastersForUser(user)
 	pageData.Flavours = c.repo.IndexFlavourProfiles()
+	pageData.Drinks = c.typeRepo.IndexDrinkTypes(user.ID)
+	pageData.MilkTypes = c.repo.IndexMilkTypes(user)
 	pageData.Form = createCoffeeRequest{
 		Flavours: coffee.FlavourIds(),
 	}
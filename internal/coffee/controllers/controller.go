@@ -3,20 +3,31 @@ package coffee_controllers
 import (
 	"time"
 
+	"github.com/indeedhat/barista/internal/brewer"
 	"github.com/indeedhat/barista/internal/coffee"
+	"github.com/indeedhat/barista/internal/equipment"
+	"github.com/indeedhat/barista/internal/types"
 )
 
 const (
-	CoffeeImagePath  = "data/uploads/coffee/"
-	RoasterImagePath = "data/uploads/roaster/"
+	CoffeeImagePath  = "coffee/"
+	RoasterImagePath = "roaster/"
 )
 
 type Controller struct {
-	repo coffee.Repository
+	repo          coffee.Repository
+	typeRepo      types.Repository
+	equipmentRepo equipment.Repository
+	brewerRepo    brewer.Repository
 }
 
-func New(repo coffee.Repository) Controller {
-	return Controller{repo}
+func New(
+	repo coffee.Repository,
+	typeRepo types.Repository,
+	equipmentRepo equipment.Repository,
+	brewerRepo brewer.Repository,
+) Controller {
+	return Controller{repo, typeRepo, equipmentRepo, brewerRepo}
 }
 
 type createSuccessResponse struct {
@@ -0,0 +1,162 @@
+package coffee_controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/coffee"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+type createCuppingRequest struct {
+	CuppedAt   string  `json:"cupped_at"`
+	Fragrance  float64 `json:"fragrance" validate:"min=6,max=10"`
+	Flavour    float64 `json:"flavour" validate:"min=6,max=10"`
+	Aftertaste float64 `json:"aftertaste" validate:"min=6,max=10"`
+	Acidity    float64 `json:"acidity" validate:"min=6,max=10"`
+	Body       float64 `json:"body" validate:"min=6,max=10"`
+	Balance    float64 `json:"balance" validate:"min=6,max=10"`
+	Uniformity float64 `json:"uniformity" validate:"min=0,max=10"`
+	CleanCup   float64 `json:"clean_cup" validate:"min=0,max=10"`
+	Sweetness  float64 `json:"sweetness" validate:"min=0,max=10"`
+	Overall    float64 `json:"overall" validate:"min=6,max=10"`
+	Taints     uint8   `json:"taints" validate:"max=5"`
+	Faults     uint8   `json:"faults" validate:"max=5"`
+	Notes      string  `json:"notes"`
+}
+
+// newCuppingRequest creates the default form values, every cup is assumed to be uniform, clean and
+// sweet until the user says otherwise
+func newCuppingRequest() createCuppingRequest {
+	return createCuppingRequest{
+		CuppedAt:   time.Now().Format(time.DateOnly),
+		Fragrance:  6,
+		Flavour:    6,
+		Aftertaste: 6,
+		Acidity:    6,
+		Body:       6,
+		Balance:    6,
+		Uniformity: 10,
+		CleanCup:   10,
+		Sweetness:  10,
+		Overall:    6,
+	}
+}
+
+// newCuppingsData builds the data for the coffee-cuppings component
+func newCuppingsData(coffeeId uint, cuppings []coffee.Cupping) ui.ComponentData {
+	return ui.ComponentData{
+		"CoffeeID": coffeeId,
+		"Cuppings": cuppings,
+		"Average":  coffee.AverageCupping(cuppings),
+		"Chart":    coffee.NewRadarChart(cuppings),
+		"Form":     newCuppingRequest(),
+	}
+}
+
+func (c Controller) CreateCupping(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	id, err := server.PathID(r)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Coffee not found")
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	coffeeModel, err := c.repo.FindCoffee(id, user.ID)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Coffee not found")
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	comData := ui.NewComponentData(
+		"coffee-cuppings",
+		newCuppingsData(coffeeModel.ID, c.repo.IndexCuppings(coffeeModel.ID)),
+	)
+	defer func() {
+		ui.RenderComponent(rw, comData)
+	}()
+
+	var req createCuppingRequest
+	if err := server.UnmarshalBody(r, &req, &comData); err != nil {
+		comData["Open"] = true
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	if err := server.ValidateRequest(req, &comData); err != nil {
+		comData["Open"] = true
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	cuppedAt := time.Now()
+	if req.CuppedAt != "" {
+		if cuppedAt, err = time.Parse(time.DateOnly, req.CuppedAt); err != nil {
+			comData["Open"] = true
+			comData.SetFieldErrors(map[string][]string{"cupped_at": {"Invalid date"}})
+			ui.Toast(rw, ui.Warning, "Bad request")
+			return
+		}
+	}
+
+	cupping := coffee.Cupping{
+		CuppedAt:   cuppedAt,
+		Fragrance:  req.Fragrance,
+		Flavour:    req.Flavour,
+		Aftertaste: req.Aftertaste,
+		Acidity:    req.Acidity,
+		Body:       req.Body,
+		Balance:    req.Balance,
+		Uniformity: req.Uniformity,
+		CleanCup:   req.CleanCup,
+		Sweetness:  req.Sweetness,
+		Overall:    req.Overall,
+		Taints:     req.Taints,
+		Faults:     req.Faults,
+		Notes:      req.Notes,
+		CoffeeID:   coffeeModel.ID,
+		UserID:     user.ID,
+	}
+
+	if err := c.repo.SaveCupping(&cupping); err != nil {
+		comData["Open"] = true
+		ui.Toast(rw, ui.Warning, "Failed to save cupping")
+		return
+	}
+
+	comData = ui.NewComponentData(
+		"coffee-cuppings",
+		newCuppingsData(coffeeModel.ID, c.repo.IndexCuppings(coffeeModel.ID)),
+	)
+	ui.Toast(rw, ui.Success, "Cupping saved")
+}
+
+func (c Controller) DeleteCupping(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	coffeeId, _ := server.PathID(r, "coffee_id")
+	cuppingId, _ := server.PathID(r, "cupping_id")
+
+	cupping, err := c.repo.FindCupping(cuppingId, user.ID)
+	if err != nil || cupping.CoffeeID != coffeeId {
+		ui.Toast(rw, ui.Warning, "Cupping not found")
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if err := c.repo.DeleteCupping(cupping); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to delete cupping")
+	} else {
+		ui.Toast(rw, ui.Success, "Cupping deleted")
+	}
+
+	ui.RenderComponent(rw, ui.NewComponentData(
+		"coffee-cuppings",
+		newCuppingsData(coffeeId, c.repo.IndexCuppings(coffeeId)),
+	))
+}
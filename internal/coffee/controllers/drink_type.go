@@ -0,0 +1,123 @@
+package coffee_controllers
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/types"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+type createDrinkTypeRequest struct {
+	Name    string `json:"name" validate:"required,max=50"`
+	Milk    bool   `json:"milk"`
+	Brewers []uint `json:"brewers"`
+}
+
+type viewDrinkTypesData struct {
+	ui.PageData
+	DrinkTypes ui.ComponentData
+}
+
+// newDrinkTypesData builds the data for the drink-types component
+func (c Controller) newDrinkTypesData(user *auth.User) ui.ComponentData {
+	return ui.ComponentData{
+		"Types":   c.typeRepo.IndexDrinkTypes(user.ID),
+		"Brewers": c.typeRepo.IndexBrewerTypes(user.ID),
+		"Form":    createDrinkTypeRequest{},
+	}
+}
+
+func (c Controller) ViewDrinkTypes(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	ui.RenderUser(rw, r, viewDrinkTypesData{
+		PageData:   ui.NewPageData("Drinks", "drinks", user),
+		DrinkTypes: c.newDrinkTypesData(user),
+	})
+}
+
+func (c Controller) CreateDrinkType(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	comData := ui.NewComponentData("drink-types", c.newDrinkTypesData(user))
+	defer func() {
+		ui.RenderComponent(rw, comData)
+	}()
+
+	var req createDrinkTypeRequest
+	if err := server.UnmarshalBody(r, &req, &comData); err != nil {
+		comData["Open"] = true
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	if err := server.ValidateRequest(req, &comData); err != nil {
+		comData["Open"] = true
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	drinkType := types.DrinkType{
+		Name:   req.Name,
+		Milk:   req.Milk,
+		UserID: &user.ID,
+	}
+
+	fieldErrors := make(map[string][]string)
+	if _, err := c.typeRepo.FindDrinkTypeByName(req.Name, user.ID); err == nil {
+		fieldErrors["name"] = append(fieldErrors["name"], "A drink type with this name already exists")
+	}
+	for _, id := range req.Brewers {
+		if slices.Contains(drinkType.BrewerIds(), id) {
+			continue
+		}
+
+		brewerType, err := c.typeRepo.FindBrewerType(id, user.ID)
+		if err != nil {
+			fieldErrors["brewers"] = append(fieldErrors["brewers"], "Brewer type not found")
+			break
+		}
+
+		drinkType.Brewers = append(drinkType.Brewers, *brewerType)
+	}
+	if len(fieldErrors) > 0 {
+		comData["Open"] = true
+		comData.SetFieldErrors(fieldErrors)
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	if err := c.typeRepo.SaveDrinkType(&drinkType); err != nil {
+		comData["Open"] = true
+		ui.Toast(rw, ui.Warning, "Failed to create drink type")
+		return
+	}
+
+	comData = ui.NewComponentData("drink-types", c.newDrinkTypesData(user))
+	ui.Toast(rw, ui.Success, "Drink type created")
+}
+
+// DeleteDrinkType removes one of the users own drink types, the built in types cannot be removed
+func (c Controller) DeleteDrinkType(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	id, _ := server.PathID(r)
+	drinkType, err := c.typeRepo.FindDrinkType(id, user.ID)
+	if err != nil || drinkType.BuiltIn() {
+		ui.Toast(rw, ui.Warning, "Drink type not found")
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if c.repo.CountRecipesOfDrink(drinkType.ID) > 0 {
+		ui.Toast(rw, ui.Warning, "Drink type cannot be deleted while recipes still use it")
+	} else if err := c.typeRepo.DeleteDrinkType(drinkType); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to delete drink type")
+	} else {
+		ui.Toast(rw, ui.Success, "Drink type deleted")
+	}
+
+	ui.RenderComponent(rw, ui.NewComponentData("drink-types", c.newDrinkTypesData(user)))
+}
@@ -0,0 +1,235 @@
+package coffee_controllers
+
+import (
+	"net/http"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/coffee"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+type flavourAdminData struct {
+	ui.PageData
+	Flavours   []coffee.FlavourProfile
+	Usage      map[uint]int
+	Duplicates [][2]coffee.FlavourProfile
+}
+
+func (c Controller) newFlavourAdminData(user *auth.User) flavourAdminData {
+	pageData := flavourAdminData{PageData: ui.NewPageData("Manage Flavours", "flavour-admin", user)}
+	pageData.Form = updateFlavourRequest{}
+	c.loadFlavourAdminData(&pageData)
+
+	return pageData
+}
+
+// loadFlavourAdminData refreshes the flavour lists after they have been modified
+func (c Controller) loadFlavourAdminData(pageData *flavourAdminData) {
+	pageData.Flavours = c.repo.IndexFlavourProfiles()
+	pageData.Usage = c.repo.FlavourUsage()
+	pageData.Duplicates = coffee.FlavourDuplicates(pageData.Flavours)
+}
+
+func (c Controller) ViewFlavourAdmin(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	ui.RenderUser(rw, r, c.newFlavourAdminData(user))
+}
+
+// FlavourAdminForm renders the rename, merge and delete controls for a single flavour
+func (c Controller) FlavourAdminForm(rw http.ResponseWriter, r *http.Request) {
+	id, err := server.PathID(r)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Flavour not found")
+		return
+	}
+
+	flavour, err := c.repo.FindFlavourProfile(id)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Flavour not found")
+		return
+	}
+
+	flavours := c.repo.IndexFlavourProfiles()
+
+	var parent uint
+	if flavour.ParentID != nil {
+		parent = *flavour.ParentID
+	}
+
+	ui.RenderComponent(rw, ui.NewComponentData("flavour-admin-form", ui.ComponentData{
+		"Flavour":     flavour,
+		"Flavours":    flavours,
+		"Parent":      parent,
+		"Coffees":     c.repo.FlavourUsage()[flavour.ID],
+		"HasChildren": hasChildFlavours(flavours, flavour.ID),
+	}))
+}
+
+type updateFlavourRequest struct {
+	Name   string `json:"name" validate:"required"`
+	Parent uint   `json:"parent"`
+}
+
+// UpdateFlavour renames a flavour and/or moves it within the tree
+func (c Controller) UpdateFlavour(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	pageData := c.newFlavourAdminData(user)
+	defer func() {
+		c.loadFlavourAdminData(&pageData)
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	id, err := server.PathID(r)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Flavour not found")
+		return
+	}
+
+	flavour, err := c.repo.FindFlavourProfile(id)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Flavour not found")
+		return
+	}
+
+	var req updateFlavourRequest
+	if err := server.UnmarshalBody(r, &req, &pageData); err != nil {
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	if err := server.ValidateRequest(req, &pageData); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to update flavour")
+		return
+	}
+
+	flavour.ParentID = nil
+	if req.Parent != 0 {
+		if coffee.FlavourWithin(pageData.Flavours, req.Parent, flavour.ID) {
+			ui.Toast(rw, ui.Warning, "A flavour cannot be moved inside itself")
+			return
+		}
+
+		parent, err := c.repo.FindFlavourProfile(req.Parent)
+		if err != nil {
+			ui.Toast(rw, ui.Warning, "Parent flavour not found")
+			return
+		}
+
+		flavour.ParentID = &parent.ID
+	}
+
+	flavour.Name = req.Name
+	if err := c.repo.SaveFlavourProfile(flavour); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to update flavour")
+		return
+	}
+
+	ui.Toast(rw, ui.Success, "Flavour updated")
+}
+
+type mergeFlavourRequest struct {
+	Into uint `json:"into" validate:"required"`
+}
+
+// MergeFlavour folds a flavour into another, any coffees using it will use the other flavour
+// instead
+func (c Controller) MergeFlavour(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	pageData := c.newFlavourAdminData(user)
+	defer func() {
+		c.loadFlavourAdminData(&pageData)
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	id, err := server.PathID(r)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Flavour not found")
+		return
+	}
+
+	from, err := c.repo.FindFlavourProfile(id)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Flavour not found")
+		return
+	}
+
+	var req mergeFlavourRequest
+	if err := server.UnmarshalBody(r, &req); err != nil {
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	if err := server.ValidateRequest(req); err != nil {
+		ui.Toast(rw, ui.Warning, "Pick a flavour to merge into")
+		return
+	}
+
+	if coffee.FlavourWithin(pageData.Flavours, req.Into, from.ID) {
+		ui.Toast(rw, ui.Warning, "A flavour cannot be merged into itself or its children")
+		return
+	}
+
+	into, err := c.repo.FindFlavourProfile(req.Into)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Flavour not found")
+		return
+	}
+
+	if err := c.repo.MergeFlavourProfiles(from, into); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to merge flavours")
+		return
+	}
+
+	ui.Toast(rw, ui.Success, "Merged "+from.Name+" into "+into.Name)
+}
+
+// DeleteFlavour removes a flavour that is not used by any coffees and has no child flavours
+func (c Controller) DeleteFlavour(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	pageData := c.newFlavourAdminData(user)
+	defer func() {
+		c.loadFlavourAdminData(&pageData)
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	id, err := server.PathID(r)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Flavour not found")
+		return
+	}
+
+	flavour, err := c.repo.FindFlavourProfile(id)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Flavour not found")
+		return
+	}
+
+	if pageData.Usage[flavour.ID] > 0 {
+		ui.Toast(rw, ui.Warning, "Flavour is in use, merge it into another flavour instead")
+		return
+	}
+
+	if hasChildFlavours(pageData.Flavours, flavour.ID) {
+		ui.Toast(rw, ui.Warning, "Flavour has child flavours, move or delete them first")
+		return
+	}
+
+	if err := c.repo.DeleteFlavourProfile(flavour); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to delete flavour")
+		return
+	}
+
+	ui.Toast(rw, ui.Success, "Flavour deleted")
+}
+
+func hasChildFlavours(flavours []coffee.FlavourProfile, id uint) bool {
+	for _, flavour := range flavours {
+		if flavour.ParentID != nil && *flavour.ParentID == id {
+			return true
+		}
+	}
+
+	return false
+}
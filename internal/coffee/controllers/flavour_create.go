@@ -1,7 +1,9 @@
 package coffee_controllers
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/indeedhat/barista/internal/auth"
 	"github.com/indeedhat/barista/internal/coffee"
@@ -10,13 +12,17 @@ import (
 )
 
 type createFlavourProfileRequest struct {
-	Name string `json:"name" validate:"required"`
+	Name   string `json:"name" validate:"required"`
+	Parent uint   `json:"parent"`
+	// Confirm is set when the user has chosen to ignore the suggested similar flavours
+	Confirm bool `json:"confirm"`
 }
 
 type createFlavoursData struct {
 	ui.PageData
-	Flavours []coffee.FlavourProfile
-	Open     bool
+	Flavours    []coffee.FlavourProfile
+	Suggestions []coffee.FlavourProfile
+	Open        bool
 }
 
 func (c Controller) CreateFlavourProfile(rw http.ResponseWriter, r *http.Request) {
@@ -38,10 +44,30 @@ func (c Controller) CreateFlavourProfile(rw http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if !req.Confirm {
+		if similar := coffee.SimilarFlavours(req.Name, pageData.Flavours); len(similar) > 0 {
+			pageData.Suggestions = similar
+			ui.Toast(rw, ui.Info, didYouMean(similar))
+			ui.RenderUser(rw, r, pageData)
+			return
+		}
+	}
+
 	flavour := coffee.FlavourProfile{
 		Name: req.Name,
 	}
 
+	if req.Parent != 0 {
+		parent, err := c.repo.FindFlavourProfile(req.Parent)
+		if err != nil {
+			ui.Toast(rw, ui.Warning, "Parent flavour not found")
+			ui.RenderUser(rw, r, pageData)
+			return
+		}
+
+		flavour.ParentID = &parent.ID
+	}
+
 	if err := c.repo.SaveFlavourProfile(&flavour); err != nil {
 		ui.Toast(rw, ui.Warning, "Failed to create flavour")
 		ui.RenderUser(rw, r, pageData)
@@ -58,7 +84,11 @@ func (c Controller) CreateFlavourProfile(rw http.ResponseWriter, r *http.Request
 
 type createFlavourFromComponentRequest struct {
 	Name     string `json:"new_flavour" validate:"required"`
-	Existing []uint `json:"flavours" validate:"required"`
+	Existing []uint `json:"flavours"`
+	Confirm  bool   `json:"confirm_new_flavour"`
+	// Parent is the flavour the new one is filed under, it is required so that free text flavours
+	// do not end up as top level categories in the wheel
+	Parent uint `json:"new_flavour_parent"`
 }
 
 func (c Controller) CreateFlavourFromComponent(rw http.ResponseWriter, r *http.Request) {
@@ -81,8 +111,36 @@ func (c Controller) CreateFlavourFromComponent(rw http.ResponseWriter, r *http.R
 
 	comData["existing"] = req.Existing
 
+	if !req.Confirm {
+		if similar := coffee.SimilarFlavours(req.Name, comData["flavours"].([]coffee.FlavourProfile)); len(similar) > 0 {
+			comData["suggestions"] = similar
+			comData["new_flavour"] = req.Name
+
+			ui.Toast(rw, ui.Info, didYouMean(similar))
+			ui.RenderComponent(rw, comData)
+			return
+		}
+	}
+
+	if req.Parent == 0 {
+		comData["new_flavour"] = req.Name
+		comData["choose_parent"] = true
+
+		ui.Toast(rw, ui.Info, fmt.Sprintf("Choose where %s belongs in the flavour wheel", req.Name))
+		ui.RenderComponent(rw, comData)
+		return
+	}
+
+	parent, err := c.repo.FindFlavourProfile(req.Parent)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Parent flavour not found")
+		rw.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	}
+
 	flavour := coffee.FlavourProfile{
-		Name: req.Name,
+		Name:     req.Name,
+		ParentID: &parent.ID,
 	}
 
 	if err := c.repo.SaveFlavourProfile(&flavour); err != nil {
@@ -97,3 +155,21 @@ func (c Controller) CreateFlavourFromComponent(rw http.ResponseWriter, r *http.R
 	ui.Toast(rw, ui.Success, "Flavour created")
 	ui.RenderComponent(rw, comData)
 }
+
+// didYouMean builds the message suggesting existing flavours in place of creating a new one
+func didYouMean(similar []coffee.FlavourProfile) string {
+	names := make([]string, 0, len(similar))
+	for _, flavour := range similar {
+		names = append(names, flavour.Name)
+	}
+
+	if len(names) == 1 {
+		return fmt.Sprintf("Did you mean %s?", names[0])
+	}
+
+	return fmt.Sprintf(
+		"Did you mean %s or %s?",
+		strings.Join(names[:len(names)-1], ", "),
+		names[len(names)-1],
+	)
+}
@@ -10,8 +10,9 @@ import (
 
 type viewFlavoursData struct {
 	ui.PageData
-	Flavours []coffee.FlavourProfile
-	Open     bool
+	Flavours    []coffee.FlavourProfile
+	Suggestions []coffee.FlavourProfile
+	Open        bool
 }
 
 func (c Controller) ViewFlavours(rw http.ResponseWriter, r *http.Request) {
@@ -6,15 +6,16 @@ import (
 	"github.com/indeedhat/barista/internal/auth"
 	"github.com/indeedhat/barista/internal/coffee"
 	"github.com/indeedhat/barista/internal/server"
-	"github.com/indeedhat/barista/internal/types"
 	"github.com/indeedhat/barista/internal/ui"
 )
 
 type createRecipeRequest struct {
+	recipeParamsRequest
+
 	Name         string        `json:"name" validate:"required"`
 	Dose         float64       `json:"dose" validate:"required"`
 	WeightOut    float64       `json:"weight_out" validate:"required"`
-	Drink        string        `json:"drink" validate:"required"`
+	Drink        uint          `json:"drink" validate:"required"`
 	Declump      string        `json:"declump"`
 	RDT          uint8         `json:"rdt"`
 	Frozen       bool          `json:"frozen"`
@@ -24,13 +25,15 @@ type createRecipeRequest struct {
 	Rating       uint8         `json:"rating"`
 	Basket       *uint         `json:"basket"`
 	Brewer       *uint         `json:"brewer"`
+	Equipment    []uint        `json:"equipment"`
 }
 
 func (c Controller) CreateRecipe(rw http.ResponseWriter, r *http.Request) {
 	user := r.Context().Value("user").(*auth.User)
 	comData := ui.NewComponentData("recipe-card", ui.ComponentData{
-		"edit":   true,
-		"Drinks": types.Drinks,
+		"edit":      true,
+		"Drinks":    c.typeRepo.IndexDrinkTypes(user.ID),
+		"MilkTypes": c.repo.IndexMilkTypes(user),
 	})
 	defer func() {
 		ui.RenderComponent(rw, comData)
@@ -60,13 +63,40 @@ func (c Controller) CreateRecipe(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	drink, err := c.typeRepo.FindDrinkType(req.Drink, user.ID)
+	if err != nil {
+		comData.SetFieldErrors(map[string][]string{"drink": {"Drink type not found"}})
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	equipment, ok := c.findRecipeEquipment(req.Equipment, user)
+	if !ok {
+		comData.SetFieldErrors(map[string][]string{"equipment": {"Equipment not found"}})
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	if errs, msg := c.checkBasket(req.Brewer, req.Basket, req.Dose, user); len(errs) > 0 {
+		comData.SetFieldErrors(errs)
+		ui.Toast(rw, ui.Warning, msg)
+		return
+	}
+
+	if errs := req.validateParams(); len(errs) > 0 {
+		comData.SetFieldErrors(errs)
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
 	recipe := coffee.Recipe{
 		User:         *user,
 		Coffee:       *coffeeModel,
 		Name:         req.Name,
 		Dose:         req.Dose,
 		WeightOut:    req.WeightOut,
-		Drink:        req.Drink,
+		DrinkID:      drink.ID,
+		Drink:        *drink,
 		Declump:      req.Declump,
 		RDT:          req.RDT,
 		Frozen:       req.Frozen,
@@ -75,8 +105,14 @@ func (c Controller) CreateRecipe(rw http.ResponseWriter, r *http.Request) {
 		Rating:       req.Rating,
 		BrewerID:     req.Brewer,
 		BasketID:     req.Basket,
+		Equipment:    equipment,
 	}
 	assignSteps(&recipe, req.Steps)
+	req.applyParams(&recipe)
+	if err := c.applyMilkType(req.MilkType, &recipe, user); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to save milk type")
+		return
+	}
 
 	coffeeModel.Recipes = append(coffeeModel.Recipes, recipe)
 	if err := c.repo.SaveRecipe(&recipe); err != nil {
@@ -84,6 +120,11 @@ func (c Controller) CreateRecipe(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// reload the recipe so the card can show the parameters for the picked brewer
+	if saved, err := c.repo.FindRecipe(recipe.ID); err == nil {
+		recipe = *saved
+	}
+
 	comData["Recipe"] = recipe
 	comData["edit"] = false
 	comData.SetForm(createRecipeRequest{})
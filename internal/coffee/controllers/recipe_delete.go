@@ -5,15 +5,15 @@ import (
 
 	"github.com/indeedhat/barista/internal/auth"
 	"github.com/indeedhat/barista/internal/server"
-	"github.com/indeedhat/barista/internal/types"
 	"github.com/indeedhat/barista/internal/ui"
 )
 
 func (c Controller) DeleteRecipe(rw http.ResponseWriter, r *http.Request) {
 	user := r.Context().Value("user").(*auth.User)
 	comData := ui.NewComponentData("recipe-card", ui.ComponentData{
-		"Open":   true,
-		"Drinks": types.Drinks,
+		"Open":      true,
+		"Drinks":    c.typeRepo.IndexDrinkTypes(user.ID),
+		"MilkTypes": c.repo.IndexMilkTypes(user),
 	})
 	defer func() {
 		ui.RenderComponent(rw, comData)
@@ -0,0 +1,171 @@
+package coffee_controllers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/coffee"
+	"github.com/indeedhat/barista/internal/equipment"
+)
+
+type recipePourRequest struct {
+	// Time is in seconds from the start of the brew
+	Time  int     `json:"time" validate:"min=0"`
+	Water float64 `json:"water" validate:"min=0"`
+}
+
+// recipeParamsRequest holds the brewer specific parameters shared by the create and update recipe
+// requests
+//
+// The form only sends the set that matches the picked brewer so the other set is cleared on save,
+// the milk parameters are only kept for drinks that call for milk
+type recipeParamsRequest struct {
+	BrewTemperature     float64 `json:"brew_temperature" validate:"min=0,max=100"`
+	PreinfusionTime     int     `json:"preinfusion_time" validate:"min=0"`
+	PreinfusionPressure float64 `json:"preinfusion_pressure" validate:"min=0,max=20"`
+	PeakPressure        float64 `json:"peak_pressure" validate:"min=0,max=20"`
+	FlowProfile         string  `json:"flow_profile"`
+
+	WaterTemperature float64             `json:"water_temperature" validate:"min=0,max=100"`
+	BloomWater       float64             `json:"bloom_water" validate:"min=0"`
+	BloomTime        int                 `json:"bloom_time" validate:"min=0"`
+	Pours            []recipePourRequest `json:"pours" validate:"dive"`
+
+	// MilkType is the name of the milk type, new names are added to the users list of milk types
+	MilkType         string  `json:"milk_type" validate:"max=50"`
+	MilkVolume       float64 `json:"milk_volume" validate:"min=0,max=2000"`
+	SteamTemperature float64 `json:"steam_temperature" validate:"min=0,max=100"`
+	Texture          string  `json:"texture"`
+	AddIns           string  `json:"add_ins"`
+	AddInVolume      float64 `json:"add_in_volume" validate:"min=0,max=1000"`
+}
+
+// validateParams runs the checks that cannot be expressed with validation tags
+//
+// Empty pour rows are dropped before validating
+func (p *recipeParamsRequest) validateParams() map[string][]string {
+	errs := make(map[string][]string)
+
+	p.Pours = slices.DeleteFunc(p.Pours, func(pour recipePourRequest) bool {
+		return pour.Time == 0 && pour.Water == 0
+	})
+
+	for i := 1; i < len(p.Pours); i++ {
+		if p.Pours[i].Time <= p.Pours[i-1].Time || p.Pours[i].Water <= p.Pours[i-1].Water {
+			errs["pours"] = append(errs["pours"], "Each pour must come after the last and add more water")
+			break
+		}
+	}
+
+	if p.PreinfusionPressure > 0 && p.PeakPressure > 0 && p.PreinfusionPressure > p.PeakPressure {
+		errs["preinfusion_pressure"] = append(
+			errs["preinfusion_pressure"],
+			"Pre-infusion pressure cannot be above the peak pressure",
+		)
+	}
+
+	return errs
+}
+
+// applyParams copies the brewer and milk parameters from the request onto the recipe
+//
+// The drink must already be set on the recipe
+func (p recipeParamsRequest) applyParams(recipe *coffee.Recipe) {
+	recipe.Espresso = coffee.EspressoParams{
+		BrewTemperature:     p.BrewTemperature,
+		PreinfusionTime:     time.Duration(p.PreinfusionTime) * time.Second,
+		PreinfusionPressure: p.PreinfusionPressure,
+		PeakPressure:        p.PeakPressure,
+		FlowProfile:         p.FlowProfile,
+	}
+
+	recipe.Filter = coffee.FilterParams{
+		WaterTemperature: p.WaterTemperature,
+		BloomWater:       p.BloomWater,
+		BloomTime:        time.Duration(p.BloomTime) * time.Second,
+	}
+	for _, pour := range p.Pours {
+		recipe.Filter.Pours = append(recipe.Filter.Pours, coffee.Pour{
+			At:    time.Duration(pour.Time) * time.Second,
+			Water: pour.Water,
+		})
+	}
+
+	recipe.Milk = coffee.MilkParams{}
+	if recipe.IsMilkDrink() {
+		recipe.Milk = coffee.MilkParams{
+			Volume:           p.MilkVolume,
+			SteamTemperature: p.SteamTemperature,
+			Texture:          p.Texture,
+			AddIns:           p.AddIns,
+			AddInVolume:      p.AddInVolume,
+		}
+	}
+}
+
+// applyMilkType links the recipe to the named milk type, adding it to the users milk types if it
+// is new
+func (c Controller) applyMilkType(name string, recipe *coffee.Recipe, user *auth.User) error {
+	recipe.MilkTypeID = nil
+
+	name = strings.TrimSpace(name)
+	if name == "" || !recipe.IsMilkDrink() {
+		return nil
+	}
+
+	milkType, err := c.repo.FindOrCreateMilkType(name, user)
+	if err != nil {
+		return err
+	}
+
+	recipe.MilkTypeID = &milkType.ID
+	return nil
+}
+
+// findRecipeEquipment looks up the equipment picked for a recipe, false is returned if any of it
+// does not belong to the user
+func (c Controller) findRecipeEquipment(ids []uint, user *auth.User) ([]equipment.Equipment, bool) {
+	ids = slices.Compact(slices.Sorted(slices.Values(ids)))
+
+	found := c.equipmentRepo.FindEquipmentByIds(ids, user.ID)
+	return found, len(found) == len(ids)
+}
+
+// checkBasket makes sure the picked basket belongs to the picked brewer, fits in its portafilter and
+// that the recipes dose is within the baskets dose range
+//
+// Any field errors are returned along with the message to show in the toast
+func (c Controller) checkBasket(brewerId, basketId *uint, dose float64, user *auth.User) (map[string][]string, string) {
+	if basketId == nil || *basketId == 0 {
+		return nil, ""
+	}
+
+	if brewerId == nil {
+		return map[string][]string{"basket": {"Basket not found"}}, "Bad request"
+	}
+
+	brewerModel, err := c.brewerRepo.FindBrewer(*brewerId, user.ID)
+	if err != nil {
+		return map[string][]string{"brewer": {"Brewer not found"}}, "Bad request"
+	}
+
+	basket := brewerModel.Basket(*basketId)
+	if basket == nil {
+		return map[string][]string{"basket": {"Basket not found"}}, "Bad request"
+	}
+
+	if !brewerModel.Fits(*basket) {
+		msg := fmt.Sprintf("Basket does not fit the brewers %gmm portafilter", brewerModel.PortafilterSize)
+		return map[string][]string{"basket": {msg}}, msg
+	}
+
+	if !basket.FitsDose(dose) {
+		msg := fmt.Sprintf("Dose is outside of the %s range for the %s basket", basket.DoseRange(), basket.Name)
+		return map[string][]string{"dose": {msg}, "basket": {msg}}, msg
+	}
+
+	return nil, ""
+}
@@ -5,15 +5,16 @@ import (
 
 	"github.com/indeedhat/barista/internal/auth"
 	"github.com/indeedhat/barista/internal/server"
-	"github.com/indeedhat/barista/internal/types"
 	"github.com/indeedhat/barista/internal/ui"
 )
 
 type updateRecipeRequest struct {
+	recipeParamsRequest
+
 	Name         string        `json:"name" validate:"required"`
 	Dose         float64       `json:"dose" validate:"required"`
 	WeightOut    float64       `json:"weight_out" validate:"required"`
-	Drink        string        `json:"drink" validate:"required"`
+	Drink        uint          `json:"drink" validate:"required"`
 	Declump      string        `json:"declump"`
 	RDT          uint8         `json:"rdt"`
 	Frozen       bool          `json:"frozen"`
@@ -23,13 +24,15 @@ type updateRecipeRequest struct {
 	Rating       uint8         `json:"rating"`
 	Basket       *uint         `json:"basket"`
 	Brewer       *uint         `json:"brewer"`
+	Equipment    []uint        `json:"equipment"`
 }
 
 func (c Controller) UpdateRecipe(rw http.ResponseWriter, r *http.Request) {
 	user := r.Context().Value("user").(*auth.User)
 	comData := ui.NewComponentData("recipe-card", ui.ComponentData{
-		"edit":   true,
-		"Drinks": types.Drinks,
+		"edit":      true,
+		"Drinks":    c.typeRepo.IndexDrinkTypes(user.ID),
+		"MilkTypes": c.repo.IndexMilkTypes(user),
 	})
 	defer func() {
 		ui.RenderComponent(rw, comData)
@@ -67,10 +70,37 @@ func (c Controller) UpdateRecipe(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	drink, err := c.typeRepo.FindDrinkType(req.Drink, user.ID)
+	if err != nil {
+		comData.SetFieldErrors(map[string][]string{"drink": {"Drink type not found"}})
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	equipment, ok := c.findRecipeEquipment(req.Equipment, user)
+	if !ok {
+		comData.SetFieldErrors(map[string][]string{"equipment": {"Equipment not found"}})
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	if errs, msg := c.checkBasket(req.Brewer, req.Basket, req.Dose, user); len(errs) > 0 {
+		comData.SetFieldErrors(errs)
+		ui.Toast(rw, ui.Warning, msg)
+		return
+	}
+
+	if errs := req.validateParams(); len(errs) > 0 {
+		comData.SetFieldErrors(errs)
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
 	recipe.Name = req.Name
 	recipe.Dose = req.Dose
 	recipe.WeightOut = req.WeightOut
-	recipe.Drink = req.Drink
+	recipe.DrinkID = drink.ID
+	recipe.Drink = *drink
 	recipe.Declump = req.Declump
 	recipe.RDT = req.RDT
 	recipe.Frozen = req.Frozen
@@ -79,13 +109,24 @@ func (c Controller) UpdateRecipe(rw http.ResponseWriter, r *http.Request) {
 	recipe.Rating = req.Rating
 	recipe.BrewerID = req.Brewer
 	recipe.BasketID = req.Basket
+	recipe.Equipment = equipment
 	assignSteps(recipe, req.Steps)
+	req.applyParams(recipe)
+	if err := c.applyMilkType(req.MilkType, recipe, user); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to save milk type")
+		return
+	}
 
 	if err := c.repo.SaveRecipe(recipe); err != nil {
 		ui.Toast(rw, ui.Warning, "Failed to save recipe")
 		return
 	}
 
+	// reload the recipe so the card can show the parameters for the picked brewer
+	if saved, err := c.repo.FindRecipe(recipe.ID); err == nil {
+		recipe = saved
+	}
+
 	coffee.AddRecipe(*recipe)
 	comData["Recipe"] = recipe
 	comData["edit"] = false
@@ -15,10 +15,11 @@ import (
 func (c Controller) NewRecipe(rw http.ResponseWriter, r *http.Request) {
 	user := r.Context().Value("user").(*auth.User)
 	comData := ui.NewComponentData("recipe-card", ui.ComponentData{
-		"Form":   map[string]struct{}{},
-		"Recipe": map[string]struct{}{},
-		"Drinks": types.Drinks,
-		"edit":   true,
+		"Form":      map[string]struct{}{},
+		"Recipe":    map[string]struct{}{},
+		"Drinks":    c.typeRepo.IndexDrinkTypes(user.ID),
+		"MilkTypes": c.repo.IndexMilkTypes(user),
+		"edit":      true,
 	})
 	defer func() {
 		ui.RenderComponent(rw, comData)
@@ -43,14 +44,17 @@ type viewRecipesFilters struct {
 	Coffees  []string
 	Caffeine []kv
 	Drinks   []string
+	Origins  []string
 	Brewers  []string
 	Rating   []kv
 }
 
 type viewRecipesData struct {
 	ui.PageData
-	Recipes []coffee.Recipe
-	Filters viewRecipesFilters
+	Recipes   []coffee.Recipe
+	Drinks    []types.DrinkType
+	MilkTypes []coffee.MilkType
+	Filters   viewRecipesFilters
 }
 
 type kv struct {
@@ -63,12 +67,15 @@ func (c Controller) ViewRecipes(rw http.ResponseWriter, r *http.Request) {
 	recipes := c.repo.IndexRecipesForUser(user)
 
 	ui.RenderUser(rw, r, viewRecipesData{
-		PageData: ui.NewPageData("Recipes", "recipes", user),
-		Recipes:  recipes,
+		PageData:  ui.NewPageData("Recipes", "recipes", user),
+		Recipes:   recipes,
+		Drinks:    c.typeRepo.IndexDrinkTypes(user.ID),
+		MilkTypes: c.repo.IndexMilkTypes(user),
 		Filters: viewRecipesFilters{
 			Coffees:  extractRecipe(recipes, func(r coffee.Recipe) *string { return &r.Coffee.Name }),
 			Caffeine: extractCaffeineLevels(recipes),
-			Drinks:   extractRecipe(recipes, func(r coffee.Recipe) *string { return &r.Drink }),
+			Drinks:   extractRecipe(recipes, func(r coffee.Recipe) *string { return &r.Drink.Name }),
+			Origins:  extractRecipe(recipes, func(r coffee.Recipe) *string { return ptr(r.Coffee.OriginType()) }),
 			Brewers: extractRecipe(recipes, func(r coffee.Recipe) *string {
 				if r.Brewer == nil {
 					return nil
@@ -6,7 +6,9 @@ import (
 
 	"github.com/indeedhat/barista/internal/auth"
 	"github.com/indeedhat/barista/internal/coffee"
+	"github.com/indeedhat/barista/internal/images"
 	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/storage"
 	"github.com/indeedhat/barista/internal/ui"
 )
 
@@ -14,6 +16,7 @@ type updateRoasterRequest struct {
 	Name        string `json:"name" validate:"required"`
 	Description string `json:"description"`
 	URL         string `json:"url" validate:"omitempty,url"`
+	Public      bool   `json:"public"`
 }
 
 type updateRoasterData struct {
@@ -57,6 +60,7 @@ func (c Controller) UpdateRoaster(rw http.ResponseWriter, r *http.Request) {
 	roaster.Name = req.Name
 	roaster.Description = req.Description
 	roaster.URL = req.URL
+	roaster.Public = req.Public
 
 	if err := c.repo.SaveRoaster(roaster); err != nil {
 		ui.Toast(rw, ui.Warning, "Failed to create roaster")
@@ -89,17 +93,17 @@ func (c Controller) UpdateRoasterImage(rw http.ResponseWriter, r *http.Request)
 	pageData.Title = roaster.Name
 	pageData.Roaster = roaster
 
-	savePath, err := server.UploadFile(r, "image", fmt.Sprint(RoasterImagePath, roaster.ID), &server.UploadProps{
+	key, err := server.UploadImage(r, "image", fmt.Sprint(RoasterImagePath, roaster.ID), &server.UploadProps{
 		Ext:  []string{".jpg", ".jpeg", ".png"},
 		Mime: []string{"image/png", "image/jpeg"},
-	})
+	}, images.DefaultVariants)
 	if err != nil {
 		ui.Toast(rw, ui.Warning, "Failed to upload image")
 		return
 	}
 
-	if savePath != "" {
-		roaster.Icon = savePath[5:]
+	if key != "" {
+		roaster.Icon = storage.ModelPath(key)
 		if err := c.repo.SaveRoaster(roaster); err != nil {
 			ui.Toast(rw, ui.Warning, "Failed to save image")
 			return
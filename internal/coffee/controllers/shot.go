@@ -0,0 +1,167 @@
+package coffee_controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/brewer"
+	"github.com/indeedhat/barista/internal/coffee"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+// maxShotSize is the largest shot export that will be accepted for import
+const maxShotSize = 5 << 20
+
+// newShotsData builds the data for the recipe-shots component
+func newShotsData(recipe *coffee.Recipe, shots []coffee.Shot) ui.ComponentData {
+	return ui.ComponentData{
+		"Recipe": recipe,
+		"Shots":  shots,
+		"Chart":  coffee.NewShotChart(shots),
+	}
+}
+
+// findRecipe looks up the users recipe from the coffee_id and recipe_id path values
+func (c Controller) findRecipe(r *http.Request, user *auth.User) (*coffee.Recipe, bool) {
+	coffeeId, _ := server.PathID(r, "coffee_id")
+	recipeId, _ := server.PathID(r, "recipe_id")
+
+	recipe, err := c.repo.FindRecipe(recipeId, user.ID)
+	if err != nil || recipe.CoffeeID != coffeeId {
+		return nil, false
+	}
+
+	return recipe, true
+}
+
+func (c Controller) ViewShots(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	recipe, ok := c.findRecipe(r, user)
+	if !ok {
+		ui.Toast(rw, ui.Warning, "Recipe not found")
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	ui.RenderComponent(rw, ui.NewComponentData(
+		"recipe-shots",
+		newShotsData(recipe, c.repo.IndexShots(recipe.ID)),
+	))
+}
+
+// ImportShot reads the telemetry from a shot export and stores it against the recipe
+func (c Controller) ImportShot(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	recipe, ok := c.findRecipe(r, user)
+	if !ok {
+		ui.Toast(rw, ui.Warning, "Recipe not found")
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	defer func() {
+		ui.RenderComponent(rw, ui.NewComponentData(
+			"recipe-shots",
+			newShotsData(recipe, c.repo.IndexShots(recipe.ID)),
+		))
+	}()
+
+	data, filename, err := server.ReadUpload(r, "shot", &server.UploadProps{
+		Ext: []string{".shot", ".json", ".csv"},
+	}, maxShotSize)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, err.Error())
+		return
+	}
+
+	shot, err := coffee.ParseShot(filename, data)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, err.Error())
+		return
+	}
+
+	shot.RecipeID = recipe.ID
+	shot.UserID = user.ID
+	if err := c.repo.SaveShot(shot); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to import shot")
+		return
+	}
+
+	if recipe.BrewerID != nil {
+		// the shot counts towards the brewers maintenance, a failure here is not worth failing the
+		// import over
+		c.brewerRepo.SaveBrew(&brewer.Brew{
+			BrewedAt: shot.PulledAt,
+			BrewerID: *recipe.BrewerID,
+			RecipeID: &recipe.ID,
+		})
+	}
+
+	ui.Toast(rw, ui.Success, "Shot imported")
+}
+
+// LogBrew records that the recipe has been brewed against its brewer
+//
+// Imported shots are logged automatically so this is for brews without telemetry
+func (c Controller) LogBrew(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	recipe, ok := c.findRecipe(r, user)
+	if !ok {
+		ui.Toast(rw, ui.Warning, "Recipe not found")
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if recipe.BrewerID == nil {
+		ui.Toast(rw, ui.Warning, "The recipe does not have a brewer")
+		return
+	}
+
+	brew := brewer.Brew{
+		BrewedAt: time.Now(),
+		BrewerID: *recipe.BrewerID,
+		RecipeID: &recipe.ID,
+	}
+
+	if err := c.brewerRepo.SaveBrew(&brew); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to log brew")
+		return
+	}
+
+	ui.Toast(rw, ui.Success, "Brew logged")
+}
+
+func (c Controller) DeleteShot(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	recipe, ok := c.findRecipe(r, user)
+	if !ok {
+		ui.Toast(rw, ui.Warning, "Recipe not found")
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	shotId, _ := server.PathID(r, "shot_id")
+	shot, err := c.repo.FindShot(shotId, user.ID)
+	if err != nil || shot.RecipeID != recipe.ID {
+		ui.Toast(rw, ui.Warning, "Shot not found")
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if err := c.repo.DeleteShot(shot); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to delete shot")
+	} else {
+		ui.Toast(rw, ui.Success, "Shot deleted")
+	}
+
+	ui.RenderComponent(rw, ui.NewComponentData(
+		"recipe-shots",
+		newShotsData(recipe, c.repo.IndexShots(recipe.ID)),
+	))
+}
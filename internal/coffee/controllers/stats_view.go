@@ -0,0 +1,33 @@
+package coffee_controllers
+
+import (
+	"net/http"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/coffee"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+type viewStatsData struct {
+	ui.PageData
+	FlavourCategories []coffee.FlavourCategoryStat
+	OriginTypes       []coffee.OriginStat
+	Processes         []coffee.OriginStat
+	Countries         []coffee.OriginStat
+}
+
+func (c Controller) ViewStats(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	pageData := viewStatsData{PageData: ui.NewPageData("Stats", "stats", user)}
+
+	pageData.FlavourCategories = c.repo.FlavourCategoryStats(user)
+
+	coffees := c.repo.IndexCoffeesForUser(user)
+	pageData.OriginTypes = coffee.GroupCoffeeRatings(coffees, func(c coffee.Coffee) []string {
+		return []string{c.OriginType()}
+	})
+	pageData.Processes = coffee.GroupCoffeeRatings(coffees, coffee.Coffee.Processes)
+	pageData.Countries = coffee.GroupCoffeeRatings(coffees, coffee.Coffee.Countries)
+
+	ui.RenderUser(rw, r, pageData)
+}
@@ -0,0 +1,234 @@
+package coffee
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/database/model"
+)
+
+// Cupping is a single cupping session for a coffee scored using the SCA cupping form
+//
+// The quality attributes are scored from 6 to 10 in quarter point steps, uniformity, clean cup and
+// sweetness are scored out of 10 with 2 points for each of the 5 cups
+type Cupping struct {
+	model.SoftDelete
+
+	CuppedAt time.Time
+
+	Fragrance  float64 // fragrance/aroma
+	Flavour    float64
+	Aftertaste float64
+	Acidity    float64
+	Body       float64
+	Balance    float64
+	Uniformity float64
+	CleanCup   float64
+	Sweetness  float64
+	Overall    float64
+
+	// Taints and Faults are the number of cups with each kind of defect
+	Taints uint8
+	Faults uint8
+
+	Notes string
+
+	CoffeeID uint `gorm:"index"`
+
+	UserID uint
+	User   auth.User
+}
+
+// CuppingAttribute is a single scored attribute on the SCA cupping form
+type CuppingAttribute struct {
+	Name  string
+	Score float64
+}
+
+// Attributes lists the cuppings scores in the order they appear on the SCA cupping form
+func (c Cupping) Attributes() []CuppingAttribute {
+	return []CuppingAttribute{
+		{"Fragrance/Aroma", c.Fragrance},
+		{"Flavour", c.Flavour},
+		{"Aftertaste", c.Aftertaste},
+		{"Acidity", c.Acidity},
+		{"Body", c.Body},
+		{"Balance", c.Balance},
+		{"Uniformity", c.Uniformity},
+		{"Clean Cup", c.CleanCup},
+		{"Sweetness", c.Sweetness},
+		{"Overall", c.Overall},
+	}
+}
+
+// Defects is the number of points deducted for tainted and faulty cups
+func (c Cupping) Defects() float64 {
+	return float64(c.Taints)*2 + float64(c.Faults)*4
+}
+
+// Total is the final score for the cupping after defects have been subtracted
+func (c Cupping) Total() float64 {
+	var total float64
+	for _, attr := range c.Attributes() {
+		total += attr.Score
+	}
+
+	return max(total-c.Defects(), 0)
+}
+
+// Grade describes the total score using the SCA quality classification
+func (c Cupping) Grade() string {
+	switch total := c.Total(); {
+	case total >= 90:
+		return "Outstanding"
+	case total >= 85:
+		return "Excellent"
+	case total >= 80:
+		return "Very Good"
+	default:
+		return "Below Specialty"
+	}
+}
+
+// AverageCupping creates a cupping with the mean score of each attribute across all the cuppings
+func AverageCupping(cuppings []Cupping) Cupping {
+	var avg Cupping
+	if len(cuppings) == 0 {
+		return avg
+	}
+
+	for _, c := range cuppings {
+		avg.Fragrance += c.Fragrance
+		avg.Flavour += c.Flavour
+		avg.Aftertaste += c.Aftertaste
+		avg.Acidity += c.Acidity
+		avg.Body += c.Body
+		avg.Balance += c.Balance
+		avg.Uniformity += c.Uniformity
+		avg.CleanCup += c.CleanCup
+		avg.Sweetness += c.Sweetness
+		avg.Overall += c.Overall
+	}
+
+	n := float64(len(cuppings))
+	for _, score := range []*float64{
+		&avg.Fragrance, &avg.Flavour, &avg.Aftertaste, &avg.Acidity, &avg.Body,
+		&avg.Balance, &avg.Uniformity, &avg.CleanCup, &avg.Sweetness, &avg.Overall,
+	} {
+		*score /= n
+	}
+
+	return avg
+}
+
+const (
+	radarSize     = 300
+	radarRadius   = 100
+	radarMinScore = 5
+	radarMaxScore = 10
+)
+
+// RadarAxis is a single spoke of the radar chart with the position of its end and label
+type RadarAxis struct {
+	Label  string
+	X, Y   float64
+	LabelX float64
+	LabelY float64
+	Anchor string
+}
+
+// RadarChart holds the precomputed geometry for drawing cuppings as an svg radar chart
+type RadarChart struct {
+	Size   int
+	Center int
+	Axes   []RadarAxis
+	Rings  []string
+	// Average is the polygon for the mean of all cuppings
+	Average string
+	// Cuppings are the polygons for each individual cupping
+	Cuppings []string
+}
+
+// NewRadarChart builds the radar chart for the given cuppings
+//
+// Scores below radarMinScore are drawn at the center of the chart so that the differences
+// between specialty grade scores are visible
+func NewRadarChart(cuppings []Cupping) RadarChart {
+	chart := RadarChart{Size: radarSize, Center: radarSize / 2}
+
+	attrs := AverageCupping(cuppings).Attributes()
+	for i, attr := range attrs {
+		x, y := radarPoint(i, len(attrs), radarRadius)
+		lx, ly := radarPoint(i, len(attrs), radarRadius+18)
+
+		anchor := "middle"
+		if lx > radarSize/2+1 {
+			anchor = "start"
+		} else if lx < radarSize/2-1 {
+			anchor = "end"
+		}
+
+		chart.Axes = append(chart.Axes, RadarAxis{
+			Label:  attr.Name,
+			X:      x,
+			Y:      y,
+			LabelX: lx,
+			LabelY: ly,
+			Anchor: anchor,
+		})
+	}
+
+	for score := radarMinScore + 1; score <= radarMaxScore; score++ {
+		ring := make([]float64, len(attrs))
+		for i := range ring {
+			ring[i] = float64(score)
+		}
+		chart.Rings = append(chart.Rings, radarPolygon(ring))
+	}
+
+	if len(cuppings) == 0 {
+		return chart
+	}
+
+	for _, cupping := range cuppings {
+		chart.Cuppings = append(chart.Cuppings, radarPolygon(cuppingScores(cupping)))
+	}
+	chart.Average = radarPolygon(cuppingScores(AverageCupping(cuppings)))
+
+	return chart
+}
+
+func cuppingScores(c Cupping) []float64 {
+	attrs := c.Attributes()
+	scores := make([]float64, 0, len(attrs))
+	for _, attr := range attrs {
+		scores = append(scores, attr.Score)
+	}
+
+	return scores
+}
+
+// radarPolygon converts a set of scores into the points attribute of an svg polygon
+func radarPolygon(scores []float64) string {
+	points := make([]string, 0, len(scores))
+	for i, score := range scores {
+		scaled := (min(max(score, radarMinScore), radarMaxScore) - radarMinScore) /
+			(radarMaxScore - radarMinScore)
+
+		x, y := radarPoint(i, len(scores), scaled*radarRadius)
+		points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+
+	return strings.Join(points, " ")
+}
+
+// radarPoint finds the position of a point on the given spoke, the first spoke points straight up
+func radarPoint(i, spokes int, radius float64) (float64, float64) {
+	angle := 2*math.Pi*float64(i)/float64(spokes) - math.Pi/2
+	center := float64(radarSize) / 2
+
+	return center + radius*math.Cos(angle), center + radius*math.Sin(angle)
+}
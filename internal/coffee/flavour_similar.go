@@ -0,0 +1,165 @@
+package coffee
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// maxFlavourSuggestions is the most similar flavours that SimilarFlavours will return
+const maxFlavourSuggestions = 3
+
+// SimilarFlavours finds existing flavours with names close enough to name that they are likely to
+// be the same flavour, closest first
+//
+// Names are compared ignoring case, punctuation and plurals so "berries", "Berry" and "Red-berry"
+// are all considered similar
+func SimilarFlavours(name string, flavours []FlavourProfile) []FlavourProfile {
+	target := normaliseFlavourName(name)
+	if target == "" {
+		return nil
+	}
+
+	type match struct {
+		flavour FlavourProfile
+		score   int
+	}
+
+	var matches []match
+	for _, flavour := range flavours {
+		if score, ok := flavourSimilarity(target, normaliseFlavourName(flavour.Name), true); ok {
+			matches = append(matches, match{flavour, score})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b match) int {
+		return a.score - b.score
+	})
+
+	var similar []FlavourProfile
+	for i := 0; i < len(matches) && i < maxFlavourSuggestions; i++ {
+		similar = append(similar, matches[i].flavour)
+	}
+
+	return similar
+}
+
+// FlavourDuplicates finds pairs of flavours that are likely to be duplicates of each other
+//
+// This is stricter than SimilarFlavours, names containing each other are not reported as they are
+// common within the flavour wheel (Chocolate > Dark Chocolate)
+func FlavourDuplicates(flavours []FlavourProfile) [][2]FlavourProfile {
+	names := make([]string, len(flavours))
+	for i, flavour := range flavours {
+		names[i] = normaliseFlavourName(flavour.Name)
+	}
+
+	var pairs [][2]FlavourProfile
+	for i := range flavours {
+		for j := i + 1; j < len(flavours); j++ {
+			if _, ok := flavourSimilarity(names[i], names[j], false); ok {
+				pairs = append(pairs, [2]FlavourProfile{flavours[i], flavours[j]})
+			}
+		}
+	}
+
+	return pairs
+}
+
+// flavourSimilarity scores how close two normalised names are, lower is closer
+//
+// If contains is true then names where one contains the other are also considered similar
+func flavourSimilarity(a, b string, contains bool) (int, bool) {
+	if a == "" || b == "" {
+		return 0, false
+	}
+
+	if a == b {
+		return 0, true
+	}
+
+	distance := levenshtein(a, b)
+	if distance <= maxFlavourDistance(min(len(a), len(b))) {
+		return distance, true
+	}
+
+	if contains && min(len(a), len(b)) >= 4 && (strings.Contains(a, b) || strings.Contains(b, a)) {
+		// rank below any spelling mistakes
+		return 10 + abs(len(a)-len(b)), true
+	}
+
+	return 0, false
+}
+
+// maxFlavourDistance is the number of edits allowed between two names of the given length for them
+// to still be considered the same, short names need to be much closer to avoid false positives
+// (Fig, Fog)
+func maxFlavourDistance(length int) int {
+	switch {
+	case length <= 3:
+		return 0
+	case length <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// normaliseFlavourName lower cases a name, strips anything that isn't a letter or number and
+// removes common plural suffixes
+func normaliseFlavourName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+
+	normalised := b.String()
+	switch {
+	case strings.HasSuffix(normalised, "ies") && len(normalised) > 4:
+		return strings.TrimSuffix(normalised, "ies") + "y"
+	case strings.HasSuffix(normalised, "ches"), strings.HasSuffix(normalised, "shes"),
+		strings.HasSuffix(normalised, "xes"), strings.HasSuffix(normalised, "sses"):
+		return strings.TrimSuffix(normalised, "es")
+	case strings.HasSuffix(normalised, "s") && !strings.HasSuffix(normalised, "ss") && len(normalised) > 3:
+		return strings.TrimSuffix(normalised, "s")
+	}
+
+	return normalised
+}
+
+// levenshtein calculates the number of single character edits needed to turn a into b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
@@ -0,0 +1,54 @@
+package coffee
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSimilarFlavours(t *testing.T) {
+	flavours := []FlavourProfile{
+		testFlavour(1, 0, "Berry"),
+		testFlavour(2, 0, "Dark Chocolate"),
+		testFlavour(3, 0, "Fig"),
+		testFlavour(4, 0, "Blackberry"),
+		testFlavour(5, 0, "Peach"),
+	}
+
+	for _, tc := range []struct {
+		name    string
+		similar []string
+	}{
+		{"berries", []string{"Berry", "Blackberry"}},
+		{"BERRY", []string{"Berry", "Blackberry"}},
+		{"Black-berry", []string{"Blackberry", "Berry"}},
+		{"Chocolate", []string{"Dark Chocolate"}},
+		{"Peaches", []string{"Peach"}},
+		{"Fog", nil},
+		{"Pear", nil},
+		{"!!", nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var names []string
+			for _, flavour := range SimilarFlavours(tc.name, flavours) {
+				names = append(names, flavour.Name)
+			}
+
+			if !slices.Equal(names, tc.similar) {
+				t.Errorf("got %v, want %v", names, tc.similar)
+			}
+		})
+	}
+}
+
+func TestFlavourDuplicates(t *testing.T) {
+	pairs := FlavourDuplicates([]FlavourProfile{
+		testFlavour(1, 0, "Chocolate"),
+		testFlavour(2, 0, "Dark Chocolate"),
+		testFlavour(3, 0, "Rasberry"),
+		testFlavour(4, 0, "Raspberries"),
+	})
+
+	if len(pairs) != 1 || pairs[0][0].ID != 3 || pairs[0][1].ID != 4 {
+		t.Errorf("got %+v", pairs)
+	}
+}
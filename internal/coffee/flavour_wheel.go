@@ -0,0 +1,188 @@
+package coffee
+
+import (
+	"slices"
+	"strings"
+)
+
+// FlavourNode is a single entry in the flavour wheel used to seed the flavour profiles
+type FlavourNode struct {
+	Name     string
+	Children []FlavourNode
+}
+
+func flavourNode(name string, children ...string) FlavourNode {
+	n := FlavourNode{Name: name}
+	for _, child := range children {
+		n.Children = append(n.Children, FlavourNode{Name: child})
+	}
+
+	return n
+}
+
+// FlavourWheel is based on the SCA/WCR coffee taster's flavour wheel
+var FlavourWheel = []FlavourNode{
+	{Name: "Fruity", Children: []FlavourNode{
+		flavourNode("Berry", "Blackberry", "Raspberry", "Blueberry", "Strawberry"),
+		flavourNode("Dried Fruit", "Raisin", "Prune"),
+		flavourNode("Other Fruit", "Coconut", "Cherry", "Pomegranate", "Pineapple", "Grape", "Apple", "Peach", "Pear"),
+		flavourNode("Citrus Fruit", "Grapefruit", "Orange", "Lemon", "Lime"),
+	}},
+	{Name: "Sour/Fermented", Children: []FlavourNode{
+		flavourNode("Sour", "Sour Aromatics", "Acetic Acid", "Butyric Acid", "Isovaleric Acid", "Citric Acid", "Malic Acid"),
+		flavourNode("Alcohol/Fermented", "Winey", "Whiskey", "Fermented", "Overripe"),
+	}},
+	{Name: "Green/Vegetative", Children: []FlavourNode{
+		flavourNode("Olive Oil"),
+		flavourNode("Raw"),
+		flavourNode("Vegetative", "Under-ripe", "Peapod", "Fresh", "Dark Green", "Hay-like", "Herb-like"),
+		flavourNode("Beany"),
+	}},
+	{Name: "Other", Children: []FlavourNode{
+		flavourNode("Papery/Musty",
+			"Stale", "Cardboard", "Papery", "Woody", "Moldy/Damp", "Musty/Dusty", "Musty/Earthy", "Animalic",
+			"Meaty Brothy", "Phenolic",
+		),
+		flavourNode("Chemical", "Bitter", "Salty", "Medicinal", "Petroleum", "Skunky", "Rubber"),
+	}},
+	{Name: "Roasted", Children: []FlavourNode{
+		flavourNode("Pipe Tobacco"),
+		flavourNode("Tobacco"),
+		flavourNode("Burnt", "Acrid", "Ashy", "Smoky", "Brown Roast"),
+		flavourNode("Cereal", "Grain", "Malt"),
+	}},
+	{Name: "Spices", Children: []FlavourNode{
+		flavourNode("Pungent"),
+		flavourNode("Pepper"),
+		flavourNode("Brown Spice", "Anise", "Nutmeg", "Cinnamon", "Clove"),
+	}},
+	{Name: "Nutty/Cocoa", Children: []FlavourNode{
+		flavourNode("Nutty", "Peanuts", "Hazelnut", "Almond"),
+		flavourNode("Cocoa", "Chocolate", "Dark Chocolate"),
+	}},
+	{Name: "Sweet", Children: []FlavourNode{
+		flavourNode("Brown Sugar", "Molasses", "Maple Syrup", "Caramelized", "Honey"),
+		flavourNode("Vanilla"),
+		flavourNode("Vanillin"),
+		flavourNode("Overall Sweet"),
+		flavourNode("Sweet Aromatics"),
+	}},
+	{Name: "Floral", Children: []FlavourNode{
+		flavourNode("Black Tea"),
+		flavourNode("Floral Notes", "Chamomile", "Rose", "Jasmine"),
+	}},
+}
+
+// SortFlavourTree orders flavours depth first with each level sorted by name, the Depth and Path of
+// each flavour is filled in along the way
+//
+// Flavours whose parent is not in the list are treated as top level categories
+func SortFlavourTree(flavours []FlavourProfile) []FlavourProfile {
+	ids := make(map[uint]bool, len(flavours))
+	for _, flavour := range flavours {
+		ids[flavour.ID] = true
+	}
+
+	children := make(map[uint][]FlavourProfile)
+	for _, flavour := range flavours {
+		var parent uint
+		if flavour.ParentID != nil && ids[*flavour.ParentID] {
+			parent = *flavour.ParentID
+		}
+
+		children[parent] = append(children[parent], flavour)
+	}
+
+	sorted := make([]FlavourProfile, 0, len(flavours))
+
+	var walk func(parent uint, depth int, path string)
+	walk = func(parent uint, depth int, path string) {
+		level := children[parent]
+		slices.SortFunc(level, func(a, b FlavourProfile) int {
+			return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		})
+
+		for _, flavour := range level {
+			flavour.Depth = depth
+			flavour.Path = flavour.Name
+			if path != "" {
+				flavour.Path = path + " > " + flavour.Name
+			}
+
+			sorted = append(sorted, flavour)
+			walk(flavour.ID, depth+1, flavour.Path)
+		}
+	}
+	walk(0, 0, "")
+
+	// anything not reached by the walk is part of a loop in the parent ids
+	if len(sorted) < len(flavours) {
+		seen := make(map[uint]bool, len(sorted))
+		for _, flavour := range sorted {
+			seen[flavour.ID] = true
+		}
+
+		for _, flavour := range flavours {
+			if !seen[flavour.ID] {
+				flavour.Path = flavour.Name
+				sorted = append(sorted, flavour)
+			}
+		}
+	}
+
+	return sorted
+}
+
+// FlavourCategories maps the id of each flavour to the id of the top level category it belongs to
+func FlavourCategories(flavours []FlavourProfile) map[uint]uint {
+	parents := make(map[uint]*uint, len(flavours))
+	for _, flavour := range flavours {
+		parents[flavour.ID] = flavour.ParentID
+	}
+
+	categories := make(map[uint]uint, len(flavours))
+	for _, flavour := range flavours {
+		id := flavour.ID
+		// the depth check guards against a loop in the parent ids
+		for depth := 0; depth < len(flavours); depth++ {
+			parent, ok := parents[id]
+			if !ok || parent == nil {
+				break
+			}
+
+			if _, ok := parents[*parent]; !ok {
+				break
+			}
+
+			id = *parent
+		}
+
+		categories[flavour.ID] = id
+	}
+
+	return categories
+}
+
+// FlavourWithin checks if the flavour id is ancestor or sits anywhere below it in the tree
+func FlavourWithin(flavours []FlavourProfile, id, ancestor uint) bool {
+	parents := make(map[uint]*uint, len(flavours))
+	for _, flavour := range flavours {
+		parents[flavour.ID] = flavour.ParentID
+	}
+
+	// the depth check guards against a loop in the parent ids
+	for depth := 0; depth <= len(flavours); depth++ {
+		if id == ancestor {
+			return true
+		}
+
+		parent := parents[id]
+		if parent == nil {
+			return false
+		}
+
+		id = *parent
+	}
+
+	return false
+}
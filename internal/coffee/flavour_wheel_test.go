@@ -0,0 +1,143 @@
+package coffee
+
+import (
+	"slices"
+	"testing"
+)
+
+// testFlavour builds a flavour with the given id and parent, a parent of 0 makes it a top level
+// category
+func testFlavour(id, parent uint, name string) FlavourProfile {
+	flavour := FlavourProfile{Name: name}
+	flavour.ID = id
+	if parent != 0 {
+		flavour.ParentID = &parent
+	}
+
+	return flavour
+}
+
+// testWheel is a small tree of flavours
+//
+//	Fruity(1) > Berry(2) > Blackberry(3)
+//	Fruity(1) > Citrus(4)
+//	Sweet(5) > Honey(6)
+var testWheel = []FlavourProfile{
+	testFlavour(6, 5, "Honey"),
+	testFlavour(3, 2, "Blackberry"),
+	testFlavour(5, 0, "Sweet"),
+	testFlavour(4, 1, "citrus"),
+	testFlavour(2, 1, "Berry"),
+	testFlavour(1, 0, "Fruity"),
+}
+
+func TestSortFlavourTree(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		flavours []FlavourProfile
+		paths    []string
+		depths   []int
+	}{
+		{
+			name:     "wheel",
+			flavours: testWheel,
+			paths: []string{
+				"Fruity", "Fruity > Berry", "Fruity > Berry > Blackberry", "Fruity > citrus",
+				"Sweet", "Sweet > Honey",
+			},
+			depths: []int{0, 1, 2, 1, 0, 1},
+		},
+		{
+			name: "missing parent is top level",
+			flavours: []FlavourProfile{
+				testFlavour(2, 99, "Orphan"),
+				testFlavour(1, 0, "Fruity"),
+			},
+			paths:  []string{"Fruity", "Orphan"},
+			depths: []int{0, 0},
+		},
+		{
+			name: "loop is appended",
+			flavours: []FlavourProfile{
+				testFlavour(1, 0, "Fruity"),
+				testFlavour(2, 3, "Loop A"),
+				testFlavour(3, 2, "Loop B"),
+			},
+			paths:  []string{"Fruity", "Loop A", "Loop B"},
+			depths: []int{0, 0, 0},
+		},
+		{
+			name: "empty",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sorted := SortFlavourTree(slices.Clone(tc.flavours))
+			if len(sorted) != len(tc.flavours) {
+				t.Fatalf("got %d flavours, want %d", len(sorted), len(tc.flavours))
+			}
+
+			for i, flavour := range sorted {
+				if flavour.Path != tc.paths[i] || flavour.Depth != tc.depths[i] {
+					t.Errorf("%d: got %q at depth %d, want %q at depth %d",
+						i, flavour.Path, flavour.Depth, tc.paths[i], tc.depths[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFlavourWithin(t *testing.T) {
+	loop := []FlavourProfile{
+		testFlavour(1, 2, "Loop A"),
+		testFlavour(2, 1, "Loop B"),
+	}
+
+	for _, tc := range []struct {
+		name     string
+		flavours []FlavourProfile
+		id       uint
+		ancestor uint
+		within   bool
+	}{
+		{"self", testWheel, 2, 2, true},
+		{"child", testWheel, 2, 1, true},
+		{"grandchild", testWheel, 3, 1, true},
+		{"parent", testWheel, 1, 2, false},
+		{"sibling", testWheel, 4, 2, false},
+		{"other category", testWheel, 6, 1, false},
+		{"unknown flavour", testWheel, 99, 1, false},
+		{"loop", loop, 1, 3, false},
+		{"loop member", loop, 1, 2, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := FlavourWithin(tc.flavours, tc.id, tc.ancestor); got != tc.within {
+				t.Errorf("got %v, want %v", got, tc.within)
+			}
+		})
+	}
+}
+
+func TestFlavourMoveCycle(t *testing.T) {
+	// moving a flavour is rejected when the new parent is the flavour itself or one of its children,
+	// as is done by the admin edit and merge handlers
+	for _, tc := range []struct {
+		name    string
+		flavour uint
+		parent  uint
+		cycle   bool
+	}{
+		{"under itself", 2, 2, true},
+		{"under its child", 1, 2, true},
+		{"under its grandchild", 1, 3, true},
+		{"under its parent", 3, 1, false},
+		{"under a sibling", 2, 4, false},
+		{"under another category", 2, 5, false},
+		{"leaf under a leaf", 3, 6, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := FlavourWithin(testWheel, tc.parent, tc.flavour); got != tc.cycle {
+				t.Errorf("got %v, want %v", got, tc.cycle)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package coffee
+
+import (
+	"github.com/indeedhat/barista/internal/database/model"
+)
+
+// MilkType is a kind of milk used in milk drinks
+//
+// The built in types are shared by everyone, users can add their own which are only visible to them
+type MilkType struct {
+	model.SoftDelete
+
+	Name string
+
+	// UserID is nil for the built in milk types
+	UserID *uint `gorm:"index"`
+}
+
+// BuiltIn checks if the milk type is one of the defaults available to everyone
+func (m MilkType) BuiltIn() bool {
+	return m.UserID == nil
+}
+
+// DefaultMilkTypes are seeded as the built in milk types
+var DefaultMilkTypes = []string{
+	"Whole",
+	"Semi-Skimmed",
+	"Skimmed",
+	"Oat",
+	"Almond",
+	"Soy",
+	"Coconut",
+	"Lactose Free",
+}
@@ -1,14 +1,20 @@
 package coffee
 
 import (
+	"cmp"
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/indeedhat/barista/internal/auth"
 	"github.com/indeedhat/barista/internal/brewer"
 	"github.com/indeedhat/barista/internal/database/model"
+	"github.com/indeedhat/barista/internal/equipment"
+	"github.com/indeedhat/barista/internal/types"
 )
 
 func ptr[T any](v T) *T {
@@ -45,6 +51,19 @@ type Coffee struct {
 	Notes    string
 	Icon     string
 	Caffeine CaffeineLevel `gorm:"index"`
+	Public   bool
+
+	Country   string `gorm:"index"`
+	Region    string
+	Farm      string // farm or washing station
+	Producer  string
+	Varietals StringList
+	Process   types.ProcessMethod `gorm:"index"`
+	// AltitudeMin and AltitudeMax are in meters above sea level
+	AltitudeMin uint
+	AltitudeMax uint
+	Harvest     string
+	Components  []BlendComponent
 
 	RoasterID uint
 	Roaster   Roaster
@@ -57,6 +76,107 @@ type Coffee struct {
 	Recipes []Recipe
 }
 
+// UploadOwner implements uploads.Owner.
+func (c Coffee) UploadOwner() (uint, bool) {
+	return c.UserID, c.Public
+}
+
+// UploadFiles implements uploads.Owner.
+func (c Coffee) UploadFiles() []string {
+	return []string{c.Icon}
+}
+
+// Altitude formats the altitude range for display
+func (c Coffee) Altitude() string {
+	switch {
+	case c.AltitudeMin == 0 && c.AltitudeMax == 0:
+		return ""
+	case c.AltitudeMax == 0 || c.AltitudeMin == c.AltitudeMax:
+		return fmt.Sprintf("%dm", max(c.AltitudeMin, c.AltitudeMax))
+	case c.AltitudeMin == 0:
+		return fmt.Sprintf("up to %dm", c.AltitudeMax)
+	default:
+		return fmt.Sprintf("%d-%dm", c.AltitudeMin, c.AltitudeMax)
+	}
+}
+
+// Origin summarises where the coffee is from for display on cards
+func (c Coffee) Origin() string {
+	if c.IsBlend() {
+		if countries := c.Countries(); len(countries) > 0 {
+			return "Blend of " + strings.Join(countries, ", ")
+		}
+		return "Blend"
+	}
+
+	parts := make([]string, 0, 3)
+	for _, part := range []string{c.Country, string(c.Process), c.Altitude()} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(parts, " · ")
+}
+
+// IsBlend checks if the coffee is made up of more than one component
+func (c Coffee) IsBlend() bool {
+	return len(c.Components) > 1
+}
+
+// OriginType labels the coffee as either a blend or a single origin
+func (c Coffee) OriginType() string {
+	if c.IsBlend() {
+		return "Blend"
+	}
+
+	return "Single Origin"
+}
+
+// Countries lists the countries the coffee is from
+//
+// For blends this is the countries of each of the components rather than the coffee itself
+func (c Coffee) Countries() []string {
+	return c.originValues(c.Country, func(b BlendComponent) string { return b.Country })
+}
+
+// Processes lists the process methods used for the coffee
+//
+// For blends this is the process of each of the components rather than the coffee itself
+func (c Coffee) Processes() []string {
+	return c.originValues(string(c.Process), func(b BlendComponent) string { return string(b.Process) })
+}
+
+// AllVarietals lists the varietals of the coffee and all of its blend components
+func (c Coffee) AllVarietals() []string {
+	values := slices.Clone(c.Varietals)
+	for _, component := range c.Components {
+		if component.Varietal != "" && !slices.Contains(values, component.Varietal) {
+			values = append(values, component.Varietal)
+		}
+	}
+
+	return values
+}
+
+func (c Coffee) originValues(own string, cb func(BlendComponent) string) []string {
+	if !c.IsBlend() {
+		if own == "" {
+			return nil
+		}
+		return []string{own}
+	}
+
+	var values []string
+	for _, component := range c.Components {
+		if value := cb(component); value != "" && !slices.Contains(values, value) {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
 func (c Coffee) FlavourIds() []uint {
 	var ids []uint
 	for _, flavour := range c.Flavours {
@@ -94,7 +214,6 @@ type Recipe struct {
 	Dose         float64
 	WeightOut    float64
 	Time         time.Duration
-	Drink        string
 	Declump      string
 	RDT          uint8
 	Frozen       bool
@@ -103,11 +222,27 @@ type Recipe struct {
 	Steps        RecipeSteps
 	Rating       uint8
 
+	// Espresso and Filter hold the parameters for the type of brewer the recipe is made with,
+	// only the set matching the brewer is shown
+	Espresso EspressoParams `gorm:"embedded;embeddedPrefix:espresso_"`
+	Filter   FilterParams   `gorm:"embedded;embeddedPrefix:filter_"`
+
+	// Milk is only used for drinks that call for it
+	Milk       MilkParams `gorm:"embedded;embeddedPrefix:milk_"`
+	MilkTypeID *uint
+	MilkType   *MilkType
+
+	DrinkID uint
+	Drink   types.DrinkType
+
 	BrewerID *uint
 	Brewer   *brewer.Brewer
 	BasketID *uint
 	Basket   *brewer.Basket
 
+	// Equipment is the rest of the gear the recipe is made with
+	Equipment []equipment.Equipment `gorm:"many2many:recipe_equipment;"`
+
 	CoffeeID uint
 	Coffee   Coffee `gorm:"foreignKey:CoffeeID"`
 
@@ -115,6 +250,115 @@ type Recipe struct {
 	User   auth.User `gorm:"foreignKey:UserID"`
 }
 
+// UploadOwner implements uploads.Owner.
+//
+// Recipes have no visibility of their own, they follow the coffee they belong to
+func (r Recipe) UploadOwner() (uint, bool) {
+	return r.UserID, r.Coffee.Public
+}
+
+// UploadFiles implements uploads.Owner.
+func (r Recipe) UploadFiles() []string {
+	return nil
+}
+
+// IsEspresso checks if the recipe is pulled on an espresso machine
+//
+// If no brewer has been picked then the drink type is used instead
+func (r Recipe) IsEspresso() bool {
+	if r.Brewer != nil {
+		return r.Brewer.IsEspresso()
+	}
+
+	return r.Drink.IsEspressoBased()
+}
+
+// IsFilter checks if the recipe is brewed with a filter method
+//
+// If no brewer has been picked then the drink type is used instead
+func (r Recipe) IsFilter() bool {
+	if r.Brewer != nil {
+		return r.Brewer.Type.IsFilter()
+	}
+
+	return !r.IsEspresso() && r.Drink.IsFilterBased()
+}
+
+// IsMilkDrink checks if the drink type calls for milk
+func (r Recipe) IsMilkDrink() bool {
+	return r.Drink.IsMilkBased()
+}
+
+// EquipmentIds lists the ids of the equipment the recipe is made with
+func (r Recipe) EquipmentIds() []uint {
+	ids := make([]uint, 0, len(r.Equipment))
+	for _, item := range r.Equipment {
+		ids = append(ids, item.ID)
+	}
+
+	return ids
+}
+
+// TotalVolume is the volume of the finished drink, the liquid out of the brewer plus any milk and
+// add-ins
+func (r Recipe) TotalVolume() float64 {
+	if !r.IsMilkDrink() {
+		return r.WeightOut
+	}
+
+	return r.WeightOut + r.Milk.Volume + r.Milk.AddInVolume
+}
+
+// BlendComponent is one of the coffees that make up a blend
+type BlendComponent struct {
+	model.SoftDelete
+
+	Name       string
+	Percentage uint8
+	Country    string
+	Varietal   string
+	Process    types.ProcessMethod
+
+	CoffeeID uint `gorm:"index"`
+}
+
+// Summary lists the origin details of the component for display
+func (b BlendComponent) Summary() string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{b.Country, b.Varietal, string(b.Process)} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(parts, " · ")
+}
+
+// StringList is a list of strings stored as json
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
+
+func (l *StringList) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	case nil:
+		*l = nil
+		return nil
+	}
+	return errors.New("invalid data type")
+}
+
+// String joins the list for display and use in form inputs
+func (l StringList) String() string {
+	return strings.Join(l, ", ")
+}
+
 type RecipeStep struct {
 	Time         *time.Duration
 	Title        *string
@@ -144,6 +388,9 @@ type Roaster struct {
 	Description string
 	URL         string
 	Icon        string
+	Public      bool
+	// HomeRoast marks the roaster that represents the user themselves for coffees they have roasted
+	HomeRoast bool
 
 	Coffees []Coffee `gorm:"foreignKey:RoasterID"`
 
@@ -151,9 +398,96 @@ type Roaster struct {
 	User   auth.User `gorm:"foreignKey:UserID"`
 }
 
+// UploadOwner implements uploads.Owner.
+func (r Roaster) UploadOwner() (uint, bool) {
+	return r.UserID, r.Public
+}
+
+// UploadFiles implements uploads.Owner.
+func (r Roaster) UploadFiles() []string {
+	return []string{r.Icon}
+}
+
 type FlavourProfile struct {
 	model.SoftDelete
 
 	Name    string
 	Coffees []Coffee `gorm:"many2many:coffee_flavour_profiles;"`
+
+	ParentID *uint `gorm:"index"`
+	Parent   *FlavourProfile
+
+	// Depth is the level of the flavour within the wheel, top level categories have a depth of 0
+	Depth int `gorm:"-"`
+	// Path is the full name of the flavour including its parent categories
+	// eg. Fruity > Berry > Blackberry
+	Path string `gorm:"-"`
+}
+
+// FlavourCategoryStat is the usage of a top level flavour category across a users coffees
+//
+// Usage of any flavour within the category is rolled up into it
+type FlavourCategoryStat struct {
+	Category FlavourProfile
+	// Coffees is the number of coffees with at least one flavour from the category
+	Coffees int
+	// Percentage is the share of all the users coffees that are in the category
+	Percentage int
+	// Rating is the average rating of the rated coffees in the category
+	Rating float64
+
+	rated       uint
+	ratingTotal uint
+}
+
+// OriginStat is the rating summary for all coffees that share a single origin attribute
+type OriginStat struct {
+	Name string
+	// Coffees is the number of coffees with the attribute
+	Coffees int
+	// Rating is the average rating of the rated coffees with the attribute
+	Rating float64
+
+	rated       uint
+	ratingTotal uint
+}
+
+// GroupCoffeeRatings groups the coffees by the values returned from keys and averages their ratings
+//
+// A coffee is counted once in each of the groups it has a key for, this allows a blend to count
+// towards every country in it. The results are ordered with the highest rated first
+func GroupCoffeeRatings(coffees []Coffee, keys func(Coffee) []string) []OriginStat {
+	stats := make(map[string]*OriginStat)
+	for _, c := range coffees {
+		for _, name := range keys(c) {
+			if _, ok := stats[name]; !ok {
+				stats[name] = &OriginStat{Name: name}
+			}
+
+			stats[name].Coffees++
+			if c.Rating > 0 {
+				stats[name].rated++
+				stats[name].ratingTotal += uint(c.Rating)
+			}
+		}
+	}
+
+	result := make([]OriginStat, 0, len(stats))
+	for _, stat := range stats {
+		if stat.rated > 0 {
+			stat.Rating = float64(stat.ratingTotal) / float64(stat.rated)
+		}
+
+		result = append(result, *stat)
+	}
+
+	slices.SortFunc(result, func(a, b OriginStat) int {
+		if a.Rating != b.Rating {
+			return cmp.Compare(b.Rating, a.Rating)
+		}
+
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return result
 }
@@ -0,0 +1,82 @@
+package coffee
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// EspressoParams are the machine settings used when pulling a shot for a recipe
+type EspressoParams struct {
+	// BrewTemperature is the group head temperature in celsius
+	BrewTemperature     float64
+	PreinfusionTime     time.Duration
+	PreinfusionPressure float64
+	PeakPressure        float64
+	// FlowProfile is the name of the profile programmed into the machine
+	FlowProfile string
+}
+
+// Empty checks if none of the espresso parameters have been set
+func (p EspressoParams) Empty() bool {
+	return p == EspressoParams{}
+}
+
+// FilterParams are the brewing settings for filter recipes
+type FilterParams struct {
+	// WaterTemperature is the temperature of the kettle in celsius
+	WaterTemperature float64
+	// BloomWater is the amount of water used for the bloom in grams
+	BloomWater float64
+	BloomTime  time.Duration
+	Pours      PourSchedule
+}
+
+// Empty checks if none of the filter parameters have been set
+func (p FilterParams) Empty() bool {
+	return p.WaterTemperature == 0 && p.BloomWater == 0 && p.BloomTime == 0 && len(p.Pours) == 0
+}
+
+// MilkParams are the details of the milk in a milk drink
+type MilkParams struct {
+	// Volume is the amount of milk in ml before steaming
+	Volume           float64
+	SteamTemperature float64
+	Texture          string
+	// AddIns are any syrups, sauces or powders added to the drink
+	AddIns      string
+	AddInVolume float64
+}
+
+// Empty checks if none of the milk parameters have been set
+func (p MilkParams) Empty() bool {
+	return p == MilkParams{}
+}
+
+// Pour is a single pour in a pour schedule
+type Pour struct {
+	// At is the time from the start of the brew that the pour begins
+	At time.Duration
+	// Water is the total weight of water in the brewer once the pour is finished
+	Water float64
+}
+
+type PourSchedule []Pour
+
+func (s PourSchedule) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+func (s *PourSchedule) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	case nil:
+		*s = nil
+		return nil
+	}
+	return errors.New("invalid data type")
+}
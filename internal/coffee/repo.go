@@ -1,7 +1,12 @@
 package coffee
 
 import (
+	"errors"
+	"slices"
+	"strings"
+
 	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/types"
 	"gorm.io/gorm"
 )
 
@@ -13,18 +18,40 @@ type Repository interface {
 
 	IndexRoastersForUser(*auth.User) []Roaster
 	FindRoaster(uint, ...uint) (*Roaster, error)
+	FindHomeRoaster(*auth.User) (*Roaster, error)
 	SaveRoaster(*Roaster) error
 	DeleteRoaster(*Roaster) error
 
 	IndexFlavourProfiles() []FlavourProfile
+	SeedFlavourProfiles([]FlavourNode) error
+	FlavourCategoryStats(*auth.User) []FlavourCategoryStat
 	FindFlavourProfile(uint) (*FlavourProfile, error)
 	FindFlavourProfiles([]uint) ([]FlavourProfile, error)
 	SaveFlavourProfile(*FlavourProfile) error
 	DeleteFlavourProfile(*FlavourProfile) error
+	MergeFlavourProfiles(from, into *FlavourProfile) error
+	FlavourUsage() map[uint]int
 
 	IndexRecipesForUser(user *auth.User) []Recipe
+	FindRecipe(uint, ...uint) (*Recipe, error)
 	SaveRecipe(*Recipe) error
 	DeleteRecipe(*Recipe) error
+	CountRecipesOfDrink(drinkId uint) int64
+	LinkDrinkTypes() error
+
+	IndexCuppings(coffeeId uint) []Cupping
+	FindCupping(uint, ...uint) (*Cupping, error)
+	SaveCupping(*Cupping) error
+	DeleteCupping(*Cupping) error
+
+	IndexMilkTypes(*auth.User) []MilkType
+	FindOrCreateMilkType(string, *auth.User) (*MilkType, error)
+	SeedMilkTypes([]string) error
+
+	IndexShots(recipeId uint) []Shot
+	FindShot(uint, ...uint) (*Shot, error)
+	SaveShot(*Shot) error
+	DeleteShot(*Shot) error
 }
 
 type SqliteRepository struct {
@@ -41,7 +68,185 @@ func (r SqliteRepository) IndexFlavourProfiles() []FlavourProfile {
 
 	r.db.Order("name ASC").Find(&flavours)
 
-	return flavours
+	return SortFlavourTree(flavours)
+}
+
+// SeedFlavourProfiles implements Repository.
+//
+// The wheel is only seeded once, after that it is up to the admins to manage. Any existing top level
+// flavours that match the name of a flavour in the wheel are moved into place rather than being
+// duplicated
+func (r SqliteRepository) SeedFlavourProfiles(wheel []FlavourNode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var seeded int64
+		if err := tx.Model(&FlavourProfile{}).Where("parent_id IS NOT NULL").Count(&seeded).Error; err != nil {
+			return err
+		}
+
+		if seeded > 0 {
+			return nil
+		}
+
+		var existing []FlavourProfile
+		if err := tx.Find(&existing).Error; err != nil {
+			return err
+		}
+
+		unclaimed := make(map[string]*FlavourProfile, len(existing))
+		for i := range existing {
+			unclaimed[strings.ToLower(existing[i].Name)] = &existing[i]
+		}
+
+		var seed func(nodes []FlavourNode, parentId *uint) error
+		seed = func(nodes []FlavourNode, parentId *uint) error {
+			for _, node := range nodes {
+				flavour, ok := unclaimed[strings.ToLower(node.Name)]
+				if ok {
+					delete(unclaimed, strings.ToLower(node.Name))
+				} else {
+					flavour = &FlavourProfile{Name: node.Name}
+				}
+
+				flavour.ParentID = parentId
+				if err := tx.Save(flavour).Error; err != nil {
+					return err
+				}
+
+				if err := seed(node.Children, &flavour.ID); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+
+		return seed(wheel, nil)
+	})
+}
+
+// MergeFlavourProfiles implements Repository.
+//
+// Coffees tagged with from are retagged with into and any children of from are moved under into
+// before from is deleted
+func (r SqliteRepository) MergeFlavourProfiles(from, into *FlavourProfile) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			INSERT INTO coffee_flavour_profiles (coffee_id, flavour_profile_id)
+			SELECT coffee_id, ? FROM coffee_flavour_profiles
+			WHERE flavour_profile_id = ?
+			AND coffee_id NOT IN (
+				SELECT coffee_id FROM coffee_flavour_profiles WHERE flavour_profile_id = ?
+			)
+		`, into.ID, from.ID, into.ID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec("DELETE FROM coffee_flavour_profiles WHERE flavour_profile_id = ?", from.ID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&FlavourProfile{}).
+			Where("parent_id = ?", from.ID).
+			Update("parent_id", into.ID).
+			Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(from).Error
+	})
+}
+
+// FlavourUsage implements Repository.
+func (r SqliteRepository) FlavourUsage() map[uint]int {
+	var rows []struct {
+		FlavourProfileID uint
+		Coffees          int
+	}
+
+	r.db.Table("coffee_flavour_profiles").
+		Select("coffee_flavour_profiles.flavour_profile_id, COUNT(*) AS coffees").
+		Joins("JOIN coffees ON coffees.id = coffee_flavour_profiles.coffee_id").
+		Where("coffees.deleted_at IS NULL").
+		Group("coffee_flavour_profiles.flavour_profile_id").
+		Scan(&rows)
+
+	usage := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		usage[row.FlavourProfileID] = row.Coffees
+	}
+
+	return usage
+}
+
+// FlavourCategoryStats implements Repository.
+func (r SqliteRepository) FlavourCategoryStats(user *auth.User) []FlavourCategoryStat {
+	var rows []struct {
+		CoffeeID         uint
+		FlavourProfileID uint
+		Rating           uint8
+	}
+
+	r.db.Table("coffee_flavour_profiles").
+		Select("coffees.id AS coffee_id, coffee_flavour_profiles.flavour_profile_id, coffees.rating").
+		Joins("JOIN coffees ON coffees.id = coffee_flavour_profiles.coffee_id").
+		Where("coffees.user_id = ? AND coffees.deleted_at IS NULL", user.ID).
+		Scan(&rows)
+
+	flavours := r.IndexFlavourProfiles()
+	categories := FlavourCategories(flavours)
+
+	stats := make(map[uint]*FlavourCategoryStat)
+	counted := make(map[[2]uint]bool)
+	for _, row := range rows {
+		category, ok := categories[row.FlavourProfileID]
+		if !ok {
+			continue
+		}
+
+		// a coffee with several flavours from the same category is only counted once
+		if counted[[2]uint{category, row.CoffeeID}] {
+			continue
+		}
+		counted[[2]uint{category, row.CoffeeID}] = true
+
+		if _, ok := stats[category]; !ok {
+			stats[category] = &FlavourCategoryStat{}
+		}
+
+		stats[category].Coffees++
+		if row.Rating > 0 {
+			stats[category].rated++
+			stats[category].ratingTotal += uint(row.Rating)
+		}
+	}
+
+	var coffees int64
+	r.db.Model(&Coffee{}).Where("user_id = ?", user.ID).Count(&coffees)
+
+	var result []FlavourCategoryStat
+	for _, flavour := range flavours {
+		stat, ok := stats[flavour.ID]
+		if !ok || flavour.Depth != 0 {
+			continue
+		}
+
+		stat.Category = flavour
+		stat.Percentage = stat.Coffees * 100 / int(max(coffees, 1))
+		if stat.rated > 0 {
+			stat.Rating = float64(stat.ratingTotal) / float64(stat.rated)
+		}
+
+		result = append(result, *stat)
+	}
+
+	slices.SortStableFunc(result, func(a, b FlavourCategoryStat) int {
+		return b.Coffees - a.Coffees
+	})
+
+	return result
 }
 
 // IndexCoffeesForUser implements Repository.
@@ -50,6 +255,7 @@ func (r SqliteRepository) IndexCoffeesForUser(user *auth.User) []Coffee {
 
 	r.db.Preload("Roaster").
 		Preload("Recipes").
+		Preload("Components").
 		Where("user_id = ?", user.ID).
 		Order("name ASC").
 		Find(&coffees)
@@ -73,8 +279,12 @@ func (r SqliteRepository) IndexRecipesForUser(user *auth.User) []Recipe {
 	var recipes []Recipe
 
 	r.db.Preload("Coffee").
-		Preload("Brewer").
+		Preload("Coffee.Components").
+		Preload("Drink.Brewers").
+		Preload("Brewer.Type").
 		Preload("Basket").
+		Preload("Equipment").
+		Preload("MilkType").
 		Where("user_id = ?", user.ID).
 		Order("name ASC").
 		Find(&recipes)
@@ -88,7 +298,13 @@ func (r SqliteRepository) FindCoffee(id uint, userId ...uint) (*Coffee, error) {
 
 	tx := r.db.Preload("Roaster").
 		Preload("Flavours").
-		Preload("Recipes")
+		Preload("Recipes").
+		Preload("Recipes.Drink.Brewers").
+		Preload("Recipes.Brewer.Type").
+		Preload("Recipes.Basket").
+		Preload("Recipes.Equipment").
+		Preload("Recipes.MilkType").
+		Preload("Components")
 
 	if len(userId) > 0 {
 		tx = tx.Where("user_id = ?", userId[0])
@@ -101,6 +317,28 @@ func (r SqliteRepository) FindCoffee(id uint, userId ...uint) (*Coffee, error) {
 	return &coffee, nil
 }
 
+// FindRecipe implements Repository.
+func (r SqliteRepository) FindRecipe(id uint, userId ...uint) (*Recipe, error) {
+	var recipe Recipe
+
+	tx := r.db.Preload("Coffee").
+		Preload("Drink.Brewers").
+		Preload("Brewer.Type").
+		Preload("Basket").
+		Preload("Equipment").
+		Preload("MilkType")
+
+	if len(userId) > 0 {
+		tx = tx.Where("user_id = ?", userId[0])
+	}
+
+	if err := tx.First(&recipe, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &recipe, nil
+}
+
 // FindFlavourProfile implements Repository.
 func (r SqliteRepository) FindFlavourProfile(id uint) (*FlavourProfile, error) {
 	var flavour FlavourProfile
@@ -139,6 +377,27 @@ func (r SqliteRepository) FindRoaster(id uint, userId ...uint) (*Roaster, error)
 	return &roaster, nil
 }
 
+// FindHomeRoaster implements Repository.
+//
+// The roaster is created the first time the user finishes a home roast
+func (r SqliteRepository) FindHomeRoaster(user *auth.User) (*Roaster, error) {
+	roaster := Roaster{
+		Name:        user.Name,
+		Description: "Home roasted",
+		HomeRoast:   true,
+		UserID:      user.ID,
+	}
+
+	err := r.db.Where("user_id = ? AND home_roast = ?", user.ID, true).
+		FirstOrCreate(&roaster).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &roaster, nil
+}
+
 // DeleteCoffee implements Repository.
 func (r SqliteRepository) DeleteCoffee(coffee *Coffee) error {
 	return r.db.Delete(coffee).Error
@@ -170,6 +429,12 @@ func (r SqliteRepository) SaveCoffee(coffee *Coffee) error {
 		if err = tx.Model(&coffee).Association("Flavours").Replace(coffee.Flavours); err != nil {
 			return err
 		}
+
+		// components are replaced wholesale on every save, they have no identity of their own
+		err = tx.Unscoped().Where("coffee_id = ?", coffee.ID).Delete(&BlendComponent{}).Error
+		if err != nil {
+			return err
+		}
 	}
 
 	err = tx.Save(coffee).Error
@@ -186,12 +451,217 @@ func (r SqliteRepository) SaveRoaster(roaster *Roaster) error {
 	return r.db.Save(roaster).Error
 }
 
+// SaveRecipe implements Repository.
+//
+// The drink, brewer, basket and milk type are left out so that a stale preloaded association does not
+// overwrite its id when it is changed. The recipes equipment is replaced with whatever it is set to
 func (r SqliteRepository) SaveRecipe(recipe *Recipe) error {
-	return r.db.Save(recipe).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Omit("Drink", "Brewer", "Basket", "MilkType", "Equipment").Save(recipe).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(recipe).Association("Equipment").Replace(recipe.Equipment)
+	})
 }
 
 func (r SqliteRepository) DeleteRecipe(recipe *Recipe) error {
 	return r.db.Delete(recipe).Error
 }
 
+// CountRecipesOfDrink implements Repository.
+func (r SqliteRepository) CountRecipesOfDrink(drinkId uint) int64 {
+	var count int64
+
+	r.db.Model(&Recipe{}).Where("drink_id = ?", drinkId).Count(&count)
+
+	return count
+}
+
+// LinkDrinkTypes implements Repository.
+//
+// Recipes saved before drink types were stored in the database only have the name of their drink,
+// these are pointed at the matching type. Names that do not match any type are added as a type for
+// the owner of the recipe so nothing is lost
+func (r SqliteRepository) LinkDrinkTypes() error {
+	if !r.db.Migrator().HasColumn(&Recipe{}, "drink") {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var unlinked []struct {
+			UserID uint
+			Drink  string
+		}
+
+		err := tx.Model(&Recipe{}).
+			Unscoped().
+			Distinct("user_id", "drink").
+			Where("drink_id IS NULL AND drink IS NOT NULL AND drink != ''").
+			Scan(&unlinked).
+			Error
+		if err != nil {
+			return err
+		}
+
+		for _, recipe := range unlinked {
+			var drinkType types.DrinkType
+			err := tx.Where("user_id IS NULL OR user_id = ?", recipe.UserID).
+				Where("LOWER(name) = LOWER(?)", recipe.Drink).
+				Order("user_id IS NOT NULL").
+				Attrs(types.DrinkType{Name: recipe.Drink, UserID: &recipe.UserID}).
+				FirstOrCreate(&drinkType).
+				Error
+			if err != nil {
+				return err
+			}
+
+			err = tx.Model(&Recipe{}).
+				Unscoped().
+				Where("drink_id IS NULL AND user_id = ? AND drink = ?", recipe.UserID, recipe.Drink).
+				UpdateColumn("drink_id", drinkType.ID).
+				Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// IndexCuppings implements Repository.
+func (r SqliteRepository) IndexCuppings(coffeeId uint) []Cupping {
+	var cuppings []Cupping
+
+	r.db.Where("coffee_id = ?", coffeeId).
+		Order("cupped_at DESC, id DESC").
+		Find(&cuppings)
+
+	return cuppings
+}
+
+// FindCupping implements Repository.
+func (r SqliteRepository) FindCupping(id uint, userId ...uint) (*Cupping, error) {
+	var cupping Cupping
+
+	tx := r.db
+	if len(userId) > 0 {
+		tx = tx.Where("user_id = ?", userId[0])
+	}
+
+	if err := tx.First(&cupping, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &cupping, nil
+}
+
+// SaveCupping implements Repository.
+func (r SqliteRepository) SaveCupping(cupping *Cupping) error {
+	return r.db.Save(cupping).Error
+}
+
+// DeleteCupping implements Repository.
+func (r SqliteRepository) DeleteCupping(cupping *Cupping) error {
+	return r.db.Delete(cupping).Error
+}
+
+// IndexMilkTypes implements Repository.
+//
+// This includes the built in milk types along with any the user has added
+func (r SqliteRepository) IndexMilkTypes(user *auth.User) []MilkType {
+	var milkTypes []MilkType
+
+	r.db.Where("user_id IS NULL OR user_id = ?", user.ID).
+		Order("user_id IS NOT NULL, name ASC").
+		Find(&milkTypes)
+
+	return milkTypes
+}
+
+// FindOrCreateMilkType implements Repository.
+//
+// Names are matched case insensitively against the milk types visible to the user, if there is no
+// match then a new milk type is added for the user
+func (r SqliteRepository) FindOrCreateMilkType(name string, user *auth.User) (*MilkType, error) {
+	var milkType MilkType
+
+	err := r.db.Where("user_id IS NULL OR user_id = ?", user.ID).
+		Where("LOWER(name) = LOWER(?)", name).
+		Order("user_id IS NOT NULL").
+		First(&milkType).
+		Error
+	if err == nil {
+		return &milkType, nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	milkType = MilkType{Name: name, UserID: &user.ID}
+	if err := r.db.Create(&milkType).Error; err != nil {
+		return nil, err
+	}
+
+	return &milkType, nil
+}
+
+// SeedMilkTypes implements Repository.
+//
+// Any of the built in milk types that are missing are added, existing ones are left as they are
+func (r SqliteRepository) SeedMilkTypes(names []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, name := range names {
+			err := tx.Where("user_id IS NULL AND name = ?", name).
+				FirstOrCreate(&MilkType{Name: name}).
+				Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// IndexShots implements Repository.
+func (r SqliteRepository) IndexShots(recipeId uint) []Shot {
+	var shots []Shot
+
+	r.db.Where("recipe_id = ?", recipeId).
+		Order("pulled_at DESC, id DESC").
+		Find(&shots)
+
+	return shots
+}
+
+// FindShot implements Repository.
+func (r SqliteRepository) FindShot(id uint, userId ...uint) (*Shot, error) {
+	var shot Shot
+
+	tx := r.db
+	if len(userId) > 0 {
+		tx = tx.Where("user_id = ?", userId[0])
+	}
+
+	if err := tx.First(&shot, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &shot, nil
+}
+
+// SaveShot implements Repository.
+func (r SqliteRepository) SaveShot(shot *Shot) error {
+	return r.db.Save(shot).Error
+}
+
+// DeleteShot implements Repository.
+func (r SqliteRepository) DeleteShot(shot *Shot) error {
+	return r.db.Delete(shot).Error
+}
+
 var _ Repository = (*SqliteRepository)(nil)
@@ -0,0 +1,98 @@
+package coffee
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestRepo(t *testing.T) (Repository, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(Coffee{}, FlavourProfile{}); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewSqliteRepo(db), db
+}
+
+func TestMergeFlavourProfiles(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		// flavours tagged on each coffee before the merge, 1 is merged into 2
+		before [][]uint
+		after  [][]uint
+	}{
+		{"from only", [][]uint{{1}}, [][]uint{{2}}},
+		{"into only", [][]uint{{2}}, [][]uint{{2}}},
+		{"both", [][]uint{{1, 2}}, [][]uint{{2}}},
+		{"other flavours kept", [][]uint{{1, 3}, {2, 3}}, [][]uint{{2, 3}, {2, 3}}},
+		{"untagged", [][]uint{{}, {1}}, [][]uint{{}, {2}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, db := newTestRepo(t)
+
+			// Sweet(2) > Honey(4), Citrus(1) > Lemon(5) is moved under Sweet with the merge
+			flavours := []FlavourProfile{
+				testFlavour(1, 0, "Citrus"),
+				testFlavour(2, 0, "Sweet"),
+				testFlavour(3, 0, "Floral"),
+				testFlavour(4, 2, "Honey"),
+				testFlavour(5, 1, "Lemon"),
+			}
+			if err := db.Create(&flavours).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			coffees := make([]Coffee, len(tc.before))
+			for i, ids := range tc.before {
+				coffees[i].Name = "coffee"
+				for _, id := range ids {
+					coffees[i].Flavours = append(coffees[i].Flavours, flavours[id-1])
+				}
+			}
+			if err := db.Create(&coffees).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			if err := repo.MergeFlavourProfiles(&flavours[0], &flavours[1]); err != nil {
+				t.Fatalf("merge: %s", err)
+			}
+
+			for i, coffee := range coffees {
+				var ids []uint
+				err := db.Table("coffee_flavour_profiles").
+					Where("coffee_id = ?", coffee.ID).
+					Order("flavour_profile_id").
+					Pluck("flavour_profile_id", &ids).
+					Error
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !slices.Equal(ids, tc.after[i]) && !(len(ids) == 0 && len(tc.after[i]) == 0) {
+					t.Errorf("coffee %d: got %v, want %v", i, ids, tc.after[i])
+				}
+			}
+
+			if _, err := repo.FindFlavourProfile(1); err == nil {
+				t.Error("expected the merged flavour to be deleted")
+			}
+
+			lemon, err := repo.FindFlavourProfile(5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lemon.ParentID == nil || *lemon.ParentID != 2 {
+				t.Errorf("child parent: got %v", lemon.ParentID)
+			}
+		})
+	}
+}
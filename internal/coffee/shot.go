@@ -0,0 +1,539 @@
+package coffee
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/database/model"
+)
+
+// Shot is the telemetry recorded by an espresso machine while pulling a shot for a recipe
+type Shot struct {
+	model.SoftDelete
+
+	// Source is the format the shot was imported from
+	Source   string
+	Filename string
+	PulledAt time.Time
+	Samples  ShotSamples
+
+	RecipeID uint `gorm:"index"`
+
+	UserID uint
+	User   auth.User
+}
+
+// Duration is the time from the start of the shot to the last sample
+func (s Shot) Duration() time.Duration {
+	if len(s.Samples) == 0 {
+		return 0
+	}
+
+	return time.Duration(s.Samples[len(s.Samples)-1].Time * float64(time.Second)).Round(100 * time.Millisecond)
+}
+
+// Yield is the final weight of the shot in the cup
+func (s Shot) Yield() float64 {
+	var yield float64
+	for _, sample := range s.Samples {
+		yield = max(yield, sample.Weight)
+	}
+
+	return yield
+}
+
+// PeakPressure is the highest pressure reached during the shot
+func (s Shot) PeakPressure() float64 {
+	var peak float64
+	for _, sample := range s.Samples {
+		peak = max(peak, sample.Pressure)
+	}
+
+	return peak
+}
+
+// ShotSample is a single reading taken during a shot
+//
+// Time is in seconds, pressure in bar, flow in ml/s, weight in grams and temperature in celsius
+type ShotSample struct {
+	Time        float64 `json:"t"`
+	Pressure    float64 `json:"p"`
+	Flow        float64 `json:"f"`
+	Weight      float64 `json:"w"`
+	Temperature float64 `json:"c"`
+}
+
+type ShotSamples []ShotSample
+
+func (s ShotSamples) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+func (s *ShotSamples) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	}
+	return errors.New("invalid data type")
+}
+
+// ParseShot reads the telemetry for a shot from an exported file
+//
+// The format is picked from the file extension:
+//   - .shot files are the tcl formatted history files saved by the Decent app
+//   - .json files can be either a Decent/Visualizer export or a Gaggiuino shot
+//   - .csv files are a generic time series with a header row naming the time, pressure, flow,
+//     weight and temperature columns
+func ParseShot(filename string, data []byte) (*Shot, error) {
+	var (
+		shot *Shot
+		err  error
+	)
+
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".shot":
+		shot, err = parseDecentTcl(data)
+	case ".json":
+		shot, err = parseShotJson(data)
+	case ".csv":
+		shot, err = parseShotCsv(data)
+	default:
+		return nil, errors.New("shots must be a .shot, .json or .csv file")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(shot.Samples) < 2 {
+		return nil, errors.New("the shot does not contain any readings")
+	}
+
+	if err := shot.Samples.validate(); err != nil {
+		return nil, err
+	}
+
+	shot.Filename = filename
+	if shot.PulledAt.IsZero() {
+		shot.PulledAt = time.Now()
+	}
+
+	return shot, nil
+}
+
+// shotSeries holds the individual columns of a shot before they are zipped into samples
+type shotSeries struct {
+	time, pressure, flow, weight, temperature []float64
+}
+
+// samples zips the series into samples, the time series decides the number of samples and any
+// other series that is short is padded with zero readings
+func (s shotSeries) samples() ShotSamples {
+	at := func(series []float64, i int) float64 {
+		if i >= len(series) || math.IsNaN(series[i]) || math.IsInf(series[i], 0) {
+			return 0
+		}
+		return math.Round(series[i]*100) / 100
+	}
+
+	samples := make(ShotSamples, 0, len(s.time))
+	for i := range s.time {
+		samples = append(samples, ShotSample{
+			Time:        at(s.time, i),
+			Pressure:    at(s.pressure, i),
+			Flow:        at(s.flow, i),
+			Weight:      at(s.weight, i),
+			Temperature: at(s.temperature, i),
+		})
+	}
+
+	return samples
+}
+
+// shotLimits are the ranges that each reading must fall within for a shot to be accepted, anything
+// outside of them is assumed to be a corrupt or mislabelled file
+//
+// The lower limits leave a little room for sensor noise and scales that drift below zero
+var shotLimits = []struct {
+	name     string
+	min, max float64
+	value    func(ShotSample) float64
+}{
+	{"time", 0, 600, func(s ShotSample) float64 { return s.Time }},
+	{"pressure", -1, 20, func(s ShotSample) float64 { return s.Pressure }},
+	{"flow", -1, 20, func(s ShotSample) float64 { return s.Flow }},
+	{"weight", -10, 500, func(s ShotSample) float64 { return s.Weight }},
+	{"temperature", 0, 120, func(s ShotSample) float64 { return s.Temperature }},
+}
+
+// validate checks that every reading is within the realistic limits for a shot
+func (s ShotSamples) validate() error {
+	for _, limit := range shotLimits {
+		for _, sample := range s {
+			if v := limit.value(sample); v < limit.min || v > limit.max {
+				return fmt.Errorf("the shot contains a %s reading of %g, outside of %g to %g", limit.name, v, limit.min, limit.max)
+			}
+		}
+	}
+
+	return nil
+}
+
+var decentTclList = regexp.MustCompile(`(?m)^\s*(espresso_\w+|clock)\s+\{?([^}\n]*)\}?`)
+
+// parseDecentTcl reads the history files the Decent app saves after every shot
+//
+// Each series is stored as a tcl list on its own line, eg: espresso_pressure {0.0 0.4 1.2}
+func parseDecentTcl(data []byte) (*Shot, error) {
+	values := make(map[string]string)
+	for _, match := range decentTclList.FindAllSubmatch(data, -1) {
+		if _, ok := values[string(match[1])]; !ok {
+			values[string(match[1])] = string(match[2])
+		}
+	}
+
+	fields := func(key string) []float64 {
+		var floats []float64
+		for _, field := range strings.Fields(values[key]) {
+			f, _ := strconv.ParseFloat(field, 64)
+			floats = append(floats, f)
+		}
+		return floats
+	}
+
+	shot := Shot{Source: "Decent"}
+	shot.Samples = shotSeries{
+		time:        fields("espresso_elapsed"),
+		pressure:    fields("espresso_pressure"),
+		flow:        fields("espresso_flow"),
+		weight:      fields("espresso_weight"),
+		temperature: fields("espresso_temperature_basket"),
+	}.samples()
+
+	if clock, err := strconv.ParseInt(strings.TrimSpace(values["clock"]), 10, 64); err == nil {
+		shot.PulledAt = time.Unix(clock, 0)
+	}
+
+	return &shot, nil
+}
+
+// parseShotJson reads the json exports from the Decent app, Visualizer and Gaggiuino
+func parseShotJson(data []byte) (*Shot, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, errors.New("not a valid json shot file")
+	}
+
+	shot := Shot{}
+	switch {
+	case doc["datapoints"] != nil:
+		// gaggiuino stores its readings multiplied by 10 to keep them as ints
+		points := jsonObject(doc["datapoints"])
+		scaled := func(key string) []float64 {
+			floats := jsonFloats(points[key])
+			for i := range floats {
+				floats[i] /= 10
+			}
+			return floats
+		}
+
+		shot.Source = "Gaggiuino"
+		shot.Samples = shotSeries{
+			time:        scaled("timeInShot"),
+			pressure:    scaled("pressure"),
+			flow:        scaled("pumpFlow"),
+			weight:      scaled("shotWeight"),
+			temperature: scaled("temperature"),
+		}.samples()
+
+		if ts := jsonFloats([]any{doc["timestamp"]}); len(ts) > 0 && ts[0] > 0 {
+			shot.PulledAt = time.Unix(int64(ts[0]), 0)
+		}
+
+	case doc["timeframe"] != nil:
+		// visualizer keeps the original decent series names
+		series := jsonObject(doc["data"])
+		shot.Source = "Decent"
+		shot.Samples = shotSeries{
+			time:        jsonFloats(doc["timeframe"]),
+			pressure:    jsonFloats(series["espresso_pressure"]),
+			flow:        jsonFloats(series["espresso_flow"]),
+			weight:      jsonFloats(series["espresso_weight"]),
+			temperature: jsonFloats(series["espresso_temperature_basket"]),
+		}.samples()
+
+	case doc["elapsed"] != nil:
+		shot.Source = "Decent"
+		shot.Samples = shotSeries{
+			time:        jsonFloats(doc["elapsed"]),
+			pressure:    jsonFloats(jsonObject(doc["pressure"])["pressure"]),
+			flow:        jsonFloats(jsonObject(doc["flow"])["flow"]),
+			weight:      jsonFloats(jsonObject(doc["totals"])["weight"]),
+			temperature: jsonFloats(jsonObject(doc["temperature"])["basket"]),
+		}.samples()
+
+		if clock := jsonFloats([]any{doc["clock"]}); len(clock) > 0 && clock[0] > 0 {
+			shot.PulledAt = time.Unix(int64(clock[0]), 0)
+		}
+
+	default:
+		return nil, errors.New("the json file is not a recognised shot format")
+	}
+
+	return &shot, nil
+}
+
+func jsonObject(value any) map[string]any {
+	obj, _ := value.(map[string]any)
+	return obj
+}
+
+// jsonFloats converts a json list into floats, the decent app writes its numbers as strings so
+// both are accepted
+func jsonFloats(value any) []float64 {
+	list, _ := value.([]any)
+
+	floats := make([]float64, 0, len(list))
+	for _, v := range list {
+		switch n := v.(type) {
+		case float64:
+			floats = append(floats, n)
+		case string:
+			f, _ := strconv.ParseFloat(strings.TrimSpace(n), 64)
+			floats = append(floats, f)
+		default:
+			floats = append(floats, 0)
+		}
+	}
+
+	return floats
+}
+
+// parseShotCsv reads a generic csv time series
+//
+// Columns are matched by their header so they can be in any order and extra columns are ignored,
+// a time column is required and is assumed to be in seconds unless its header mentions ms
+func parseShotCsv(data []byte) (*Shot, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil || len(rows) < 2 {
+		return nil, errors.New("not a valid csv shot file")
+	}
+
+	columns := map[string]int{}
+	var millis bool
+	for i, header := range rows[0] {
+		header = strings.ToLower(strings.TrimSpace(header))
+		for _, name := range []string{"time", "elapsed", "pressure", "flow", "weight", "temp"} {
+			if !strings.Contains(header, name) {
+				continue
+			}
+			if name == "elapsed" {
+				name = "time"
+			}
+			if _, ok := columns[name]; ok {
+				continue
+			}
+
+			columns[name] = i
+			if name == "time" {
+				millis = strings.Contains(header, "ms")
+			}
+		}
+	}
+
+	if _, ok := columns["time"]; !ok {
+		return nil, errors.New("the csv file needs a time column")
+	}
+
+	column := func(name string) []float64 {
+		i, ok := columns[name]
+		if !ok {
+			return nil
+		}
+
+		floats := make([]float64, 0, len(rows)-1)
+		for _, row := range rows[1:] {
+			var f float64
+			if i < len(row) {
+				f, _ = strconv.ParseFloat(strings.TrimSpace(row[i]), 64)
+			}
+			floats = append(floats, f)
+		}
+		return floats
+	}
+
+	series := shotSeries{
+		time:        column("time"),
+		pressure:    column("pressure"),
+		flow:        column("flow"),
+		weight:      column("weight"),
+		temperature: column("temp"),
+	}
+	if millis {
+		for i := range series.time {
+			series.time[i] /= 1000
+		}
+	}
+
+	return &Shot{Source: "CSV", Samples: series.samples()}, nil
+}
+
+const (
+	shotChartWidth  = 400
+	shotChartHeight = 140
+	shotPadLeft     = 32
+	shotPadBottom   = 20
+	shotPadTop      = 8
+	shotPadEnd      = 8
+	// shotMaxTicks is the most grid lines drawn on any axis, the step between ticks is widened to
+	// keep within it
+	shotMaxTicks = 12
+)
+
+// shotColours are the stroke classes given to each shot in turn so they can be told apart
+var shotColours = []string{
+	"stroke-primary",
+	"stroke-secondary",
+	"stroke-accent",
+	"stroke-info",
+	"stroke-success",
+	"stroke-warning",
+}
+
+// ShotTick is a labelled grid line on one of the chart axes
+type ShotTick struct {
+	Pos   float64
+	Label string
+}
+
+// ShotLine is the curve for a single shot on a graph
+type ShotLine struct {
+	Points string
+	Colour string
+}
+
+// ShotGraph is a single measurement plotted for every shot
+type ShotGraph struct {
+	Name   string
+	Unit   string
+	YTicks []ShotTick
+	Lines  []ShotLine
+}
+
+// ShotLegend labels the colour used for a shot
+type ShotLegend struct {
+	Shot   Shot
+	Colour string
+}
+
+// ShotChart holds the precomputed geometry for overlaying the pressure, flow and weight of shots
+// as svg line graphs
+//
+// All the graphs share the same time axis so shots can be compared across them
+type ShotChart struct {
+	Width  int
+	Height int
+	Left   float64
+	Right  float64
+	Top    float64
+	Bottom float64
+
+	XTicks []ShotTick
+	Graphs []ShotGraph
+	Legend []ShotLegend
+}
+
+// NewShotChart builds the overlaid graphs for the shots
+func NewShotChart(shots []Shot) ShotChart {
+	chart := ShotChart{
+		Width:  shotChartWidth,
+		Height: shotChartHeight,
+		Left:   shotPadLeft,
+		Right:  shotChartWidth - shotPadEnd,
+		Top:    shotPadTop,
+		Bottom: shotChartHeight - shotPadBottom,
+	}
+
+	if len(shots) == 0 {
+		return chart
+	}
+
+	var duration float64
+	for i, shot := range shots {
+		duration = max(duration, shot.Duration().Seconds())
+		chart.Legend = append(chart.Legend, ShotLegend{shot, shotColours[i%len(shotColours)]})
+	}
+	xMax := math.Max(math.Ceil(duration/10)*10, 10)
+
+	x := func(seconds float64) float64 {
+		return math.Round((chart.Left+seconds/xMax*(chart.Right-chart.Left))*10) / 10
+	}
+	xStep := math.Max(10, math.Ceil(xMax/shotMaxTicks/10)*10)
+	for i := 0; i <= shotMaxTicks && float64(i)*xStep <= xMax; i++ {
+		s := float64(i) * xStep
+		chart.XTicks = append(chart.XTicks, ShotTick{x(s), fmt.Sprint(s)})
+	}
+
+	for _, graph := range []struct {
+		name  string
+		unit  string
+		step  float64
+		value func(ShotSample) float64
+	}{
+		{"Pressure", "bar", 3, func(s ShotSample) float64 { return s.Pressure }},
+		{"Flow", "ml/s", 2, func(s ShotSample) float64 { return s.Flow }},
+		{"Weight", "g", 10, func(s ShotSample) float64 { return s.Weight }},
+	} {
+		var peak float64
+		for _, shot := range shots {
+			for _, sample := range shot.Samples {
+				peak = max(peak, graph.value(sample))
+			}
+		}
+		if peak == 0 {
+			// the machine did not record this measurement
+			continue
+		}
+		yMax := math.Ceil(peak/graph.step) * graph.step
+
+		y := func(v float64) float64 {
+			return math.Round((chart.Bottom-v/yMax*(chart.Bottom-chart.Top))*10) / 10
+		}
+
+		g := ShotGraph{Name: graph.name, Unit: graph.unit}
+		yStep := math.Max(graph.step, math.Ceil(yMax/shotMaxTicks/graph.step)*graph.step)
+		for i := 0; i <= shotMaxTicks && float64(i)*yStep <= yMax; i++ {
+			v := float64(i) * yStep
+			g.YTicks = append(g.YTicks, ShotTick{y(v), fmt.Sprint(v)})
+		}
+
+		for i, shot := range shots {
+			points := make([]string, 0, len(shot.Samples))
+			for _, sample := range shot.Samples {
+				points = append(points, fmt.Sprintf("%.1f,%.1f", x(sample.Time), y(max(graph.value(sample), 0))))
+			}
+			g.Lines = append(g.Lines, ShotLine{strings.Join(points, " "), shotColours[i%len(shotColours)]})
+		}
+
+		chart.Graphs = append(chart.Graphs, g)
+	}
+
+	return chart
+}
@@ -0,0 +1,114 @@
+package coffee
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testDecentShot = `clock 1700000000
+espresso_elapsed {0.0 1.0 2.0 3.0}
+espresso_pressure {0.0 2.5 9.0 8.8}
+espresso_flow {0.0 1.5 2.0 2.1}
+espresso_weight {0.0 0.0 10.2 20.4}
+espresso_temperature_basket {90.0 92.0 93.0 93.1}
+`
+
+const testGaggiuinoJson = `{"timestamp": 1700000000, "datapoints": {
+	"timeInShot": [0, 10, 20, 30],
+	"pressure": [0, 25, 90, 88],
+	"pumpFlow": [0, 15, 20, 21],
+	"shotWeight": [0, 0, 102, 204],
+	"temperature": [900, 920, 930, 931]
+}}`
+
+const testVisualizerJson = `{"timeframe": ["0.0", "1.0", "2.0", "3.0"], "data": {
+	"espresso_pressure": ["0.0", "2.5", "9.0", "8.8"],
+	"espresso_flow": ["0.0", "1.5", "2.0", "2.1"],
+	"espresso_weight": ["0.0", "0.0", "10.2", "20.4"],
+	"espresso_temperature_basket": ["90.0", "92.0", "93.0", "93.1"]
+}}`
+
+const testDecentJson = `{"clock": 1700000000, "elapsed": [0.0, 1.0, 2.0, 3.0],
+	"pressure": {"pressure": [0.0, 2.5, 9.0, 8.8]},
+	"flow": {"flow": [0.0, 1.5, 2.0, 2.1]},
+	"totals": {"weight": [0.0, 0.0, 10.2, 20.4]},
+	"temperature": {"basket": [90.0, 92.0, 93.0, 93.1]}
+}`
+
+const testCsv = `Time (ms),Pressure,Flow,Weight,Temperature
+0,0.0,0.0,0.0,90.0
+1000,2.5,1.5,0.0,92.0
+2000,9.0,2.0,10.2,93.0
+3000,8.8,2.1,20.4,93.1
+`
+
+func TestParseShot(t *testing.T) {
+	for _, tc := range []struct {
+		filename string
+		data     string
+		source   string
+		pulled   bool
+	}{
+		{"history.shot", testDecentShot, "Decent", true},
+		{"gaggiuino.json", testGaggiuinoJson, "Gaggiuino", true},
+		{"visualizer.json", testVisualizerJson, "Decent", false},
+		{"decent.json", testDecentJson, "Decent", true},
+		{"shot.csv", testCsv, "CSV", false},
+	} {
+		shot, err := ParseShot(tc.filename, []byte(tc.data))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.filename, err)
+			continue
+		}
+
+		if shot.Source != tc.source {
+			t.Errorf("%s: source: got %q", tc.filename, shot.Source)
+		}
+		if len(shot.Samples) != 4 || shot.Duration() != 3*time.Second {
+			t.Errorf("%s: samples: got %v", tc.filename, shot.Samples)
+		}
+		if shot.PeakPressure() != 9 || shot.Yield() != 20.4 {
+			t.Errorf("%s: got pressure %v yield %v", tc.filename, shot.PeakPressure(), shot.Yield())
+		}
+		if tc.pulled && !shot.PulledAt.Equal(time.Unix(1700000000, 0)) {
+			t.Errorf("%s: pulled at: got %s", tc.filename, shot.PulledAt)
+		}
+	}
+}
+
+func TestParseShotOutOfRange(t *testing.T) {
+	for name, data := range map[string]string{
+		"time":        "time,pressure\n0,1\n1e12,1\n",
+		"pressure":    "time,pressure\n0,1\n1,1e12\n",
+		"negative":    "time,pressure\n0,1\n1,-50\n",
+		"flow":        "time,flow\n0,1\n1,100\n",
+		"weight":      "time,weight\n0,1\n1,5000\n",
+		"temperature": "time,temp\n0,90\n1,500\n",
+	} {
+		if _, err := ParseShot(name+".csv", []byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	data := strings.Replace(testDecentShot, "8.8}", "1e12}", 1)
+	if _, err := ParseShot("history.shot", []byte(data)); err == nil {
+		t.Error("shot: expected an error")
+	}
+}
+
+func TestNewShotChartTickLimit(t *testing.T) {
+	chart := NewShotChart([]Shot{{Samples: ShotSamples{
+		{Time: 0, Pressure: 1, Flow: 1, Weight: 1},
+		{Time: 1e12, Pressure: 1e12, Flow: 1e12, Weight: 1e12},
+	}}})
+
+	if len(chart.XTicks) > shotMaxTicks+1 {
+		t.Errorf("too many time ticks: %d", len(chart.XTicks))
+	}
+	for _, graph := range chart.Graphs {
+		if len(graph.YTicks) > shotMaxTicks+1 {
+			t.Errorf("too many %s ticks: %d", graph.Name, len(graph.YTicks))
+		}
+	}
+}
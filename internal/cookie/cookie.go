@@ -2,7 +2,11 @@ package cookie
 
 import "net/http"
 
-const SessionKey = "bs"
+const (
+	SessionKey   = "bs"
+	TwoFactorKey = "bs2fa"
+	OidcKey      = "bsoidc"
+)
 
 func Set(rw http.ResponseWriter, r *http.Request, key, value string) {
 	http.SetCookie(rw, &http.Cookie{
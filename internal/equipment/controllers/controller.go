@@ -0,0 +1,68 @@
+package equipment_controllers
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/equipment"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+type Controller struct {
+	repo equipment.Repository
+}
+
+func New(repo equipment.Repository) Controller {
+	return Controller{repo}
+}
+
+type equipmentRequest struct {
+	Name       string               `json:"name" validate:"required,max=100"`
+	Brand      string               `json:"brand" validate:"max=100"`
+	Category   equipment.Category   `json:"category" validate:"required"`
+	Attributes equipment.Attributes `json:"attributes" validate:"max=20"`
+	Notes      string               `json:"notes"`
+}
+
+// validate checks the parts of the request that cannot be covered by validation tags, any
+// attributes without a name are dropped
+func (req *equipmentRequest) validate(comData ui.ComponentData) bool {
+	req.Attributes = slices.DeleteFunc(req.Attributes, func(attr equipment.Attribute) bool {
+		return strings.TrimSpace(attr.Name) == ""
+	})
+
+	if !slices.Contains(equipment.Categories, req.Category) {
+		comData.SetFieldErrors(map[string][]string{"category": {"Unknown category"}})
+		return false
+	}
+
+	return true
+}
+
+// newCardData builds the data for the equipment-card component
+func newCardData(item any) ui.ComponentData {
+	return ui.ComponentData{
+		"Equipment":  item,
+		"Categories": equipment.Categories,
+	}
+}
+
+// findEquipment looks up the users equipment from the id in the request path
+//
+// If it cannot be found a toast is written and nil is returned
+func (c Controller) findEquipment(rw http.ResponseWriter, user *auth.User, id uint) *equipment.Equipment {
+	if id == 0 {
+		ui.Toast(rw, ui.Warning, "Equipment not found")
+		return nil
+	}
+
+	item, err := c.repo.FindEquipment(id, user.ID)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Equipment not found")
+		return nil
+	}
+
+	return item
+}
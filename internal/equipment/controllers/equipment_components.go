@@ -0,0 +1,22 @@
+package equipment_controllers
+
+import (
+	"net/http"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/equipment"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+// EquipmentSelect lists the users equipment as checkboxes for picking what a recipe is made with,
+// each value in the query is an id that starts out checked
+func (c Controller) EquipmentSelect(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	comData := ui.NewComponentData("equipment-select", ui.ComponentData{
+		"Groups": equipment.GroupByCategory(c.repo.IndexEquipmentForUser(user)),
+		"value":  r.URL.Query()["value"],
+	})
+
+	ui.RenderComponent(rw, comData)
+}
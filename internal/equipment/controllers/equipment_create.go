@@ -0,0 +1,55 @@
+package equipment_controllers
+
+import (
+	"net/http"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/equipment"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+func (c Controller) CreateEquipment(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	comData := ui.NewComponentData("equipment-card", newCardData(map[string]struct{}{}))
+	comData["edit"] = true
+	defer func() {
+		ui.RenderComponent(rw, comData)
+	}()
+
+	var req equipmentRequest
+	if err := server.UnmarshalBody(r, &req, &comData); err != nil {
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	if err := server.ValidateRequest(req, &comData); err != nil {
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	if !req.validate(comData) {
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	item := equipment.Equipment{
+		Name:       req.Name,
+		Brand:      req.Brand,
+		Category:   req.Category,
+		Attributes: req.Attributes,
+		Notes:      req.Notes,
+		UserID:     user.ID,
+	}
+
+	if err := c.repo.SaveEquipment(&item); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to create equipment")
+		return
+	}
+
+	comData["Equipment"] = item
+	comData["edit"] = false
+	comData.SetForm(equipmentRequest{})
+
+	ui.Toast(rw, ui.Success, "Equipment created")
+}
@@ -0,0 +1,40 @@
+package equipment_controllers
+
+import (
+	"net/http"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+func (c Controller) DeleteEquipment(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	comData := ui.NewComponentData("equipment-card", newCardData(nil))
+	comData["open"] = true
+	defer func() {
+		ui.RenderComponent(rw, comData)
+	}()
+
+	id, _ := server.PathID(r)
+	item := c.findEquipment(rw, user, id)
+	if item == nil {
+		comData["Component"] = ""
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+	comData["Equipment"] = item
+
+	if item.Uses > 0 {
+		ui.Toast(rw, ui.Warning, "Equipment cannot be deleted while recipes still use it")
+		return
+	}
+
+	if err := c.repo.DeleteEquipment(item); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to delete equipment")
+		return
+	}
+
+	comData["Component"] = ""
+	ui.Toast(rw, ui.Success, "Equipment deleted")
+}
@@ -0,0 +1,59 @@
+package equipment_controllers
+
+import (
+	"net/http"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+func (c Controller) UpdateEquipment(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	comData := ui.NewComponentData("equipment-card", newCardData(nil))
+	comData["edit"] = true
+	defer func() {
+		ui.RenderComponent(rw, comData)
+	}()
+
+	id, _ := server.PathID(r)
+	item := c.findEquipment(rw, user, id)
+	if item == nil {
+		comData["Component"] = ""
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+	comData["Equipment"] = item
+
+	var req equipmentRequest
+	if err := server.UnmarshalBody(r, &req, &comData); err != nil {
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	if err := server.ValidateRequest(req, &comData); err != nil {
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	if !req.validate(comData) {
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	item.Name = req.Name
+	item.Brand = req.Brand
+	item.Category = req.Category
+	item.Attributes = req.Attributes
+	item.Notes = req.Notes
+
+	if err := c.repo.SaveEquipment(item); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to save equipment")
+		return
+	}
+
+	comData["edit"] = false
+	comData.SetForm(equipmentRequest{})
+
+	ui.Toast(rw, ui.Success, "Equipment updated")
+}
@@ -0,0 +1,35 @@
+package equipment_controllers
+
+import (
+	"net/http"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/equipment"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+type viewEquipmentData struct {
+	ui.PageData
+	Groups     []equipment.CategoryGroup
+	Categories []equipment.Category
+}
+
+func (c Controller) ViewEquipment(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	pageData := viewEquipmentData{
+		PageData:   ui.NewPageData("Equipment", "equipment", user),
+		Groups:     equipment.GroupByCategory(c.repo.IndexEquipmentForUser(user)),
+		Categories: equipment.Categories,
+	}
+
+	ui.RenderUser(rw, r, pageData)
+}
+
+func (c Controller) NewEquipment(rw http.ResponseWriter, r *http.Request) {
+	comData := ui.NewComponentData("equipment-card", newCardData(map[string]struct{}{}))
+	comData["Form"] = map[string]struct{}{}
+	comData["edit"] = true
+
+	ui.RenderComponent(rw, comData)
+}
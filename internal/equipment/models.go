@@ -0,0 +1,100 @@
+package equipment
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/database/model"
+)
+
+type Category string
+
+const (
+	CategoryKettle       Category = "Kettle"
+	CategoryScale        Category = "Scale"
+	CategoryTamper       Category = "Tamper"
+	CategoryDistribution Category = "Distribution Tool"
+	CategoryPuckScreen   Category = "Puck Screen"
+	CategoryFilter       Category = "Filter Paper"
+	CategoryOther        Category = "Other"
+)
+
+var Categories = []Category{
+	CategoryKettle,
+	CategoryScale,
+	CategoryTamper,
+	CategoryDistribution,
+	CategoryPuckScreen,
+	CategoryFilter,
+	CategoryOther,
+}
+
+// Equipment is a piece of gear, other than the brewer and basket, that recipes are made with
+type Equipment struct {
+	model.SoftDelete
+
+	Name     string
+	Brand    string
+	Category Category
+	// Attributes are free form details about the equipment such as its size or material
+	Attributes Attributes
+	Notes      string
+
+	UserID uint
+	User   auth.User
+
+	// Uses is the number of recipes made with the equipment, this is only counted when the
+	// equipment is listed
+	Uses int64 `gorm:"->;-:migration"`
+}
+
+// Attribute is a single named detail about a piece of equipment
+type Attribute struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type Attributes []Attribute
+
+func (a Attributes) Value() (driver.Value, error) {
+	return json.Marshal(a)
+}
+
+func (a *Attributes) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	}
+	return errors.New("invalid data type")
+}
+
+// CategoryGroup is the users equipment in a single category
+type CategoryGroup struct {
+	Category  Category
+	Equipment []Equipment
+}
+
+// GroupByCategory splits the equipment up by category, the categories are in the order they are
+// listed in Categories and empty categories are left out
+func GroupByCategory(equipment []Equipment) []CategoryGroup {
+	var groups []CategoryGroup
+
+	for _, category := range Categories {
+		group := CategoryGroup{Category: category}
+		for _, item := range equipment {
+			if item.Category == category {
+				group.Equipment = append(group.Equipment, item)
+			}
+		}
+
+		if len(group.Equipment) > 0 {
+			groups = append(groups, group)
+		}
+	}
+
+	return groups
+}
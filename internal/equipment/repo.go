@@ -0,0 +1,89 @@
+package equipment
+
+import (
+	"github.com/indeedhat/barista/internal/auth"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	IndexEquipmentForUser(*auth.User) []Equipment
+	FindEquipment(uint, ...uint) (*Equipment, error)
+	FindEquipmentByIds(ids []uint, userId uint) []Equipment
+	SaveEquipment(*Equipment) error
+	DeleteEquipment(*Equipment) error
+}
+
+type SqliteRepository struct {
+	db *gorm.DB
+}
+
+func NewSqliteRepo(db *gorm.DB) Repository {
+	return SqliteRepository{db}
+}
+
+// IndexEquipmentForUser implements Repository.
+//
+// The most used equipment is listed first
+func (r SqliteRepository) IndexEquipmentForUser(user *auth.User) []Equipment {
+	var equipment []Equipment
+
+	r.db.Scopes(withUses).
+		Where("user_id = ?", user.ID).
+		Order("uses DESC, name ASC").
+		Find(&equipment)
+
+	return equipment
+}
+
+// FindEquipment implements Repository.
+func (r SqliteRepository) FindEquipment(id uint, userId ...uint) (*Equipment, error) {
+	var equipment Equipment
+
+	tx := r.db.Scopes(withUses)
+	if len(userId) > 0 {
+		tx = tx.Where("user_id = ?", userId[0])
+	}
+
+	if err := tx.First(&equipment, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &equipment, nil
+}
+
+// FindEquipmentByIds implements Repository.
+//
+// Any ids that do not belong to the user are left out
+func (r SqliteRepository) FindEquipmentByIds(ids []uint, userId uint) []Equipment {
+	var equipment []Equipment
+	if len(ids) == 0 {
+		return equipment
+	}
+
+	r.db.Where("id IN ? AND user_id = ?", ids, userId).Find(&equipment)
+
+	return equipment
+}
+
+// SaveEquipment implements Repository.
+func (r SqliteRepository) SaveEquipment(equipment *Equipment) error {
+	return r.db.Save(equipment).Error
+}
+
+// DeleteEquipment implements Repository.
+func (r SqliteRepository) DeleteEquipment(equipment *Equipment) error {
+	return r.db.Delete(equipment).Error
+}
+
+// withUses counts the recipes made with each piece of equipment into its Uses field
+//
+// The recipe tables are queried directly as the coffee package depends on this one
+func withUses(tx *gorm.DB) *gorm.DB {
+	return tx.Select(`equipment.*, (
+		SELECT COUNT(*) FROM recipe_equipment
+		JOIN recipes ON recipes.id = recipe_equipment.recipe_id
+		WHERE recipe_equipment.equipment_id = equipment.id AND recipes.deleted_at IS NULL
+	) AS uses`)
+}
+
+var _ Repository = (*SqliteRepository)(nil)
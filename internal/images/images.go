@@ -0,0 +1,199 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/indeedhat/barista/internal/storage"
+	"golang.org/x/image/draw"
+)
+
+const (
+	// images larger than this in either dimension or in total pixels are rejected before decoding
+	// to avoid decompression bombs eating all the memory, 40 megapixels is ~160MB once decoded
+	maxDimension = 12000
+	maxPixels    = 40_000_000
+	jpegQuality  = 85
+)
+
+// Variant is a resized copy of an uploaded image
+type Variant struct {
+	Name string
+	// MaxSize is the maximum width or height of the variant, images smaller than this are not
+	// scaled up
+	MaxSize int
+}
+
+// FullVariant is the variant that gets stored against the model, other variants are found
+// relative to it with VariantPath
+const FullVariant = "full"
+
+// DefaultVariants are generated for every uploaded icon and photo
+var DefaultVariants = []Variant{
+	{Name: "card", MaxSize: 320},
+	{Name: FullVariant, MaxSize: 1600},
+}
+
+// Process decodes an uploaded image and writes a re-encoded copy for each variant
+//
+// Re-encoding strips any metadata (including EXIF location data) and the EXIF orientation is
+// applied to the pixels so the image displays the right way up without it.
+// Variants are stored as <baseKey>.<variant>.<ext>, any previous files for the same baseKey are
+// removed once all of the new variants have been saved so a failed upload leaves the old image in
+// place.
+// The storage key of the full variant is returned
+func Process(ctx context.Context, data []byte, baseKey string, variants []Variant) (string, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", errors.New("image could not be read")
+	}
+
+	if cfg.Width > maxDimension ||
+		cfg.Height > maxDimension ||
+		int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return "", errors.New("image is too large")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", errors.New("image could not be read")
+	}
+
+	ext := ".jpg"
+	if format == "png" {
+		ext = ".png"
+	}
+
+	var orientation int
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	largest := 0
+	for _, v := range variants {
+		largest = max(largest, v.MaxSize)
+	}
+
+	img = applyOrientation(resize(img, largest), orientation)
+
+	encoded := make(map[string][]byte, len(variants))
+	for _, v := range variants {
+		var buf bytes.Buffer
+		if err := encode(&buf, resize(img, v.MaxSize), ext); err != nil {
+			return "", errors.New("image could not be encoded")
+		}
+
+		encoded[baseKey+"."+v.Name+ext] = buf.Bytes()
+	}
+
+	store := storage.Default()
+	for key, data := range encoded {
+		if err := store.Put(ctx, key, bytes.NewReader(data), storage.ContentType(key)); err != nil {
+			return "", errors.New("image could not be saved on the server")
+		}
+	}
+
+	keep := make([]string, 0, len(encoded))
+	for key := range encoded {
+		keep = append(keep, key)
+	}
+
+	if err := RemoveVariants(ctx, baseKey, keep...); err != nil {
+		return "", err
+	}
+
+	return baseKey + "." + FullVariant + ext, nil
+}
+
+// RemoveVariants removes every file stored for baseKey other than the keep keys
+//
+// This includes images uploaded before variants existed (<baseKey>.<ext>) so an old .png is not
+// left behind when a .jpg is uploaded in its place
+func RemoveVariants(ctx context.Context, baseKey string, keep ...string) error {
+	store := storage.Default()
+
+	objects, err := store.List(ctx, baseKey+".")
+	if err != nil {
+		return errors.New("old image could not be removed")
+	}
+
+	for _, obj := range objects {
+		if slices.Contains(keep, obj.Key) {
+			continue
+		}
+
+		if err := store.Delete(ctx, obj.Key); err != nil {
+			return errors.New("old image could not be removed")
+		}
+	}
+
+	return nil
+}
+
+// VariantPath converts the path of the full variant into the path of another variant
+//
+// Images uploaded before variants existed only have the original file so their path is returned
+// as is
+func VariantPath(imagePath, variant string) string {
+	ext := path.Ext(imagePath)
+	stem := strings.TrimSuffix(imagePath, ext)
+
+	if !strings.HasSuffix(stem, "."+FullVariant) {
+		return imagePath
+	}
+
+	return strings.TrimSuffix(stem, FullVariant) + variant + ext
+}
+
+// IsVariantOf checks if key is one of the variants of the image stored at imageKey
+func IsVariantOf(key, imageKey string, variants []Variant) bool {
+	if key == imageKey {
+		return true
+	}
+
+	for _, v := range variants {
+		if VariantPath(imageKey, v.Name) == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resize scales the image down so that neither side is larger than maxSize
+func resize(img image.Image, maxSize int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width <= maxSize && height <= maxSize {
+		return img
+	}
+
+	if width > height {
+		height = max(1, height*maxSize/width)
+		width = maxSize
+	} else {
+		width = max(1, width*maxSize/height)
+		height = maxSize
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+	return dst
+}
+
+func encode(buf *bytes.Buffer, img image.Image, ext string) error {
+	if ext == ".png" {
+		return png.Encode(buf, img)
+	}
+
+	return jpeg.Encode(buf, img, &jpeg.Options{Quality: jpegQuality})
+}
@@ -0,0 +1,42 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+)
+
+// pngHeader crafts the start of a png that claims the given size, it is only enough for the config
+// to be read
+func pngHeader(width, height uint32) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], width)
+	binary.BigEndian.PutUint32(ihdr[4:], height)
+	ihdr[8] = 8 // bit depth
+	ihdr[9] = 6 // rgba
+
+	chunk := append([]byte("IHDR"), ihdr...)
+	binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)))
+	buf.Write(chunk)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+
+	return buf.Bytes()
+}
+
+func TestProcessRejectsLargeImages(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"pixel budget": pngHeader(12000, 12000),
+		"wide":         pngHeader(12001, 10),
+		"tall":         pngHeader(10, 12001),
+	} {
+		_, err := Process(context.Background(), data, "test", DefaultVariants)
+		if err == nil || err.Error() != "image is too large" {
+			t.Errorf("%s: got %v", name, err)
+		}
+	}
+}
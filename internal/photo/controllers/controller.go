@@ -0,0 +1,73 @@
+package photo_controllers
+
+import (
+	"net/http"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/photo"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+const (
+	PhotoImagePath = "photo/"
+
+	maxPhotosPerUpload = 10
+)
+
+type Controller struct {
+	repo   photo.Repository
+	owners photo.Owners
+}
+
+func New(repo photo.Repository, owners photo.Owners) Controller {
+	return Controller{repo, owners}
+}
+
+// ownsTarget checks that the user owns the coffee, recipe or brewer that photos are being attached to
+func (c Controller) ownsTarget(user *auth.User, ownerType photo.OwnerType, ownerId uint) bool {
+	resolve, ok := c.owners[ownerType]
+	if !ok || ownerId == 0 {
+		return false
+	}
+
+	owner, err := resolve(ownerId)
+	if err != nil {
+		return false
+	}
+
+	userId, _ := owner.UploadOwner()
+	return userId == user.ID
+}
+
+// findPhoto finds one of the users photos from the id in the request path
+//
+// The gallery is swapped out on every request so failure is reported with an error status to
+// stop htmx from replacing it with an empty response
+func (c Controller) findPhoto(rw http.ResponseWriter, r *http.Request, user *auth.User) *photo.Photo {
+	id, err := server.PathID(r)
+	if err != nil {
+		notFound(rw)
+		return nil
+	}
+
+	photo, err := c.repo.FindPhoto(id, user.ID)
+	if err != nil {
+		notFound(rw)
+		return nil
+	}
+
+	return photo
+}
+
+func newGalleryData(ownerType photo.OwnerType, ownerId uint) ui.ComponentData {
+	return ui.NewComponentData("photo-gallery", ui.ComponentData{
+		"OwnerType": ownerType,
+		"OwnerID":   ownerId,
+	})
+}
+
+func notFound(rw http.ResponseWriter) {
+	ui.Toast(rw, ui.Warning, "Photo not found")
+	rw.WriteHeader(http.StatusNotFound)
+}
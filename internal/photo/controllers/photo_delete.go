@@ -0,0 +1,44 @@
+package photo_controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/images"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+// DeletePhoto removes a photo and its files
+//
+// If the photo was the cover of its gallery then the next photo takes its place
+func (c Controller) DeletePhoto(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	model := c.findPhoto(rw, r, user)
+	if model == nil {
+		return
+	}
+
+	comData := newGalleryData(model.OwnerType, model.OwnerID)
+	defer func() {
+		comData["Photos"] = c.repo.IndexPhotos(model.OwnerType, model.OwnerID)
+		ui.RenderComponent(rw, comData)
+	}()
+
+	if err := c.repo.DeletePhoto(model); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to delete photo")
+		return
+	}
+
+	// any files left behind will be picked up by the upload gc
+	_ = images.RemoveVariants(r.Context(), fmt.Sprint(PhotoImagePath, model.ID))
+
+	if model.Cover {
+		if photos := c.repo.IndexPhotos(model.OwnerType, model.OwnerID); len(photos) > 0 {
+			c.repo.SetCover(&photos[0])
+		}
+	}
+
+	ui.Toast(rw, ui.Success, "Photo deleted")
+}
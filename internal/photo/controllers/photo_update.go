@@ -0,0 +1,114 @@
+package photo_controllers
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/photo"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+type updatePhotoRequest struct {
+	Caption string `json:"caption" validate:"max=200"`
+}
+
+// UpdatePhoto changes the caption of a photo
+func (c Controller) UpdatePhoto(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	model := c.findPhoto(rw, r, user)
+	if model == nil {
+		return
+	}
+
+	comData := newGalleryData(model.OwnerType, model.OwnerID)
+	defer func() {
+		comData["Photos"] = c.repo.IndexPhotos(model.OwnerType, model.OwnerID)
+		ui.RenderComponent(rw, comData)
+	}()
+
+	var req updatePhotoRequest
+	if err := server.UnmarshalBody(r, &req, comData); err != nil {
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	if err := server.ValidateRequest(req, comData); err != nil {
+		ui.Toast(rw, ui.Warning, "Caption is too long")
+		return
+	}
+
+	model.Caption = req.Caption
+	if err := c.repo.SavePhoto(model); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to update photo")
+		return
+	}
+
+	ui.Toast(rw, ui.Success, "Photo updated")
+}
+
+// SetCoverPhoto makes a photo the cover of its gallery
+func (c Controller) SetCoverPhoto(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	model := c.findPhoto(rw, r, user)
+	if model == nil {
+		return
+	}
+
+	comData := newGalleryData(model.OwnerType, model.OwnerID)
+	defer func() {
+		comData["Photos"] = c.repo.IndexPhotos(model.OwnerType, model.OwnerID)
+		ui.RenderComponent(rw, comData)
+	}()
+
+	if err := c.repo.SetCover(model); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to set cover photo")
+		return
+	}
+
+	ui.Toast(rw, ui.Success, "Cover photo updated")
+}
+
+// MovePhoto moves a photo one place earlier or later in its gallery
+func (c Controller) MovePhoto(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	model := c.findPhoto(rw, r, user)
+	if model == nil {
+		return
+	}
+
+	comData := newGalleryData(model.OwnerType, model.OwnerID)
+	defer func() {
+		comData["Photos"] = c.repo.IndexPhotos(model.OwnerType, model.OwnerID)
+		ui.RenderComponent(rw, comData)
+	}()
+
+	photos := c.repo.IndexPhotos(model.OwnerType, model.OwnerID)
+	i := slices.IndexFunc(photos, func(p photo.Photo) bool {
+		return p.ID == model.ID
+	})
+
+	j := i
+	switch r.PathValue("direction") {
+	case "earlier":
+		j--
+	case "later":
+		j++
+	default:
+		ui.Toast(rw, ui.Warning, "Unknown direction")
+		return
+	}
+
+	if i < 0 || j < 0 || j >= len(photos) {
+		return
+	}
+
+	photos[i], photos[j] = photos[j], photos[i]
+	if err := c.repo.SavePositions(photos); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to move photo")
+	}
+}
@@ -0,0 +1,100 @@
+package photo_controllers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/images"
+	"github.com/indeedhat/barista/internal/photo"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/storage"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+// UploadPhotos adds one or more photos to the end of a gallery
+//
+// If the gallery does not yet have a cover then the first photo uploaded becomes it
+func (c Controller) UploadPhotos(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	ownerType := photo.OwnerType(r.PathValue("type"))
+	ownerId, _ := server.PathID(r)
+	if !c.ownsTarget(user, ownerType, ownerId) {
+		ui.Toast(rw, ui.Warning, "Gallery not found")
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	comData := newGalleryData(ownerType, ownerId)
+	defer func() {
+		comData["Photos"] = c.repo.IndexPhotos(ownerType, ownerId)
+		ui.RenderComponent(rw, comData)
+	}()
+
+	headers, err := server.FormFiles(r, "photos", maxPhotosPerUpload)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, err.Error())
+		return
+	}
+
+	if len(headers) == 0 {
+		ui.Toast(rw, ui.Warning, "No photos were uploaded")
+		return
+	}
+
+	existing := c.repo.IndexPhotos(ownerType, ownerId)
+	position := uint(len(existing))
+	if len(existing) > 0 {
+		position = max(position, existing[len(existing)-1].Position+1)
+	}
+
+	needsCover := !slices.ContainsFunc(existing, func(p photo.Photo) bool {
+		return p.Cover
+	})
+
+	var failed int
+	for _, header := range headers {
+		model := photo.Photo{
+			OwnerType: ownerType,
+			OwnerID:   ownerId,
+			UserID:    user.ID,
+			Position:  position,
+			Cover:     needsCover,
+		}
+
+		if err := c.repo.SavePhoto(&model); err != nil {
+			failed++
+			continue
+		}
+
+		key, err := server.UploadImageFile(r, header, fmt.Sprint(PhotoImagePath, model.ID), &server.UploadProps{
+			Ext:  []string{".jpg", ".jpeg", ".png"},
+			Mime: []string{"image/png", "image/jpeg"},
+		}, images.DefaultVariants)
+		if err == nil {
+			model.Path = storage.ModelPath(key)
+			err = c.repo.SavePhoto(&model)
+		}
+
+		if err != nil {
+			images.RemoveVariants(r.Context(), fmt.Sprint(PhotoImagePath, model.ID))
+			c.repo.DeletePhoto(&model)
+			failed++
+			continue
+		}
+
+		position++
+		needsCover = false
+	}
+
+	switch {
+	case failed == len(headers):
+		ui.Toast(rw, ui.Warning, "Failed to upload photos")
+	case failed > 0:
+		ui.Toast(rw, ui.Warning, fmt.Sprintf("%d of %d photos failed to upload", failed, len(headers)))
+	default:
+		ui.Toast(rw, ui.Success, "Photos uploaded")
+	}
+}
@@ -0,0 +1,28 @@
+package photo_controllers
+
+import (
+	"net/http"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/photo"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+// ViewGallery renders the photo gallery for a coffee, recipe or brewer
+func (c Controller) ViewGallery(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	ownerType := photo.OwnerType(r.PathValue("type"))
+	ownerId, _ := server.PathID(r)
+	if !c.ownsTarget(user, ownerType, ownerId) {
+		ui.Toast(rw, ui.Warning, "Gallery not found")
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	comData := newGalleryData(ownerType, ownerId)
+	comData["Photos"] = c.repo.IndexPhotos(ownerType, ownerId)
+
+	ui.RenderComponent(rw, comData)
+}
@@ -0,0 +1,76 @@
+package photo
+
+import (
+	"errors"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/database/model"
+	"github.com/indeedhat/barista/internal/uploads"
+)
+
+// OwnerType is the kind of model a photo is attached to
+type OwnerType string
+
+const (
+	OwnerCoffee OwnerType = "coffee"
+	OwnerRecipe OwnerType = "recipe"
+	OwnerBrewer OwnerType = "brewer"
+)
+
+type Photo struct {
+	model.SoftDelete
+
+	Path     string
+	Caption  string
+	Position uint
+	Cover    bool
+
+	OwnerType OwnerType `gorm:"index:idx_photo_owner"`
+	OwnerID   uint      `gorm:"index:idx_photo_owner"`
+
+	UserID uint
+	User   auth.User
+
+	// public is inherited from the owner when the photo is resolved as an upload
+	public bool
+}
+
+// UploadOwner implements uploads.Owner.
+func (p Photo) UploadOwner() (uint, bool) {
+	return p.UserID, p.public
+}
+
+// UploadFiles implements uploads.Owner.
+func (p Photo) UploadFiles() []string {
+	return []string{p.Path}
+}
+
+// Owners maps each owner type to the resolver for the model photos can be attached to
+type Owners map[OwnerType]uploads.Resolver
+
+// Resolver creates the upload resolver for photos
+//
+// Photos are visible to other users if the coffee, recipe or brewer they are attached to is, if the
+// owner no longer exists then neither does the photo
+func Resolver(repo Repository, owners Owners) uploads.Resolver {
+	return func(id uint) (uploads.Owner, error) {
+		photo, err := repo.FindPhoto(id)
+		if err != nil {
+			return nil, err
+		}
+
+		resolve, ok := owners[photo.OwnerType]
+		if !ok {
+			return nil, errors.New("unknown photo owner type")
+		}
+
+		owner, err := resolve(photo.OwnerID)
+		if err != nil {
+			return nil, err
+		}
+
+		_, photo.public = owner.UploadOwner()
+
+		return photo, nil
+	}
+}
@@ -0,0 +1,93 @@
+package photo
+
+import (
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	IndexPhotos(OwnerType, uint) []Photo
+	FindPhoto(uint, ...uint) (*Photo, error)
+	SavePhoto(*Photo) error
+	DeletePhoto(*Photo) error
+	SetCover(*Photo) error
+	SavePositions([]Photo) error
+}
+
+type SqliteRepository struct {
+	db *gorm.DB
+}
+
+func NewSqliteRepo(db *gorm.DB) Repository {
+	return SqliteRepository{db}
+}
+
+// IndexPhotos implements Repository.
+func (r SqliteRepository) IndexPhotos(ownerType OwnerType, ownerId uint) []Photo {
+	var photos []Photo
+
+	r.db.Where("owner_type = ? AND owner_id = ?", ownerType, ownerId).
+		Order("position ASC, id ASC").
+		Find(&photos)
+
+	return photos
+}
+
+// FindPhoto implements Repository.
+func (r SqliteRepository) FindPhoto(id uint, userId ...uint) (*Photo, error) {
+	var photo Photo
+
+	tx := r.db
+	if len(userId) > 0 {
+		tx = tx.Where("user_id = ?", userId[0])
+	}
+
+	if err := tx.First(&photo, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &photo, nil
+}
+
+// SavePhoto implements Repository.
+func (r SqliteRepository) SavePhoto(photo *Photo) error {
+	return r.db.Save(photo).Error
+}
+
+// DeletePhoto implements Repository.
+func (r SqliteRepository) DeletePhoto(photo *Photo) error {
+	return r.db.Delete(photo).Error
+}
+
+// SetCover implements Repository.
+func (r SqliteRepository) SetCover(photo *Photo) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&Photo{}).
+			Where("owner_type = ? AND owner_id = ? AND id != ?", photo.OwnerType, photo.OwnerID, photo.ID).
+			Update("cover", false).
+			Error
+		if err != nil {
+			return err
+		}
+
+		photo.Cover = true
+		return tx.Save(photo).Error
+	})
+}
+
+// SavePositions implements Repository.
+func (r SqliteRepository) SavePositions(photos []Photo) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range photos {
+			photos[i].Position = uint(i)
+
+			err := tx.Model(&photos[i]).Update("position", photos[i].Position).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+var _ Repository = (*SqliteRepository)(nil)
@@ -0,0 +1,405 @@
+package roasting
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// artisan stores the index of each event in the timeindex list
+const (
+	artisanCharge = iota
+	artisanDryEnd
+	artisanFirstCrack
+	artisanFirstCrackEnd
+	artisanSecondCrack
+	artisanSecondCrackEnd
+	artisanDrop
+)
+
+const (
+	// maxRoastLength is the longest roast that will be accepted from a profile, anything longer is
+	// assumed to be corrupt
+	maxRoastLength = 60 * 60
+	// maxRoastTemp is the hottest reading in celsius that will be accepted from a profile
+	maxRoastTemp = 400
+	// maxPyDepth is the deepest that python literals can be nested, artisan files only nest a few
+	// levels deep so this is plenty
+	maxPyDepth = 32
+)
+
+// ParseArtisan reads a roast profile from an Artisan .alog file
+//
+// .alog files are a python dict literal containing the sample times (timex), the environment
+// temperature (temp1), the bean temperature (temp2) and the sample index of each roast event
+// (timeindex). The curve is trimmed to the samples between charge and drop and all times are
+// made relative to charge. Temperatures recorded in fahrenheit are converted to celsius
+func ParseArtisan(data []byte) (*RoastProfile, error) {
+	value, err := parsePyLiteral(data)
+	if err != nil {
+		return nil, fmt.Errorf("not a valid Artisan profile: %w", err)
+	}
+
+	alog, ok := value.(map[string]any)
+	if !ok {
+		return nil, errors.New("not a valid Artisan profile")
+	}
+
+	times := pyFloats(alog["timex"])
+	env := pyFloats(alog["temp1"])
+	bean := pyFloats(alog["temp2"])
+	if len(times) < 2 || len(bean) != len(times) || len(env) != len(times) {
+		return nil, errors.New("the Artisan profile does not contain any temperature readings")
+	}
+
+	events := pyFloats(alog["timeindex"])
+	event := func(i int) int {
+		if i >= len(events) {
+			return 0
+		}
+		idx := int(events[i])
+		if idx <= 0 || idx >= len(times) {
+			return 0
+		}
+		return idx
+	}
+
+	charge := event(artisanCharge)
+	drop := event(artisanDrop)
+	if drop <= charge {
+		drop = len(times) - 1
+	}
+
+	fahrenheit := alog["mode"] == "F"
+	temp := func(t float64) float64 {
+		// artisan uses -1 for a missing reading
+		if t <= 0 || math.IsNaN(t) || math.IsInf(t, 0) {
+			return 0
+		}
+		if fahrenheit {
+			t = (t - 32) * 5 / 9
+		}
+		return math.Round(t*10) / 10
+	}
+
+	profile := RoastProfile{}
+	for i := charge; i <= drop; i++ {
+		offset := times[i] - times[charge]
+		if math.IsNaN(offset) || offset < 0 || offset > maxRoastLength {
+			return nil, errors.New("the Artisan profile contains sample times outside of a one hour roast")
+		}
+
+		beanTemp, envTemp := temp(bean[i]), temp(env[i])
+		if beanTemp > maxRoastTemp || envTemp > maxRoastTemp {
+			return nil, fmt.Errorf("the Artisan profile contains temperatures above %d°C", maxRoastTemp)
+		}
+
+		profile.Times = append(profile.Times, math.Round(offset*10)/10)
+		profile.BeanTemp = append(profile.BeanTemp, beanTemp)
+		profile.EnvTemp = append(profile.EnvTemp, envTemp)
+	}
+
+	offset := func(i int) time.Duration {
+		if i <= charge || i > drop {
+			return 0
+		}
+		return time.Duration((times[i] - times[charge]) * float64(time.Second)).Round(time.Second)
+	}
+
+	profile.DryEnd = offset(event(artisanDryEnd))
+	profile.FirstCrack = offset(event(artisanFirstCrack))
+	profile.FirstCrackEnd = offset(event(artisanFirstCrackEnd))
+	profile.Drop = offset(drop)
+	profile.ChargeTemp = temp(bean[charge])
+	profile.DropTemp = temp(bean[drop])
+
+	if title, ok := alog["title"].(string); ok {
+		profile.Title = title
+	}
+
+	return &profile, nil
+}
+
+// pyFloats converts a parsed python list of numbers into a float slice, anything that is not a
+// number is treated as zero
+func pyFloats(value any) []float64 {
+	list, ok := value.([]any)
+	if !ok {
+		return nil
+	}
+
+	floats := make([]float64, 0, len(list))
+	for _, v := range list {
+		f, _ := v.(float64)
+		floats = append(floats, f)
+	}
+
+	return floats
+}
+
+// pyParser is a minimal parser for python literals
+//
+// It supports the subset of the syntax that python produces when it repr()s a dict of basic types
+// which is how Artisan saves its files: dicts, lists, tuples, strings, numbers, True, False and None
+type pyParser struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+// parsePyLiteral parses a single python literal value
+//
+// dicts are returned as map[string]any, lists and tuples as []any, numbers as float64
+func parsePyLiteral(data []byte) (any, error) {
+	p := pyParser{data: data}
+
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if p.pos < len(p.data) {
+		return nil, p.errorf("unexpected data after value")
+	}
+
+	return value, nil
+}
+
+func (p *pyParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *pyParser) skipSpace() {
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *pyParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return 0
+	}
+
+	return p.data[p.pos]
+}
+
+func (p *pyParser) value() (any, error) {
+	switch c := p.peek(); {
+	case c == 0:
+		return nil, p.errorf("unexpected end of data")
+	case c == '{':
+		return p.dict()
+	case c == '[':
+		return p.list('[', ']')
+	case c == '(':
+		return p.list('(', ')')
+	case c == '\'' || c == '"':
+		return p.string()
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+		return p.number()
+	case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		return p.identifier()
+	default:
+		return nil, p.errorf("unexpected character %q", c)
+	}
+}
+
+// enter steps into a nested dict, list or tuple, the returned func must be called on the way back out
+func (p *pyParser) enter() (func(), error) {
+	if p.depth >= maxPyDepth {
+		return nil, p.errorf("values nested too deeply")
+	}
+
+	p.depth++
+	return func() { p.depth-- }, nil
+}
+
+func (p *pyParser) dict() (any, error) {
+	leave, err := p.enter()
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+
+	p.pos++
+	dict := make(map[string]any)
+
+	for {
+		if p.peek() == '}' {
+			p.pos++
+			return dict, nil
+		}
+
+		key, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.peek() != ':' {
+			return nil, p.errorf("expected ':'")
+		}
+		p.pos++
+
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+
+		switch k := key.(type) {
+		case string:
+			dict[k] = value
+		case float64:
+			dict[strconv.FormatFloat(k, 'f', -1, 64)] = value
+		default:
+			dict[fmt.Sprint(k)] = value
+		}
+
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+		default:
+			return nil, p.errorf("expected ',' or '}'")
+		}
+	}
+}
+
+func (p *pyParser) list(open, close byte) (any, error) {
+	leave, err := p.enter()
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+
+	p.pos++
+	list := []any{}
+
+	for {
+		if p.peek() == close {
+			p.pos++
+			return list, nil
+		}
+
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case close:
+		default:
+			return nil, p.errorf("expected ',' or '%c'", close)
+		}
+	}
+}
+
+func (p *pyParser) string() (any, error) {
+	quote := p.data[p.pos]
+	p.pos++
+
+	var sb strings.Builder
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return sb.String(), nil
+
+		case c == '\\':
+			if p.pos+1 >= len(p.data) {
+				return nil, p.errorf("unterminated string")
+			}
+
+			esc := p.data[p.pos+1]
+			p.pos += 2
+			switch esc {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case 'x', 'u', 'U':
+				size := map[byte]int{'x': 2, 'u': 4, 'U': 8}[esc]
+				if p.pos+size > len(p.data) {
+					return nil, p.errorf("invalid escape sequence")
+				}
+
+				code, err := strconv.ParseUint(string(p.data[p.pos:p.pos+size]), 16, 32)
+				if err != nil {
+					return nil, p.errorf("invalid escape sequence")
+				}
+				sb.WriteRune(rune(code))
+				p.pos += size
+			default:
+				sb.WriteByte(esc)
+			}
+
+		default:
+			r, size := utf8.DecodeRune(p.data[p.pos:])
+			sb.WriteRune(r)
+			p.pos += size
+		}
+	}
+
+	return nil, p.errorf("unterminated string")
+}
+
+func (p *pyParser) number() (any, error) {
+	start := p.pos
+	for p.pos < len(p.data) && strings.IndexByte("+-.0123456789eE", p.data[p.pos]) >= 0 {
+		p.pos++
+	}
+
+	n, err := strconv.ParseFloat(string(p.data[start:p.pos]), 64)
+	if err != nil {
+		return nil, p.errorf("invalid number")
+	}
+
+	return n, nil
+}
+
+func (p *pyParser) identifier() (any, error) {
+	start := p.pos
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			break
+		}
+		p.pos++
+	}
+
+	// string prefixes such as u'' and b''
+	if p.pos < len(p.data) && (p.data[p.pos] == '\'' || p.data[p.pos] == '"') && p.pos-start <= 2 {
+		return p.string()
+	}
+
+	switch ident := string(p.data[start:p.pos]); ident {
+	case "True", "true":
+		return true, nil
+	case "False", "false":
+		return false, nil
+	case "None", "null":
+		return nil, nil
+	case "nan":
+		return math.NaN(), nil
+	case "inf":
+		return math.Inf(1), nil
+	default:
+		return nil, p.errorf("unknown identifier %s", ident)
+	}
+}
@@ -0,0 +1,66 @@
+package roasting
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testAlog = `{'mode': 'C', 'title': u'Test Roast', 'timex': [0.0, 30.0, 60.0, 90.0, 120.0, 150.0, 180.0],
+'temp1': [220.0, 210.0, 215.0, 225.0, 230.0, 235.0, 240.0],
+'temp2': [-1, 200.0, 150.0, 170.0, 190.0, 200.0, 210.5],
+'timeindex': [1, 3, 4, 0, 0, 0, 6], 'extra': {'nested': ({'a': None, 'b': True},)}}`
+
+func TestParseArtisan(t *testing.T) {
+	profile, err := ParseArtisan([]byte(testAlog))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if profile.Title != "Test Roast" {
+		t.Errorf("title: got %q", profile.Title)
+	}
+	if len(profile.Times) != 6 || profile.Times[0] != 0 || profile.Times[5] != 150 {
+		t.Errorf("times: got %v", profile.Times)
+	}
+	if profile.DryEnd != time.Minute || profile.FirstCrack != 90*time.Second || profile.Drop != 150*time.Second {
+		t.Errorf("events: got %s %s %s", profile.DryEnd, profile.FirstCrack, profile.Drop)
+	}
+	if profile.ChargeTemp != 200 || profile.DropTemp != 210.5 {
+		t.Errorf("temps: got %v %v", profile.ChargeTemp, profile.DropTemp)
+	}
+}
+
+func TestParseArtisanDeepNesting(t *testing.T) {
+	for _, open := range []string{"[", "{'a':", "("} {
+		data := bytes.Repeat([]byte(open), 5<<20/len(open))
+		if _, err := ParseArtisan(data); err == nil {
+			t.Errorf("%s: expected an error", open)
+		}
+	}
+}
+
+func TestParseArtisanImplausible(t *testing.T) {
+	for name, data := range map[string]string{
+		"long":    strings.Replace(testAlog, "180.0]", "1e12]", 1),
+		"hot":     strings.Replace(testAlog, "210.5]", "1e12]", 1),
+		"reverse": strings.Replace(testAlog, "0.0, 30.0, 60.0", "0.0, 30.0, -60.0", 1),
+	} {
+		if _, err := ParseArtisan([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestNewProfileChartTickLimit(t *testing.T) {
+	chart := NewProfileChart(RoastProfile{
+		Times:    FloatList{0, 1e12},
+		BeanTemp: FloatList{1, 1e12},
+		EnvTemp:  FloatList{1, 1e12},
+	})
+
+	if len(chart.XTicks) > profileMaxTicks+1 || len(chart.YTicks) > profileMaxTicks+1 {
+		t.Errorf("too many ticks: %d %d", len(chart.XTicks), len(chart.YTicks))
+	}
+}
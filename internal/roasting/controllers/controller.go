@@ -0,0 +1,22 @@
+package roasting_controllers
+
+import (
+	"slices"
+
+	"github.com/indeedhat/barista/internal/coffee"
+	"github.com/indeedhat/barista/internal/roasting"
+	"github.com/indeedhat/barista/internal/types"
+)
+
+type Controller struct {
+	repo       roasting.Repository
+	coffeeRepo coffee.Repository
+}
+
+func New(repo roasting.Repository, coffeeRepo coffee.Repository) Controller {
+	return Controller{repo, coffeeRepo}
+}
+
+func validProcess(process string) bool {
+	return process == "" || slices.Contains(types.ProcessMethods, types.ProcessMethod(process))
+}
@@ -0,0 +1,202 @@
+package roasting_controllers
+
+import (
+	"net/http"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/roasting"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/types"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+type greenBeanRequest struct {
+	Name       string  `json:"name" validate:"required"`
+	Supplier   string  `json:"supplier"`
+	Country    string  `json:"country"`
+	Region     string  `json:"region"`
+	Varietal   string  `json:"varietal"`
+	Process    string  `json:"process"`
+	Weight     float64 `json:"weight" validate:"min=0"`
+	PricePerKg float64 `json:"price_per_kg" validate:"min=0"`
+	Notes      string  `json:"notes"`
+}
+
+func (req greenBeanRequest) apply(bean *roasting.GreenBean) {
+	bean.Name = req.Name
+	bean.Supplier = req.Supplier
+	bean.Country = req.Country
+	bean.Region = req.Region
+	bean.Varietal = req.Varietal
+	bean.Process = types.ProcessMethod(req.Process)
+	bean.Weight = req.Weight
+	bean.PricePerKg = req.PricePerKg
+	bean.Notes = req.Notes
+}
+
+type viewGreenBeansData struct {
+	ui.PageData
+	Beans []roasting.GreenBean
+	Open  bool
+}
+
+func (c Controller) ViewGreenBeans(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	pageData := viewGreenBeansData{PageData: ui.NewPageData("Green Beans", "green-beans", user)}
+	pageData.Form = greenBeanRequest{}
+	pageData.Beans = c.repo.IndexGreenBeansForUser(user)
+
+	ui.RenderUser(rw, r, pageData)
+}
+
+func (c Controller) CreateGreenBean(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	pageData := viewGreenBeansData{PageData: ui.NewPageData("Green Beans", "green-beans", user)}
+	pageData.Beans = c.repo.IndexGreenBeansForUser(user)
+	pageData.Open = true
+	defer func() {
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	var req greenBeanRequest
+	if err := server.UnmarshalBody(r, &req, &pageData); err != nil {
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	if err := server.ValidateRequest(req, &pageData); err != nil {
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	if !validProcess(req.Process) {
+		pageData.SetFieldErrors(map[string][]string{"process": {"Unknown process method"}})
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	bean := roasting.GreenBean{UserID: user.ID}
+	req.apply(&bean)
+
+	if err := c.repo.SaveGreenBean(&bean); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to create green bean")
+		return
+	}
+
+	pageData.Beans = c.repo.IndexGreenBeansForUser(user)
+	pageData.Open = false
+	pageData.Form = greenBeanRequest{}
+
+	ui.Toast(rw, ui.Success, "Green bean created")
+}
+
+type viewGreenBeanData struct {
+	ui.PageData
+	Bean *roasting.GreenBean
+}
+
+func (c Controller) ViewGreenBean(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	id, err := server.PathID(r)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Green Bean Not Found")
+		ui.RenderUser(rw, r, ui.NewPageData("Green Bean Not Found", "404", user))
+		return
+	}
+
+	bean, err := c.repo.FindGreenBean(id, user.ID)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Green Bean Not Found")
+		ui.RenderUser(rw, r, ui.NewPageData("Green Bean Not Found", "404", user))
+		return
+	}
+
+	pageData := viewGreenBeanData{PageData: ui.NewPageData(bean.Name, "green-bean", user)}
+	pageData.Bean = bean
+	pageData.Form = greenBeanRequest{}
+
+	ui.RenderUser(rw, r, pageData)
+}
+
+func (c Controller) UpdateGreenBean(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	pageData := viewGreenBeanData{PageData: ui.NewPageData("Green Bean", "green-bean", user)}
+	defer func() {
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	id, err := server.PathID(r)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Green bean not found")
+		return
+	}
+
+	bean, err := c.repo.FindGreenBean(id, user.ID)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Green bean not found")
+		return
+	}
+
+	pageData.Title = bean.Name
+	pageData.Bean = bean
+
+	var req greenBeanRequest
+	if err := server.UnmarshalBody(r, &req, &pageData); err != nil {
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	if err := server.ValidateRequest(req, &pageData); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to update green bean")
+		return
+	}
+
+	if !validProcess(req.Process) {
+		pageData.SetFieldErrors(map[string][]string{"process": {"Unknown process method"}})
+		ui.Toast(rw, ui.Warning, "Failed to update green bean")
+		return
+	}
+
+	req.apply(bean)
+
+	if err := c.repo.SaveGreenBean(bean); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to update green bean")
+		return
+	}
+
+	pageData.Title = bean.Name
+	ui.Toast(rw, ui.Success, "Green bean updated")
+}
+
+func (c Controller) DeleteGreenBean(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	pageData := viewGreenBeanData{PageData: ui.NewPageData("Green Bean", "green-bean", user)}
+	defer func() {
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	id, err := server.PathID(r)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Green bean not found")
+		return
+	}
+
+	bean, err := c.repo.FindGreenBean(id, user.ID)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Green bean not found")
+		return
+	}
+
+	pageData.Title = bean.Name
+	pageData.Bean = bean
+
+	if err := c.repo.DeleteGreenBean(bean); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to delete green bean")
+		return
+	}
+
+	ui.Toast(rw, ui.Success, "Green bean deleted")
+	server.Redirect(rw, r, "/roasting/beans")
+}
@@ -0,0 +1,234 @@
+package roasting_controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/coffee"
+	"github.com/indeedhat/barista/internal/roasting"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+type createBatchRequest struct {
+	GreenBean    uint    `json:"green_bean" validate:"required"`
+	RoastedAt    string  `json:"roasted_at"`
+	ChargeWeight float64 `json:"charge_weight" validate:"gt=0"`
+	DropWeight   float64 `json:"drop_weight" validate:"min=0,ltefield=ChargeWeight"`
+	// FirstCrack and DevelopmentTime are in seconds
+	FirstCrack      int     `json:"first_crack" validate:"min=0"`
+	DevelopmentTime int     `json:"development_time" validate:"min=0"`
+	EndTemperature  float64 `json:"end_temperature" validate:"min=0,max=300"`
+	Notes           string  `json:"notes"`
+}
+
+type viewBatchesData struct {
+	ui.PageData
+	Batches []roasting.RoastBatch
+	Beans   []roasting.GreenBean
+	Open    bool
+}
+
+func (c Controller) newBatchesData(user *auth.User) viewBatchesData {
+	return viewBatchesData{
+		PageData: ui.NewPageData("Roasts", "roasts", user),
+		Batches:  c.repo.IndexBatchesForUser(user),
+		Beans:    c.repo.IndexGreenBeansForUser(user),
+	}
+}
+
+func (c Controller) ViewBatches(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	pageData := c.newBatchesData(user)
+	pageData.Form = createBatchRequest{RoastedAt: time.Now().Format(time.DateOnly)}
+
+	ui.RenderUser(rw, r, pageData)
+}
+
+func (c Controller) CreateBatch(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	pageData := c.newBatchesData(user)
+	pageData.Open = true
+	defer func() {
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	var req createBatchRequest
+	if err := server.UnmarshalBody(r, &req, &pageData); err != nil {
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	if err := server.ValidateRequest(req, &pageData); err != nil {
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	roastedAt := time.Now()
+	if req.RoastedAt != "" {
+		var err error
+		if roastedAt, err = time.Parse(time.DateOnly, req.RoastedAt); err != nil {
+			pageData.SetFieldErrors(map[string][]string{"roasted_at": {"Invalid date"}})
+			ui.Toast(rw, ui.Warning, "Bad request")
+			return
+		}
+	}
+
+	bean, err := c.repo.FindGreenBean(req.GreenBean, user.ID)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Green bean not found")
+		return
+	}
+
+	batch := roasting.RoastBatch{
+		RoastedAt:       roastedAt,
+		ChargeWeight:    req.ChargeWeight,
+		DropWeight:      req.DropWeight,
+		FirstCrack:      time.Duration(req.FirstCrack) * time.Second,
+		DevelopmentTime: time.Duration(req.DevelopmentTime) * time.Second,
+		EndTemperature:  req.EndTemperature,
+		Notes:           req.Notes,
+		GreenBeanID:     bean.ID,
+		UserID:          user.ID,
+	}
+
+	if err := c.repo.CreateBatch(&batch); err != nil {
+		if errors.Is(err, roasting.ErrInsufficientStock) {
+			pageData.SetFieldErrors(map[string][]string{
+				"charge_weight": {fmt.Sprintf("Only %gg of %s left in stock", bean.Weight, bean.Name)},
+			})
+		}
+		ui.Toast(rw, ui.Warning, "Failed to log roast")
+		return
+	}
+
+	pageData = c.newBatchesData(user)
+	pageData.Form = createBatchRequest{RoastedAt: time.Now().Format(time.DateOnly)}
+
+	ui.Toast(rw, ui.Success, "Roast logged")
+}
+
+type finishBatchRequest struct {
+	Name       string  `json:"name" validate:"required"`
+	Roast      uint8   `json:"roast" validate:"required,min=1,max=7"`
+	DropWeight float64 `json:"drop_weight" validate:"gt=0"`
+}
+
+// FinishBatch turns a roast batch into a coffee roasted by the user themselves
+//
+// The origin details of the coffee are copied over from the green bean
+func (c Controller) FinishBatch(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	pageData := c.newBatchesData(user)
+	pageData.Form = createBatchRequest{RoastedAt: time.Now().Format(time.DateOnly)}
+	defer func() {
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	id, err := server.PathID(r)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Roast not found")
+		return
+	}
+
+	batch, err := c.repo.FindBatch(id, user.ID)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Roast not found")
+		return
+	}
+
+	if batch.Finished() {
+		ui.Toast(rw, ui.Warning, "Roast has already been finished")
+		return
+	}
+
+	var req finishBatchRequest
+	if err := server.UnmarshalBody(r, &req); err != nil {
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	if err := server.ValidateRequest(req); err != nil {
+		ui.Toast(rw, ui.Warning, "A name, roast level and drop weight are needed to finish the roast")
+		return
+	}
+
+	if req.DropWeight > batch.ChargeWeight {
+		ui.Toast(rw, ui.Warning, "The drop weight cannot be more than the charge weight")
+		return
+	}
+
+	roaster, err := c.coffeeRepo.FindHomeRoaster(user)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to finish roast")
+		return
+	}
+
+	bean := batch.GreenBean
+	coffeeModel := coffee.Coffee{
+		Name:     req.Name,
+		Roast:    coffee.RoastLevel(req.Roast),
+		Caffeine: coffee.FullCaf,
+		Notes:    fmt.Sprintf("Roasted on %s from %s", batch.RoastedAt.Format("02 Jan 2006"), bean.Name),
+		Country:  bean.Country,
+		Region:   bean.Region,
+		Process:  bean.Process,
+		Roaster:  *roaster,
+		User:     *user,
+	}
+	if bean.Varietal != "" {
+		coffeeModel.Varietals = coffee.StringList{bean.Varietal}
+	}
+
+	batch.DropWeight = req.DropWeight
+	if err := c.repo.FinishBatch(batch, &coffeeModel); err != nil {
+		if errors.Is(err, roasting.ErrBatchFinished) {
+			ui.Toast(rw, ui.Warning, "Roast has already been finished")
+		} else {
+			ui.Toast(rw, ui.Warning, "Failed to finish roast")
+		}
+		return
+	}
+
+	pageData.Batches = c.repo.IndexBatchesForUser(user)
+	ui.Toast(rw, ui.Success, "Roast finished, "+coffeeModel.Name+" added to your coffees")
+}
+
+func (c Controller) DeleteBatch(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	pageData := c.newBatchesData(user)
+	pageData.Form = createBatchRequest{RoastedAt: time.Now().Format(time.DateOnly)}
+	defer func() {
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	id, err := server.PathID(r)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Roast not found")
+		return
+	}
+
+	batch, err := c.repo.FindBatch(id, user.ID)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Roast not found")
+		return
+	}
+
+	if batch.Finished() {
+		ui.Toast(rw, ui.Warning, "Finished roasts cannot be deleted")
+		return
+	}
+
+	if err := c.repo.DeleteBatch(batch); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to delete roast")
+		return
+	}
+
+	pageData.Batches = c.repo.IndexBatchesForUser(user)
+	pageData.Beans = c.repo.IndexGreenBeansForUser(user)
+	ui.Toast(rw, ui.Success, "Roast deleted, the beans have been returned to stock")
+}
@@ -0,0 +1,89 @@
+package roasting_controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/roasting"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+// maxProfileSize is the largest roast profile that will be accepted for import
+const maxProfileSize = 5 << 20
+
+// ImportProfile reads an Artisan .alog file and attaches its roast curve to the batch
+//
+// The batch timings are updated from the events marked in the profile
+func (c Controller) ImportProfile(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	pageData := c.newBatchesData(user)
+	pageData.Form = createBatchRequest{RoastedAt: time.Now().Format(time.DateOnly)}
+	defer func() {
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	id, err := server.PathID(r)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Roast not found")
+		return
+	}
+
+	batch, err := c.repo.FindBatch(id, user.ID)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Roast not found")
+		return
+	}
+
+	data, _, err := server.ReadUpload(r, "profile", &server.UploadProps{
+		Ext: []string{".alog"},
+	}, maxProfileSize)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, err.Error())
+		return
+	}
+
+	profile, err := roasting.ParseArtisan(data)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, err.Error())
+		return
+	}
+
+	if err := c.repo.SaveProfile(batch, profile); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to import roast profile")
+		return
+	}
+
+	pageData.Batches = c.repo.IndexBatchesForUser(user)
+	ui.Toast(rw, ui.Success, "Roast profile imported")
+}
+
+func (c Controller) DeleteProfile(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	pageData := c.newBatchesData(user)
+	pageData.Form = createBatchRequest{RoastedAt: time.Now().Format(time.DateOnly)}
+	defer func() {
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	id, err := server.PathID(r)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Roast not found")
+		return
+	}
+
+	batch, err := c.repo.FindBatch(id, user.ID)
+	if err != nil {
+		ui.Toast(rw, ui.Warning, "Roast not found")
+		return
+	}
+
+	if err := c.repo.DeleteProfile(batch); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to remove roast profile")
+		return
+	}
+
+	pageData.Batches = c.repo.IndexBatchesForUser(user)
+	ui.Toast(rw, ui.Success, "Roast profile removed")
+}
@@ -0,0 +1,99 @@
+package roasting
+
+import (
+	"time"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/coffee"
+	"github.com/indeedhat/barista/internal/database/model"
+	"github.com/indeedhat/barista/internal/types"
+)
+
+// GreenBean is a stock of unroasted coffee held for home roasting
+type GreenBean struct {
+	model.SoftDelete
+
+	Name     string
+	Supplier string
+	Country  string
+	Region   string
+	Varietal string
+	Process  types.ProcessMethod
+	// Weight is the amount of stock remaining in grams
+	Weight     float64
+	PricePerKg float64
+	Notes      string
+
+	Batches []RoastBatch
+
+	UserID uint
+	User   auth.User
+}
+
+// StockValue is the cost of the remaining stock
+func (b GreenBean) StockValue() float64 {
+	return b.Weight / 1000 * b.PricePerKg
+}
+
+// RoastBatch is the log of a single roast of green beans
+type RoastBatch struct {
+	model.SoftDelete
+
+	RoastedAt time.Time
+	// ChargeWeight and DropWeight are the weight of the beans going in and out of the roaster in grams
+	ChargeWeight float64
+	DropWeight   float64
+	// FirstCrack is the time from charge to first crack
+	FirstCrack time.Duration
+	// DevelopmentTime is the time from first crack to drop
+	DevelopmentTime time.Duration
+	// EndTemperature is the bean temperature at drop in celsius
+	EndTemperature float64
+	Notes          string
+
+	// Profile is the temperature curve imported from roasting software
+	Profile *RoastProfile
+
+	GreenBeanID uint `gorm:"index"`
+	GreenBean   GreenBean
+
+	// CoffeeID is set once the batch has been finished and turned into a coffee
+	CoffeeID *uint
+	Coffee   *coffee.Coffee
+
+	UserID uint
+	User   auth.User
+}
+
+// Finished checks if a coffee has been created from the batch
+func (b RoastBatch) Finished() bool {
+	return b.CoffeeID != nil
+}
+
+// WeightLoss is the percentage of the charge weight lost during the roast
+func (b RoastBatch) WeightLoss() float64 {
+	if b.ChargeWeight == 0 || b.DropWeight == 0 {
+		return 0
+	}
+
+	return (b.ChargeWeight - b.DropWeight) / b.ChargeWeight * 100
+}
+
+// TotalTime is the time from charge to drop
+func (b RoastBatch) TotalTime() time.Duration {
+	return b.FirstCrack + b.DevelopmentTime
+}
+
+// DevelopmentRatio is the percentage of the roast spent after first crack
+func (b RoastBatch) DevelopmentRatio() float64 {
+	if b.TotalTime() == 0 {
+		return 0
+	}
+
+	return float64(b.DevelopmentTime) / float64(b.TotalTime()) * 100
+}
+
+// Cost is the price of the green beans that went into the batch
+func (b RoastBatch) Cost() float64 {
+	return b.ChargeWeight / 1000 * b.GreenBean.PricePerKg
+}
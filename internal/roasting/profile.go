@@ -0,0 +1,286 @@
+package roasting
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/indeedhat/barista/internal/database/model"
+)
+
+// RoastProfile is the temperature curve recorded by roasting software for a batch
+//
+// All times are relative to charge and all temperatures are in celsius
+type RoastProfile struct {
+	model.SoftDelete
+
+	Title string
+
+	// Times is the offset of each sample in seconds
+	Times    FloatList
+	BeanTemp FloatList
+	EnvTemp  FloatList
+
+	// The event times are zero when the event was not marked during the roast
+	DryEnd        time.Duration
+	FirstCrack    time.Duration
+	FirstCrackEnd time.Duration
+	Drop          time.Duration
+
+	ChargeTemp float64
+	DropTemp   float64
+
+	RoastBatchID uint `gorm:"uniqueIndex"`
+}
+
+// RoastPhase is a single stage of the roast
+type RoastPhase struct {
+	Name     string
+	Start    time.Duration
+	Duration time.Duration
+	Percent  float64
+}
+
+// Phases splits the roast into its drying, maillard and development stages
+//
+// Stages whose start or end event was not marked are left out
+func (p RoastProfile) Phases() []RoastPhase {
+	var phases []RoastPhase
+	add := func(name string, from, to time.Duration) {
+		if to <= from || p.Drop == 0 {
+			return
+		}
+
+		phases = append(phases, RoastPhase{
+			Name:     name,
+			Start:    from,
+			Duration: to - from,
+			Percent:  float64(to-from) / float64(p.Drop) * 100,
+		})
+	}
+
+	if p.DryEnd > 0 {
+		add("Drying", 0, p.DryEnd)
+		if p.FirstCrack > 0 {
+			add("Maillard", p.DryEnd, p.FirstCrack)
+		}
+	}
+	if p.FirstCrack > 0 {
+		add("Development", p.FirstCrack, p.Drop)
+	}
+
+	return phases
+}
+
+// Chart builds the svg line chart for the profile
+func (p RoastProfile) Chart() ProfileChart {
+	return NewProfileChart(p)
+}
+
+// ApplyTo copies the first crack, development time and end temperature from the profile onto the batch
+func (p RoastProfile) ApplyTo(batch *RoastBatch) {
+	if p.FirstCrack > 0 {
+		batch.FirstCrack = p.FirstCrack
+		batch.DevelopmentTime = p.Drop - p.FirstCrack
+	}
+
+	if p.DropTemp > 0 {
+		batch.EndTemperature = p.DropTemp
+	}
+}
+
+// FloatList is a list of numbers stored as json
+type FloatList []float64
+
+func (l FloatList) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
+
+func (l *FloatList) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	case nil:
+		*l = nil
+		return nil
+	}
+	return errors.New("invalid data type")
+}
+
+const (
+	profileWidth   = 600
+	profileHeight  = 300
+	profilePadLeft = 40
+	profilePadBot  = 24
+	profilePadTop  = 16
+	profilePadEnd  = 10
+	// profileMaxTicks is the most grid lines drawn on either axis, the step between ticks is widened
+	// to keep within it
+	profileMaxTicks = 20
+)
+
+// ChartTick is a labelled grid line on one of the chart axes
+type ChartTick struct {
+	Pos   float64
+	Label string
+}
+
+// ChartEvent is a marked roast event drawn as a vertical line
+type ChartEvent struct {
+	X     float64
+	Y     float64
+	Label string
+}
+
+// ChartBand is a shaded area behind the curves marking a phase of the roast
+type ChartBand struct {
+	X      float64
+	Width  float64
+	Height float64
+	Name   string
+}
+
+// ProfileChart holds the precomputed geometry for drawing a roast profile as an svg line chart
+type ProfileChart struct {
+	Width  int
+	Height int
+	// Left, Right, Top and Bottom are the edges of the plot area
+	Left   float64
+	Right  float64
+	Top    float64
+	Bottom float64
+
+	XTicks []ChartTick
+	YTicks []ChartTick
+	Bands  []ChartBand
+	Events []ChartEvent
+
+	// BeanTemp and EnvTemp are the points attributes of the svg polylines
+	BeanTemp string
+	EnvTemp  string
+}
+
+// NewProfileChart builds the line chart for the profile
+//
+// The temperature axis is fitted to the readings in steps of 50 degrees and the time axis is
+// marked every minute, or every other minute for long roasts. The steps are widened for profiles
+// that would otherwise need more than profileMaxTicks grid lines
+func NewProfileChart(p RoastProfile) ProfileChart {
+	chart := ProfileChart{
+		Width:  profileWidth,
+		Height: profileHeight,
+		Left:   profilePadLeft,
+		Right:  profileWidth - profilePadEnd,
+		Top:    profilePadTop,
+		Bottom: profileHeight - profilePadBot,
+	}
+
+	if len(p.Times) == 0 {
+		return chart
+	}
+
+	duration := p.Times[len(p.Times)-1]
+	xMax := math.Max(math.Ceil(duration/60)*60, 60)
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, temps := range []FloatList{p.BeanTemp, p.EnvTemp} {
+		for _, t := range temps {
+			if t <= 0 {
+				continue
+			}
+			lo, hi = math.Min(lo, t), math.Max(hi, t)
+		}
+	}
+	if math.IsInf(lo, 0) {
+		lo, hi = 0, 250
+	}
+	yMin := math.Floor(lo/50) * 50
+	yMax := math.Max(math.Ceil(hi/50)*50, yMin+50)
+
+	x := func(seconds float64) float64 {
+		return math.Round((chart.Left+seconds/xMax*(chart.Right-chart.Left))*10) / 10
+	}
+	y := func(temp float64) float64 {
+		return math.Round((chart.Bottom-(temp-yMin)/(yMax-yMin)*(chart.Bottom-chart.Top))*10) / 10
+	}
+
+	step := 60.0
+	if xMax > 20*60 {
+		step = 120
+	}
+	step = math.Max(step, math.Ceil(xMax/profileMaxTicks/60)*60)
+	for i := 0; i <= profileMaxTicks && float64(i)*step <= xMax; i++ {
+		s := float64(i) * step
+		chart.XTicks = append(chart.XTicks, ChartTick{x(s), fmt.Sprint(int(s / 60))})
+	}
+
+	tempStep := math.Max(50, math.Ceil((yMax-yMin)/profileMaxTicks/50)*50)
+	for i := 0; i <= profileMaxTicks && yMin+float64(i)*tempStep <= yMax; i++ {
+		t := yMin + float64(i)*tempStep
+		chart.YTicks = append(chart.YTicks, ChartTick{y(t), fmt.Sprint(int(t))})
+	}
+
+	for _, phase := range p.Phases() {
+		chart.Bands = append(chart.Bands, ChartBand{
+			X:      x(phase.Start.Seconds()),
+			Width:  x((phase.Start + phase.Duration).Seconds()) - x(phase.Start.Seconds()),
+			Height: chart.Bottom - chart.Top,
+			Name:   phase.Name,
+		})
+	}
+
+	for _, event := range []struct {
+		label string
+		at    time.Duration
+	}{
+		{"DE", p.DryEnd},
+		{"FCs", p.FirstCrack},
+		{"FCe", p.FirstCrackEnd},
+		{"Drop", p.Drop},
+	} {
+		if event.at == 0 {
+			continue
+		}
+		chart.Events = append(chart.Events, ChartEvent{
+			X:     x(event.at.Seconds()),
+			Y:     y(p.tempAt(event.at)),
+			Label: event.label,
+		})
+	}
+
+	chart.BeanTemp = profilePolyline(p.Times, p.BeanTemp, x, y)
+	chart.EnvTemp = profilePolyline(p.Times, p.EnvTemp, x, y)
+
+	return chart
+}
+
+// tempAt finds the bean temperature of the first sample at or after the given time
+func (p RoastProfile) tempAt(at time.Duration) float64 {
+	for i, t := range p.Times {
+		if t >= at.Seconds() && i < len(p.BeanTemp) {
+			return p.BeanTemp[i]
+		}
+	}
+
+	return p.DropTemp
+}
+
+// profilePolyline converts a curve into the points attribute of an svg polyline, missing readings
+// are skipped
+func profilePolyline(times, temps FloatList, x, y func(float64) float64) string {
+	points := make([]string, 0, len(times))
+	for i, t := range times {
+		if i >= len(temps) || temps[i] <= 0 {
+			continue
+		}
+		points = append(points, fmt.Sprintf("%.1f,%.1f", x(t), y(temps[i])))
+	}
+
+	return strings.Join(points, " ")
+}
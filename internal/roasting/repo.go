@@ -0,0 +1,228 @@
+package roasting
+
+import (
+	"errors"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/coffee"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInsufficientStock = errors.New("not enough green beans in stock")
+	ErrBatchFinished     = errors.New("roast has already been finished")
+)
+
+type Repository interface {
+	IndexGreenBeansForUser(*auth.User) []GreenBean
+	FindGreenBean(uint, ...uint) (*GreenBean, error)
+	SaveGreenBean(*GreenBean) error
+	DeleteGreenBean(*GreenBean) error
+
+	IndexBatchesForUser(*auth.User) []RoastBatch
+	FindBatch(uint, ...uint) (*RoastBatch, error)
+	CreateBatch(*RoastBatch) error
+	SaveBatch(*RoastBatch) error
+	FinishBatch(*RoastBatch, *coffee.Coffee) error
+	DeleteBatch(*RoastBatch) error
+
+	SaveProfile(*RoastBatch, *RoastProfile) error
+	DeleteProfile(*RoastBatch) error
+}
+
+type SqliteRepository struct {
+	db *gorm.DB
+}
+
+func NewSqliteRepo(db *gorm.DB) Repository {
+	return SqliteRepository{db}
+}
+
+// IndexGreenBeansForUser implements Repository.
+func (r SqliteRepository) IndexGreenBeansForUser(user *auth.User) []GreenBean {
+	var beans []GreenBean
+
+	r.db.Preload("Batches").
+		Where("user_id = ?", user.ID).
+		Order("name ASC").
+		Find(&beans)
+
+	return beans
+}
+
+// FindGreenBean implements Repository.
+func (r SqliteRepository) FindGreenBean(id uint, userId ...uint) (*GreenBean, error) {
+	var bean GreenBean
+
+	tx := r.db.Preload("Batches")
+	if len(userId) > 0 {
+		tx = tx.Where("user_id = ?", userId[0])
+	}
+
+	if err := tx.First(&bean, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &bean, nil
+}
+
+// SaveGreenBean implements Repository.
+func (r SqliteRepository) SaveGreenBean(bean *GreenBean) error {
+	return r.db.Omit("Batches").Save(bean).Error
+}
+
+// DeleteGreenBean implements Repository.
+func (r SqliteRepository) DeleteGreenBean(bean *GreenBean) error {
+	return r.db.Delete(bean).Error
+}
+
+// IndexBatchesForUser implements Repository.
+func (r SqliteRepository) IndexBatchesForUser(user *auth.User) []RoastBatch {
+	var batches []RoastBatch
+
+	r.db.Preload("GreenBean", func(tx *gorm.DB) *gorm.DB {
+		return tx.Unscoped()
+	}).
+		Preload("Coffee").
+		Preload("Profile").
+		Where("user_id = ?", user.ID).
+		Order("roasted_at DESC, id DESC").
+		Find(&batches)
+
+	return batches
+}
+
+// FindBatch implements Repository.
+func (r SqliteRepository) FindBatch(id uint, userId ...uint) (*RoastBatch, error) {
+	var batch RoastBatch
+
+	tx := r.db.Preload("GreenBean", func(tx *gorm.DB) *gorm.DB {
+		return tx.Unscoped()
+	}).
+		Preload("Coffee").
+		Preload("Profile")
+	if len(userId) > 0 {
+		tx = tx.Where("user_id = ?", userId[0])
+	}
+
+	if err := tx.First(&batch, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &batch, nil
+}
+
+// CreateBatch implements Repository.
+//
+// The charge weight is taken out of the green bean stock
+func (r SqliteRepository) CreateBatch(batch *RoastBatch) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&GreenBean{}).
+			Where("id = ? AND weight >= ?", batch.GreenBeanID, batch.ChargeWeight).
+			Update("weight", gorm.Expr("weight - ?", batch.ChargeWeight))
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrInsufficientStock
+		}
+
+		return tx.Omit("GreenBean", "Coffee", "Profile").Create(batch).Error
+	})
+}
+
+// SaveBatch implements Repository.
+func (r SqliteRepository) SaveBatch(batch *RoastBatch) error {
+	return r.db.Omit("GreenBean", "Coffee", "Profile").Save(batch).Error
+}
+
+// FinishBatch implements Repository.
+//
+// The coffee is only kept if the batch has not already been finished so a double submit cannot
+// create two coffees from the same roast
+func (r SqliteRepository) FinishBatch(batch *RoastBatch, coffeeModel *coffee.Coffee) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(coffeeModel).Error; err != nil {
+			return err
+		}
+
+		res := tx.Model(&RoastBatch{}).
+			Where("id = ? AND coffee_id IS NULL", batch.ID).
+			Updates(map[string]any{
+				"coffee_id":   coffeeModel.ID,
+				"drop_weight": batch.DropWeight,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrBatchFinished
+		}
+
+		batch.CoffeeID = &coffeeModel.ID
+		return nil
+	})
+}
+
+// DeleteBatch implements Repository.
+//
+// The charge weight is returned to the green bean stock
+func (r SqliteRepository) DeleteBatch(batch *RoastBatch) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&GreenBean{}).
+			Where("id = ?", batch.GreenBeanID).
+			Update("weight", gorm.Expr("weight + ?", batch.ChargeWeight)).
+			Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Unscoped().
+			Where("roast_batch_id = ?", batch.ID).
+			Delete(&RoastProfile{}).
+			Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(batch).Error
+	})
+}
+
+// SaveProfile implements Repository.
+//
+// Any existing profile on the batch is replaced and the batch is updated with the timings from
+// the new profile
+func (r SqliteRepository) SaveProfile(batch *RoastBatch, profile *RoastProfile) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Where("roast_batch_id = ?", batch.ID).
+			Delete(&RoastProfile{}).
+			Error
+		if err != nil {
+			return err
+		}
+
+		profile.RoastBatchID = batch.ID
+		if err := tx.Create(profile).Error; err != nil {
+			return err
+		}
+
+		profile.ApplyTo(batch)
+		batch.Profile = profile
+
+		return tx.Omit("GreenBean", "Coffee", "Profile").Save(batch).Error
+	})
+}
+
+// DeleteProfile implements Repository.
+func (r SqliteRepository) DeleteProfile(batch *RoastBatch) error {
+	return r.db.Unscoped().
+		Where("roast_batch_id = ?", batch.ID).
+		Delete(&RoastProfile{}).
+		Error
+}
+
+var _ Repository = (*SqliteRepository)(nil)
@@ -0,0 +1,53 @@
+package roasting
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/indeedhat/barista/internal/coffee"
+	"gorm.io/gorm"
+)
+
+func TestFinishBatch(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(coffee.Coffee{}, GreenBean{}, RoastBatch{}, RoastProfile{}); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewSqliteRepo(db)
+	batch := RoastBatch{ChargeWeight: 250}
+	if err := db.Create(&batch).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// both submits loaded the batch before either finished it
+	first, second := batch, batch
+	first.DropWeight = 210
+	second.DropWeight = 200
+
+	if err := repo.FinishBatch(&first, &coffee.Coffee{Name: "first"}); err != nil {
+		t.Fatalf("first finish: %s", err)
+	}
+	if err := repo.FinishBatch(&second, &coffee.Coffee{Name: "second"}); !errors.Is(err, ErrBatchFinished) {
+		t.Fatalf("second finish: got %v, want %v", err, ErrBatchFinished)
+	}
+
+	var coffees []coffee.Coffee
+	db.Find(&coffees)
+	if len(coffees) != 1 || coffees[0].Name != "first" {
+		t.Errorf("coffees: got %+v", coffees)
+	}
+
+	saved, err := repo.FindBatch(batch.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.CoffeeID == nil || *saved.CoffeeID != coffees[0].ID || saved.DropWeight != 210 {
+		t.Errorf("batch: got coffee %v drop weight %g", saved.CoffeeID, saved.DropWeight)
+	}
+}
@@ -8,8 +8,13 @@ import (
 	"github.com/indeedhat/barista/internal/auth/controllers"
 	"github.com/indeedhat/barista/internal/brewer/controllers"
 	"github.com/indeedhat/barista/internal/coffee/controllers"
+	"github.com/indeedhat/barista/internal/equipment/controllers"
+	"github.com/indeedhat/barista/internal/photo/controllers"
+	"github.com/indeedhat/barista/internal/roasting/controllers"
 	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/tasting/controllers"
 	"github.com/indeedhat/barista/internal/ui"
+	"github.com/indeedhat/barista/internal/uploads/controllers"
 )
 
 func BuildRoutes(
@@ -17,14 +22,26 @@ func BuildRoutes(
 	coffeeController coffee_controllers.Controller,
 	authController auth_controllers.Controller,
 	brewerController brewer_controllers.Controller,
+	uploadsController uploads_controllers.Controller,
+	photoController photo_controllers.Controller,
+	tastingController tasting_controllers.Controller,
+	roastingController roasting_controllers.Controller,
+	equipmentController equipment_controllers.Controller,
 	authRepo auth.Repository,
 ) *http.ServeMux {
 	r.Handle("GET /assets/", http.StripPrefix("/assets/", http.FileServer(http.FS(assets.Public))))
 
+	r.HandleFunc("GET /login/oidc/callback", authController.OidcCallback)
+
+	r.HandleFunc("GET /setup", authController.ViewSetup)
+	r.HandleFunc("POST /setup", authController.Setup)
+
 	guest := r.Group("", auth.IsGuestMiddleware(auth.UI, authRepo))
 	{
 		guest.HandleFunc("GET /login", authController.ViewLogin)
 		guest.HandleFunc("POST /login", authController.Login)
+		guest.HandleFunc("POST /login/2fa", authController.LoginTotp)
+		guest.HandleFunc("GET /login/oidc", authController.OidcLogin)
 
 		guest.HandleFunc("GET /register", authController.ViewRegister)
 		guest.HandleFunc("POST /register", authController.Register)
@@ -32,9 +49,7 @@ func BuildRoutes(
 
 	private := r.Group("", auth.IsLoggedInMiddleware(auth.UI, authRepo))
 	{
-		private.Handle("GET /uploads/",
-			http.StripPrefix("/uploads/", http.FileServer(http.Dir("data/uploads"))),
-		)
+		private.HandleFunc("GET /uploads/{key...}", uploadsController.ViewUpload)
 
 		private.HandleFunc("GET /", func(w http.ResponseWriter, r *http.Request) {
 			if r.URL.Path != "/" && r.URL.Path != "/home" {
@@ -51,6 +66,14 @@ func BuildRoutes(
 
 		private.HandleFunc("GET /user/settings", authController.ViewSettings)
 		private.HandleFunc("POST /user/change-password", authController.ChangePassword)
+		private.HandleFunc("DELETE /user/sessions", authController.RevokeOtherSessions)
+		private.HandleFunc("DELETE /user/sessions/{id}", authController.RevokeSession)
+		private.HandleFunc("POST /user/2fa/setup", authController.SetupTotp)
+		private.HandleFunc("POST /user/2fa/enable", authController.EnableTotp)
+		private.HandleFunc("POST /user/2fa/disable", authController.DisableTotp)
+		private.HandleFunc("POST /user/2fa/recovery-codes", authController.RegenerateRecoveryCodes)
+		private.HandleFunc("POST /user/oidc/link", authController.OidcLink)
+		private.HandleFunc("POST /user/oidc/unlink", authController.OidcUnlink)
 
 		private.HandleFunc("GET /coffees", coffeeController.ViewCoffees)
 		private.HandleFunc("POST /coffees", coffeeController.CreateCoffee)
@@ -63,9 +86,22 @@ func BuildRoutes(
 		private.HandleFunc("POST /coffees/{id}/recipes", coffeeController.CreateRecipe)
 		private.HandleFunc("PUT /coffees/{coffee_id}/recipes/{recipe_id}", coffeeController.UpdateRecipe)
 		private.HandleFunc("DELETE /coffees/{coffee_id}/recipes/{recipe_id}", coffeeController.DeleteRecipe)
+		private.HandleFunc("GET /coffees/{coffee_id}/recipes/{recipe_id}/shots", coffeeController.ViewShots)
+		private.HandleFunc("POST /coffees/{coffee_id}/recipes/{recipe_id}/shots", coffeeController.ImportShot)
+		private.HandleFunc("DELETE /coffees/{coffee_id}/recipes/{recipe_id}/shots/{shot_id}", coffeeController.DeleteShot)
+		private.HandleFunc("POST /coffees/{coffee_id}/recipes/{recipe_id}/brews", coffeeController.LogBrew)
+
+		private.HandleFunc("POST /coffees/{id}/cuppings", coffeeController.CreateCupping)
+		private.HandleFunc("DELETE /coffees/{coffee_id}/cuppings/{cupping_id}", coffeeController.DeleteCupping)
 
 		private.HandleFunc("GET /recipes", coffeeController.ViewRecipes)
 
+		private.HandleFunc("GET /drinks", coffeeController.ViewDrinkTypes)
+		private.HandleFunc("POST /drinks", coffeeController.CreateDrinkType)
+		private.HandleFunc("DELETE /drinks/{id}", coffeeController.DeleteDrinkType)
+
+		private.HandleFunc("GET /stats", coffeeController.ViewStats)
+
 		private.HandleFunc("GET /flavours", coffeeController.ViewFlavours)
 		private.HandleFunc("POST /flavours", coffeeController.CreateFlavourProfile)
 		private.HandleFunc("POST /flavours/input", coffeeController.CreateFlavourFromComponent)
@@ -80,6 +116,9 @@ func BuildRoutes(
 		private.HandleFunc("GET /baskets/select", brewerController.BasketsSelect)
 		private.HandleFunc("GET /brewers/select", brewerController.BrewersSelect)
 
+		private.HandleFunc("POST /brewers/types", brewerController.CreateBrewerType)
+		private.HandleFunc("DELETE /brewers/types/{id}", brewerController.DeleteBrewerType)
+
 		private.HandleFunc("GET /brewers", brewerController.ViewBrewers)
 		private.HandleFunc("POST /brewers", brewerController.CreateBrewer)
 		private.HandleFunc("GET /brewers/{id}", brewerController.ViewBrewer)
@@ -92,8 +131,70 @@ func BuildRoutes(
 		private.HandleFunc("PUT /brewers/{brewer_id}/baskets/{basket_id}", brewerController.UpdateBasket)
 		private.HandleFunc("DELETE /brewers/{brewer_id}/baskets/{basket_id}", brewerController.DeleteBasket)
 
+		private.HandleFunc("GET /brewers/{id}/maintenance", brewerController.ViewMaintenance)
+		private.HandleFunc("POST /brewers/{id}/brews", brewerController.LogBrew)
+		private.HandleFunc("POST /brewers/{id}/maintenance", brewerController.CreateMaintenanceTask)
+		private.HandleFunc("DELETE /brewers/{brewer_id}/maintenance/{task_id}", brewerController.DeleteMaintenanceTask)
+		private.HandleFunc("POST /brewers/{brewer_id}/maintenance/{task_id}/log", brewerController.LogMaintenance)
+		private.HandleFunc("DELETE /brewers/{brewer_id}/maintenance/{task_id}/log/{log_id}", brewerController.DeleteMaintenanceLog)
+
+		private.HandleFunc("GET /equipment/select", equipmentController.EquipmentSelect)
+		private.HandleFunc("GET /equipment/new", equipmentController.NewEquipment)
+		private.HandleFunc("GET /equipment", equipmentController.ViewEquipment)
+		private.HandleFunc("POST /equipment", equipmentController.CreateEquipment)
+		private.HandleFunc("PUT /equipment/{id}", equipmentController.UpdateEquipment)
+		private.HandleFunc("DELETE /equipment/{id}", equipmentController.DeleteEquipment)
+
+		private.HandleFunc("GET /photos/{type}/{id}", photoController.ViewGallery)
+		private.HandleFunc("POST /photos/{type}/{id}", photoController.UploadPhotos)
+		private.HandleFunc("PUT /photos/{id}", photoController.UpdatePhoto)
+		private.HandleFunc("POST /photos/{id}/cover", photoController.SetCoverPhoto)
+		private.HandleFunc("POST /photos/{id}/move/{direction}", photoController.MovePhoto)
+		private.HandleFunc("DELETE /photos/{id}", photoController.DeletePhoto)
+
+		private.HandleFunc("GET /tastings", tastingController.ViewSessions)
+		private.HandleFunc("POST /tastings", tastingController.CreateSession)
+		private.HandleFunc("GET /tastings/{id}", tastingController.ViewSession)
+		private.HandleFunc("DELETE /tastings/{id}", tastingController.DeleteSession)
+		private.HandleFunc("POST /tastings/{id}/participants", tastingController.InviteParticipants)
+		private.HandleFunc("POST /tastings/{id}/reveal", tastingController.RevealSession)
+		private.HandleFunc("POST /tastings/{id}/samples/{sample_id}", tastingController.ScoreSample)
+
+		private.HandleFunc("GET /roasting/beans", roastingController.ViewGreenBeans)
+		private.HandleFunc("POST /roasting/beans", roastingController.CreateGreenBean)
+		private.HandleFunc("GET /roasting/beans/{id}", roastingController.ViewGreenBean)
+		private.HandleFunc("PUT /roasting/beans/{id}", roastingController.UpdateGreenBean)
+		private.HandleFunc("DELETE /roasting/beans/{id}", roastingController.DeleteGreenBean)
+
+		private.HandleFunc("GET /roasting/roasts", roastingController.ViewBatches)
+		private.HandleFunc("POST /roasting/roasts", roastingController.CreateBatch)
+		private.HandleFunc("POST /roasting/roasts/{id}/finish", roastingController.FinishBatch)
+		private.HandleFunc("DELETE /roasting/roasts/{id}", roastingController.DeleteBatch)
+		private.HandleFunc("POST /roasting/roasts/{id}/profile", roastingController.ImportProfile)
+		private.HandleFunc("DELETE /roasting/roasts/{id}/profile", roastingController.DeleteProfile)
+
 		private.HandleFunc("POST /logout", authController.Logout)
 	}
 
+	invites := r.Group("/invites", auth.UserHasPermissionMiddleware(auth.UI, auth.LevelAdmin|auth.LevelMember, authRepo))
+	{
+		invites.HandleFunc("GET /", authController.ViewInvites)
+		invites.HandleFunc("POST /", authController.CreateInvite)
+		invites.HandleFunc("DELETE /{id}", authController.DeleteInvite)
+	}
+
+	admin := r.Group("/admin", auth.UserHasPermissionMiddleware(auth.UI, auth.LevelAdmin, authRepo))
+	{
+		admin.HandleFunc("GET /users", authController.ViewUsers)
+		admin.HandleFunc("GET /settings", authController.ViewInstanceSettings)
+		admin.HandleFunc("PUT /settings", authController.UpdateInstanceSettings)
+
+		admin.HandleFunc("GET /flavours", coffeeController.ViewFlavourAdmin)
+		admin.HandleFunc("GET /flavours/{id}", coffeeController.FlavourAdminForm)
+		admin.HandleFunc("PUT /flavours/{id}", coffeeController.UpdateFlavour)
+		admin.HandleFunc("POST /flavours/{id}/merge", coffeeController.MergeFlavour)
+		admin.HandleFunc("DELETE /flavours/{id}", coffeeController.DeleteFlavour)
+	}
+
 	return r.ServerMux()
 }
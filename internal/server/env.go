@@ -6,4 +6,7 @@ import (
 
 const (
 	CorsAllowHost dotenv.String = "CORS_ALLOW_HOST"
+	// Comma separated list of ips or cidr ranges of the reverse proxies in front of the app, the
+	// X-Forwarded-For header is only read from requests made by these
+	TrustedProxies dotenv.String = "TRUSTED_PROXIES"
 )
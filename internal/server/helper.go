@@ -6,8 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
+	"net"
 	"net/http"
-	"os"
+	"net/netip"
 	"path"
 	"reflect"
 	"slices"
@@ -15,6 +17,8 @@ import (
 	"strings"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/indeedhat/barista/internal/images"
+	"github.com/indeedhat/barista/internal/storage"
 	"github.com/indeedhat/barista/internal/ui"
 )
 
@@ -152,16 +156,146 @@ type UploadProps struct {
 	Mime     []string
 }
 
-func UploadFile(r *http.Request, formKey, savePath string, props *UploadProps) (string, error) {
+// UploadFile stores the uploaded file in the default storage backend under key with the files
+// extension appended
+//
+// The full storage key is returned
+func UploadFile(r *http.Request, formKey, key string, props *UploadProps) (string, error) {
+	file, ext, err := openUpload(r, formKey, props)
+	if file == nil || err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if err := storage.Default().Put(r.Context(), key+ext, file, storage.ContentType(ext)); err != nil {
+		return "", errors.New("file upload could not be saved on the server")
+	}
+
+	return key + ext, nil
+}
+
+// ReadUpload reads the contents of an uploaded file without storing it, this is for uploads that
+// are imported into the database rather than kept as files
+//
+// The name of the uploaded file is returned along with its contents, an error is returned if the
+// file is larger than maxSize bytes
+func ReadUpload(r *http.Request, formKey string, props *UploadProps, maxSize int64) ([]byte, string, error) {
+	file, _, err := openUpload(r, formKey, props)
+	if file == nil || err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		return nil, "", errors.New("file upload failed")
+	}
+
+	if int64(len(data)) > maxSize {
+		return nil, "", errors.New("file is too large")
+	}
+
+	return data, r.MultipartForm.File[formKey][0].Filename, nil
+}
+
+// maxImageUploadSize is the largest image that will be accepted by UploadImage
+const maxImageUploadSize = 20 << 20
+
+// maxMultipartMemory is the amount of a multipart body that will be held in memory, anything over
+// this is buffered to temp files
+const maxMultipartMemory = 32 << 20
+
+// UploadImage works the same as UploadFile but the image is processed into resized variants rather
+// than being stored as is
+//
+// The storage key of the full size variant is returned
+func UploadImage(r *http.Request, formKey, key string, props *UploadProps, variants []images.Variant) (string, error) {
+	file, _, err := openUpload(r, formKey, props)
+	if file == nil || err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	return processImage(r, file, key, variants)
+}
+
+// FormFiles returns the headers for all of the files uploaded under formKey
+//
+// An error is returned if more than limit files were sent
+func FormFiles(r *http.Request, formKey string, limit int) ([]*multipart.FileHeader, error) {
+	if err := r.ParseMultipartForm(maxMultipartMemory); err != nil {
+		return nil, errors.New("file upload failed")
+	}
+
+	headers := r.MultipartForm.File[formKey]
+	if len(headers) > limit {
+		return nil, fmt.Errorf("no more than %d files can be uploaded at once", limit)
+	}
+
+	return headers, nil
+}
+
+// UploadImageFile works the same as UploadImage but for a single file from a multi file upload
+// found via FormFiles
+func UploadImageFile(
+	r *http.Request,
+	header *multipart.FileHeader,
+	key string,
+	props *UploadProps,
+	variants []images.Variant,
+) (string, error) {
+	file, err := header.Open()
+	if err != nil {
+		return "", errors.New("file upload failed")
+	}
+	defer file.Close()
+
+	if _, err := validateUpload(file, header, props); err != nil {
+		return "", err
+	}
+
+	return processImage(r, file, key, variants)
+}
+
+// processImage reads an uploaded image, enforcing the max size, and stores its resized variants
+func processImage(r *http.Request, file multipart.File, key string, variants []images.Variant) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(file, maxImageUploadSize+1))
+	if err != nil {
+		return "", errors.New("file upload failed")
+	}
+
+	if len(data) > maxImageUploadSize {
+		return "", errors.New("image is too large")
+	}
+
+	return images.Process(r.Context(), data, key, variants)
+}
+
+// openUpload opens the uploaded file and validates it against the upload props
+//
+// If the upload is optional and no file was sent then the returned file will be nil
+func openUpload(r *http.Request, formKey string, props *UploadProps) (multipart.File, string, error) {
 	file, header, err := r.FormFile(formKey)
 	if err != nil {
 		if props != nil && props.Optional && errors.Is(err, http.ErrMissingFile) {
-			return "", nil
+			return nil, "", nil
 		}
-		return "", errors.New("file upload failed")
+		return nil, "", errors.New("file upload failed")
+	}
+
+	ext, err := validateUpload(file, header, props)
+	if err != nil {
+		file.Close()
+		return nil, "", err
 	}
-	defer file.Close()
 
+	return file, ext, nil
+}
+
+// validateUpload checks the files extension and mime type against the upload props
+//
+// The lower case extension of the file is returned and the file is left at its start
+func validateUpload(file multipart.File, header *multipart.FileHeader, props *UploadProps) (string, error) {
 	ext := strings.ToLower(path.Ext(header.Filename))
 	if props != nil && len(props.Ext) > 0 {
 		if !slices.Contains(props.Ext, ext) {
@@ -183,21 +317,78 @@ func UploadFile(r *http.Request, formKey, savePath string, props *UploadProps) (
 
 	file.Seek(0, io.SeekStart)
 
-	_ = os.MkdirAll(path.Dir(savePath), os.ModePerm)
-	saveFile, err := os.Create(savePath + ext)
+	return ext, nil
+}
+
+func Redirect(rw http.ResponseWriter, r *http.Request, url string) {
+	http.Redirect(rw, r, url, http.StatusSeeOther)
+}
+
+// ClientIP makes a best effort attempt at finding the ip address of the client that made the request
+//
+// X-Forwarded-For is only read when the request comes from one of the TRUSTED_PROXIES, the hops are
+// walked from the right and the first one that is not a trusted proxy is the client. Anything to
+// the left of that could have been set by the client so it is ignored
+func ClientIP(r *http.Request) string {
+	client, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
-		return "", errors.New("file upload could not be saved on the server")
+		client = r.RemoteAddr
 	}
-	defer saveFile.Close()
 
-	_, err = io.Copy(saveFile, file)
-	if err != nil {
-		return "", errors.New("file upload could not be saved on the server")
+	trusted := trustedProxies()
+	if !isTrustedProxy(client, trusted) {
+		return client
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
 	}
 
-	return savePath + ext, nil
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if _, err := netip.ParseAddr(hop); err != nil {
+			// the proxy that added this hop is the last address that can be relied on
+			return client
+		}
+
+		client = hop
+		if !isTrustedProxy(hop, trusted) {
+			break
+		}
+	}
+
+	return client
 }
 
-func Redirect(rw http.ResponseWriter, r *http.Request, url string) {
-	http.Redirect(rw, r, url, http.StatusSeeOther)
+// trustedProxies parses the TRUSTED_PROXIES env, single ips are treated as a prefix of their full
+// length and invalid entries are ignored
+func trustedProxies() []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(TrustedProxies.Get(), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(entry); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+		}
+	}
+
+	return prefixes
+}
+
+func isTrustedProxy(ip string, trusted []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	return slices.ContainsFunc(trusted, func(prefix netip.Prefix) bool {
+		return prefix.Contains(addr)
+	})
 }
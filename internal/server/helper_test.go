@@ -0,0 +1,41 @@
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		trusted string
+		remote  string
+		fwd     []string
+		want    string
+	}{
+		{"no proxies", "", "203.0.113.5:1234", []string{"1.2.3.4"}, "203.0.113.5"},
+		{"untrusted remote", "10.0.0.1", "203.0.113.5:1234", []string{"1.2.3.4"}, "203.0.113.5"},
+		{"trusted remote", "10.0.0.1", "10.0.0.1:1234", []string{"198.51.100.7"}, "198.51.100.7"},
+		{"spoofed hop", "10.0.0.0/8", "10.0.0.1:1234", []string{"1.2.3.4, 198.51.100.7"}, "198.51.100.7"},
+		{"proxy chain", "10.0.0.0/8", "10.0.0.1:1234", []string{"1.2.3.4, 198.51.100.7, 10.0.0.2"}, "198.51.100.7"},
+		{"multiple headers", "10.0.0.0/8", "10.0.0.1:1234", []string{"1.2.3.4", "198.51.100.7"}, "198.51.100.7"},
+		{"all trusted", "10.0.0.0/8", "10.0.0.1:1234", []string{"10.0.0.3, 10.0.0.2"}, "10.0.0.3"},
+		{"invalid hop", "10.0.0.1", "10.0.0.1:1234", []string{"not-an-ip"}, "10.0.0.1"},
+		{"no header", "10.0.0.1", "10.0.0.1:1234", nil, "10.0.0.1"},
+		{"ipv6", "::1", "[::1]:1234", []string{"2001:db8::1"}, "2001:db8::1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(string(TrustedProxies), tc.trusted)
+
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tc.remote
+			for _, fwd := range tc.fwd {
+				r.Header.Add("X-Forwarded-For", fwd)
+			}
+
+			if got := ClientIP(r); got != tc.want {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package storage
+
+import "github.com/indeedhat/dotenv"
+
+const (
+	// Which backend uploads are stored in, either fs or s3
+	envBackend dotenv.String = "STORAGE_BACKEND"
+	envFsRoot  dotenv.String = "STORAGE_FS_ROOT"
+
+	defaultFsRoot = "data/uploads"
+)
+
+const (
+	// S3 compatible storage, requests are made path style (<endpoint>/<bucket>/<key>) so that it
+	// works with self hosted alternatives like minio
+	envS3Endpoint  dotenv.String = "S3_ENDPOINT"
+	envS3Region    dotenv.String = "S3_REGION"
+	envS3Bucket    dotenv.String = "S3_BUCKET"
+	envS3AccessKey dotenv.String = "S3_ACCESS_KEY"
+	envS3SecretKey dotenv.String = "S3_SECRET_KEY"
+
+	defaultS3Region = "us-east-1"
+)
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Filesystem stores files in a directory on the local disk
+type Filesystem struct {
+	root string
+}
+
+func NewFilesystem(root string) Filesystem {
+	return Filesystem{root}
+}
+
+// Put implements Storage.
+func (f Filesystem) Put(ctx context.Context, key string, data io.Reader, contentType string) error {
+	filePath, err := f.path(key)
+	if err != nil {
+		return err
+	}
+
+	_ = os.MkdirAll(filepath.Dir(filePath), os.ModePerm)
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, data)
+	return err
+}
+
+// Get implements Storage.
+func (f Filesystem) Get(ctx context.Context, key string) (*Object, error) {
+	filePath, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		file.Close()
+		return nil, ErrNotFound
+	}
+
+	return &Object{
+		Body:        file,
+		Size:        info.Size(),
+		ModTime:     info.ModTime(),
+		ContentType: ContentType(key),
+	}, nil
+}
+
+// Delete implements Storage.
+func (f Filesystem) Delete(ctx context.Context, key string) error {
+	filePath, err := f.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// List implements Storage.
+//
+// Only the directory the prefix sits in is walked rather than the whole upload root
+func (f Filesystem) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+
+	dir := f.root
+	if parent := path.Dir(prefix); parent != "." {
+		var err error
+		if dir, err = f.path(parent); err != nil {
+			return nil, err
+		}
+	}
+
+	err := filepath.WalkDir(dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(f.root, filePath)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, ObjectInfo{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+
+		return nil
+	})
+
+	return objects, err
+}
+
+// URL implements Storage.
+func (f Filesystem) URL(key string) string {
+	return "/" + ModelPath(key)
+}
+
+// path converts the key into a path on disk, keys that would escape the root dir are rejected
+func (f Filesystem) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if clean == "/" || clean[1:] != key {
+		return "", errors.New("invalid storage key")
+	}
+
+	return filepath.Join(f.root, filepath.FromSlash(clean)), nil
+}
+
+var _ Storage = (*Filesystem)(nil)
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFilesystemList(t *testing.T) {
+	store := NewFilesystem(t.TempDir())
+	ctx := context.Background()
+
+	for _, key := range []string{
+		"coffee/1.full.jpg",
+		"coffee/1.thumb.jpg",
+		"coffee/10.full.jpg",
+		"coffee/nested/1.full.jpg",
+		"roaster/1.full.jpg",
+		"root.jpg",
+	} {
+		if err := store.Put(ctx, key, strings.NewReader(key), ContentType(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		prefix string
+		keys   []string
+	}{
+		{"coffee/1.", []string{"coffee/1.full.jpg", "coffee/1.thumb.jpg"}},
+		{"coffee/n", []string{"coffee/nested/1.full.jpg"}},
+		{"roaster/", []string{"roaster/1.full.jpg"}},
+		{"brewer/1.", nil},
+		{"../coffee/1.", nil},
+		{"", []string{
+			"coffee/1.full.jpg", "coffee/1.thumb.jpg", "coffee/10.full.jpg", "coffee/nested/1.full.jpg",
+			"roaster/1.full.jpg", "root.jpg",
+		}},
+	} {
+		objects, _ := store.List(ctx, tc.prefix)
+
+		var keys []string
+		for _, obj := range objects {
+			keys = append(keys, obj.Key)
+		}
+		slices.Sort(keys)
+
+		if !slices.Equal(keys, tc.keys) {
+			t.Errorf("%q: got %v, want %v", tc.prefix, keys, tc.keys)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// Migrate copies every file from one backend to another
+//
+// If remove is set then each file is deleted from the source backend once it has been copied.
+// The number of files copied is returned
+func Migrate(ctx context.Context, from, to Storage, remove bool, progress func(key string)) (int, error) {
+	objects, err := from.List(ctx, "")
+	if err != nil {
+		return 0, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	for i, obj := range objects {
+		if err := copyFile(ctx, from, to, obj.Key); err != nil {
+			return i, fmt.Errorf("failed to copy %s: %w", obj.Key, err)
+		}
+
+		if remove {
+			if err := from.Delete(ctx, obj.Key); err != nil {
+				return i + 1, fmt.Errorf("failed to delete %s: %w", obj.Key, err)
+			}
+		}
+
+		if progress != nil {
+			progress(obj.Key)
+		}
+	}
+
+	return len(objects), nil
+}
+
+func copyFile(ctx context.Context, from, to Storage, key string) error {
+	obj, err := from.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer obj.Body.Close()
+
+	return to.Put(ctx, key, obj.Body, obj.ContentType)
+}
@@ -0,0 +1,339 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	s3Service       = "s3"
+	s3Algorithm     = "AWS4-HMAC-SHA256"
+	amzDateFormat   = "20060102T150405Z"
+	amzScopeFormat  = "20060102"
+	s3EmptyBodyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// S3 stores files in an s3 compatible bucket
+//
+// Requests are signed with AWS signature v4 and made path style so that self hosted alternatives
+// like minio can be used
+type S3 struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	client    *http.Client
+}
+
+// NewS3FromEnv creates a new s3 backend from the env config
+func NewS3FromEnv() (*S3, error) {
+	endpoint, err := url.Parse(envS3Endpoint.Get())
+	if err != nil || endpoint.Host == "" {
+		return nil, errors.New("S3_ENDPOINT must be a valid url")
+	}
+
+	if envS3Bucket.Get() == "" {
+		return nil, errors.New("S3_BUCKET is required")
+	}
+
+	return &S3{
+		endpoint:  endpoint,
+		region:    envS3Region.Get(defaultS3Region),
+		bucket:    envS3Bucket.Get(),
+		accessKey: envS3AccessKey.Get(),
+		secretKey: envS3SecretKey.Get(),
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Put implements Storage.
+func (s *S3) Put(ctx context.Context, key string, data io.Reader, contentType string) error {
+	body, err := io.ReadAll(data)
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+
+	res, err := s.do(ctx, http.MethodPut, key, nil, header, body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	return checkS3Response(res)
+}
+
+// Get implements Storage.
+func (s *S3) Get(ctx context.Context, key string) (*Object, error) {
+	res, err := s.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkS3Response(res); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+
+	obj := Object{
+		Body:        res.Body,
+		Size:        res.ContentLength,
+		ContentType: res.Header.Get("Content-Type"),
+	}
+
+	if modified, err := http.ParseTime(res.Header.Get("Last-Modified")); err == nil {
+		obj.ModTime = modified
+	}
+
+	if obj.ContentType == "" {
+		obj.ContentType = ContentType(key)
+	}
+
+	return &obj, nil
+}
+
+// Delete implements Storage.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	res, err := s.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if err := checkS3Response(res); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key          string
+		Size         int64
+		LastModified time.Time
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+// List implements Storage.
+func (s *S3) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+
+	query := url.Values{}
+	query.Set("list-type", "2")
+	query.Set("prefix", prefix)
+
+	for {
+		res, err := s.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := checkS3Response(res); err != nil {
+			res.Body.Close()
+			return nil, err
+		}
+
+		var result listBucketResult
+		err = xml.NewDecoder(res.Body).Decode(&result)
+		res.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse bucket list: %w", err)
+		}
+
+		for _, obj := range result.Contents {
+			objects = append(objects, ObjectInfo{Key: obj.Key, Size: obj.Size, ModTime: obj.LastModified})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// URL implements Storage.
+//
+// Files are always proxied through barista so that the owner of the upload is checked before it is
+// served, linking to the bucket directly would make private uploads public
+func (s *S3) URL(key string) string {
+	return "/" + ModelPath(key)
+}
+
+// do makes a signed request against the bucket, an empty key makes the request against the bucket
+// itself
+func (s *S3) do(
+	ctx context.Context,
+	method string,
+	key string,
+	query url.Values,
+	header http.Header,
+	body []byte,
+) (*http.Response, error) {
+	target := *s.endpoint
+	target.Path = strings.TrimRight(target.Path, "/") + "/" + s.bucket
+	if key != "" {
+		target.Path += "/" + key
+	}
+	target.RawPath = s3EscapePath(target.Path)
+	target.RawQuery = s3CanonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.ContentLength = int64(len(body))
+
+	s.sign(req, body, time.Now().UTC())
+
+	return s.client.Do(req)
+}
+
+// sign adds the aws signature v4 authorization header to the request
+func (s *S3) sign(req *http.Request, body []byte, now time.Time) {
+	payloadHash := s3EmptyBodyHash
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+
+	req.Header.Set("X-Amz-Date", now.Format(amzDateFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signed := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		name := strings.ToLower(k)
+		if name == "content-type" || name == "range" || strings.HasPrefix(name, "x-amz-") {
+			signed[name] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+
+	names := make([]string, 0, len(signed))
+	for name := range signed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + signed[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{now.Format(amzScopeFormat), s.region, s3Service, "aws4_request"}, "/")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		now.Format(amzDateFormat),
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := hmacSha256([]byte("AWS4"+s.secretKey), now.Format(amzScopeFormat))
+	key = hmacSha256(key, s.region)
+	key = hmacSha256(key, s3Service)
+	key = hmacSha256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm,
+		s.accessKey,
+		scope,
+		signedHeaders,
+		signature,
+	))
+}
+
+func checkS3Response(res *http.Response) error {
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case res.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("s3 request failed (%d): %s", res.StatusCode, msg)
+	default:
+		return nil
+	}
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
+
+// s3Escape uri encodes a string as per the aws signature v4 spec
+func s3Escape(value string, keepSlash bool) string {
+	var out strings.Builder
+
+	for _, b := range []byte(value) {
+		switch {
+		case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9',
+			b == '-', b == '_', b == '.', b == '~':
+			out.WriteByte(b)
+		case b == '/' && keepSlash:
+			out.WriteByte(b)
+		default:
+			fmt.Fprintf(&out, "%%%02X", b)
+		}
+	}
+
+	return out.String()
+}
+
+func s3EscapePath(p string) string {
+	return s3Escape(p, true)
+}
+
+// s3CanonicalQuery encodes the query string with sorted keys as required by the signature
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, s3Escape(k, false)+"="+s3Escape(v, false))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+var _ Storage = (*S3)(nil)
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServeFile streams a file from the default storage backend
+//
+// Files are stored under stable names so they are cached privately and revalidated against their
+// ETag on every use, this means a new upload shows up straight away but unchanged files only cost
+// a 304
+func ServeFile(rw http.ResponseWriter, r *http.Request, key string) {
+	obj, err := Default().Get(r.Context(), key)
+	if errors.Is(err, ErrNotFound) {
+		http.NotFound(rw, r)
+		return
+	} else if err != nil {
+		http.Error(rw, "failed to read file", http.StatusInternalServerError)
+		return
+	}
+	defer obj.Body.Close()
+
+	etag := fmt.Sprintf(`"%x-%x"`, obj.Size, obj.ModTime.UnixNano())
+
+	rw.Header().Set("Cache-Control", "private, no-cache")
+	rw.Header().Set("ETag", etag)
+	if !obj.ModTime.IsZero() {
+		rw.Header().Set("Last-Modified", obj.ModTime.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, obj.ModTime) {
+		rw.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	rw.Header().Set("Content-Type", obj.ContentType)
+	if obj.Size >= 0 {
+		rw.Header().Set("Content-Length", strconv.FormatInt(obj.Size, 10))
+	}
+
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	_, _ = io.Copy(rw, obj.Body)
+}
+
+// notModified checks the conditional request headers against the file
+//
+// If-None-Match takes priority over If-Modified-Since as per RFC 9110
+func notModified(r *http.Request, etag string, modTime time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || modTime.IsZero() {
+		return false
+	}
+
+	return !modTime.Truncate(time.Second).After(since)
+}
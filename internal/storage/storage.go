@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"time"
+)
+
+var ErrNotFound = errors.New("file not found")
+
+// ModelPrefix is prepended to storage keys when they are stored against a model
+//
+// Before storage backends existed models stored the path of the file relative to the data dir and
+// this keeps the existing values valid
+const ModelPrefix = "uploads/"
+
+// Storage is a backend for storing uploaded files
+//
+// keys are slash separated paths relative to the root of the backend, eg. coffee/1.full.jpg
+type Storage interface {
+	Put(ctx context.Context, key string, data io.Reader, contentType string) error
+	Get(ctx context.Context, key string) (*Object, error)
+	Delete(ctx context.Context, key string) error
+	// List returns every file with a key that starts with prefix
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+	// URL returns the url the file can be viewed at in the browser
+	URL(key string) string
+}
+
+// Object is a file read from a storage backend, the caller must close Body
+type Object struct {
+	Body        io.ReadCloser
+	Size        int64
+	ModTime     time.Time
+	ContentType string
+}
+
+// ObjectInfo describes a file in a storage backend without reading it
+type ObjectInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+var backend Storage = NewFilesystem(defaultFsRoot)
+
+// Init sets up the default storage backend from the env
+func Init() error {
+	store, err := New(envBackend.Get(BackendFilesystem))
+	if err != nil {
+		return err
+	}
+
+	backend = store
+	return nil
+}
+
+// Default returns the storage backend used by the app
+func Default() Storage {
+	return backend
+}
+
+const (
+	BackendFilesystem = "fs"
+	BackendS3         = "s3"
+)
+
+// New creates a storage backend of the given kind from the env config
+func New(kind string) (Storage, error) {
+	switch kind {
+	case BackendFilesystem:
+		return NewFilesystem(envFsRoot.Get(defaultFsRoot)), nil
+	case BackendS3:
+		return NewS3FromEnv()
+	default:
+		return nil, fmt.Errorf("unknown storage backend %s", kind)
+	}
+}
+
+// ModelPath converts a storage key into the value stored against a model
+func ModelPath(key string) string {
+	return ModelPrefix + key
+}
+
+// KeyFromModelPath converts a value stored against a model back into a storage key
+func KeyFromModelPath(modelPath string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(modelPath, "/"), ModelPrefix)
+}
+
+// ContentType guesses the content type of a file from its key
+func ContentType(key string) string {
+	if t := mime.TypeByExtension(path.Ext(key)); t != "" {
+		return t
+	}
+
+	return "application/octet-stream"
+}
@@ -0,0 +1,59 @@
+package tasting_controllers
+
+import (
+	"net/http"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/coffee"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/tasting"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+type Controller struct {
+	repo       tasting.Repository
+	authRepo   auth.Repository
+	coffeeRepo coffee.Repository
+}
+
+func New(repo tasting.Repository, authRepo auth.Repository, coffeeRepo coffee.Repository) Controller {
+	return Controller{repo, authRepo, coffeeRepo}
+}
+
+// findSession finds the session from the id in the request path
+//
+// Sessions are only visible to the host and the invited participants
+func (c Controller) findSession(r *http.Request, user *auth.User) *tasting.Session {
+	id, err := server.PathID(r)
+	if err != nil {
+		return nil
+	}
+
+	session, err := c.repo.FindSession(id)
+	if err != nil || !session.CanAccess(user.ID) {
+		return nil
+	}
+
+	return session
+}
+
+// tagFlavours lists the flavours that can be tagged on a sample
+//
+// Only the top two levels of the flavour wheel are used to keep the list short enough to pick
+// from on a phone
+func (c Controller) tagFlavours() []coffee.FlavourProfile {
+	var flavours []coffee.FlavourProfile
+	for _, flavour := range c.coffeeRepo.IndexFlavourProfiles() {
+		if flavour.Depth <= 1 {
+			flavours = append(flavours, flavour)
+		}
+	}
+
+	return flavours
+}
+
+func notFound(rw http.ResponseWriter, r *http.Request, user *auth.User) {
+	ui.Toast(rw, ui.Warning, "Tasting not found")
+	rw.WriteHeader(http.StatusNotFound)
+	ui.RenderUser(rw, r, ui.NewPageData("Tasting Not Found", "404", user))
+}
@@ -0,0 +1,93 @@
+package tasting_controllers
+
+import (
+	"net/http"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/coffee"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/tasting"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+type scoreSampleRequest struct {
+	Score    uint8  `json:"score" validate:"min=1,max=10"`
+	Notes    string `json:"notes"`
+	Flavours []uint `json:"flavours"`
+}
+
+func (c Controller) ScoreSample(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	session := c.findSession(r, user)
+	if session == nil {
+		ui.Toast(rw, ui.Warning, "Tasting not found")
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if session.Revealed() {
+		ui.Toast(rw, ui.Warning, "Scores cannot be changed once the tasting has been revealed")
+		rw.WriteHeader(http.StatusConflict)
+		return
+	}
+
+	sampleId, _ := server.PathID(r, "sample_id")
+	var sample *tasting.Sample
+	for i := range session.Samples {
+		if session.Samples[i].ID == sampleId {
+			sample = &session.Samples[i]
+		}
+	}
+
+	if sample == nil {
+		ui.Toast(rw, ui.Warning, "Sample not found")
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	comData := newSampleData(session, *sample, user, c.tagFlavours())
+	defer func() {
+		ui.RenderComponent(rw, comData)
+	}()
+
+	var req scoreSampleRequest
+	if err := server.UnmarshalBody(r, &req, &comData); err != nil {
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	if err := server.ValidateRequest(req, &comData); err != nil {
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	var flavours []coffee.FlavourProfile
+	if len(req.Flavours) > 0 {
+		var err error
+		if flavours, err = c.coffeeRepo.FindFlavourProfiles(req.Flavours); err != nil {
+			ui.Toast(rw, ui.Warning, "One or more flavours not found")
+			return
+		}
+	}
+
+	score := sample.ScoreFor(user.ID)
+	if score == nil {
+		score = &tasting.Score{SampleID: sample.ID, UserID: user.ID}
+	}
+	score.Score = req.Score
+	score.Notes = req.Notes
+	score.Flavours = flavours
+
+	if err := c.repo.SaveScore(score); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to save score")
+		return
+	}
+
+	if sample.ScoreFor(user.ID) == nil {
+		sample.Scores = append(sample.Scores, *score)
+	}
+
+	comData = newSampleData(session, *sample, user, c.tagFlavours())
+	ui.Toast(rw, ui.Success, "Sample "+sample.Code+" scored")
+}
@@ -0,0 +1,108 @@
+package tasting_controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/coffee"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/tasting"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+type createSessionRequest struct {
+	Name     string `json:"name" validate:"required"`
+	Coffees  []uint `json:"coffees" validate:"min=2,max=12"`
+	Invitees string `json:"invitees"` // comma separated user names
+}
+
+func (c Controller) CreateSession(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	pageData := c.newSessionsData(user)
+	pageData.Open = true
+	defer func() {
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	var req createSessionRequest
+	if err := server.UnmarshalBody(r, &req, &pageData); err != nil {
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	if err := server.ValidateRequest(req, &pageData); err != nil {
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	var coffees []coffee.Coffee
+	for _, id := range req.Coffees {
+		coffee, err := c.coffeeRepo.FindCoffee(id, user.ID)
+		if err != nil {
+			ui.Toast(rw, ui.Warning, "Coffee not found")
+			return
+		}
+
+		coffees = append(coffees, *coffee)
+	}
+
+	participants, missing := c.findInvitees(user, req.Invitees)
+	if len(missing) > 0 {
+		pageData.SetFieldErrors(map[string][]string{
+			"invitees": {"Users not found: " + strings.Join(missing, ", ")},
+		})
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	session := tasting.Session{
+		Name:         req.Name,
+		HostID:       user.ID,
+		Samples:      tasting.NewSamples(coffees),
+		Participants: participants,
+	}
+
+	if err := c.repo.SaveSession(&session); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to create tasting")
+		return
+	}
+
+	pageData = c.newSessionsData(user)
+	pageData.Form = createSessionRequest{}
+
+	ui.Toast(rw, ui.Success, "Tasting created")
+}
+
+// findInvitees looks up the users from a comma separated list of names
+//
+// The host is skipped as they are always part of the session, any names that could not be found
+// are returned so they can be reported back
+func (c Controller) findInvitees(host *auth.User, names string) ([]tasting.Participant, []string) {
+	var (
+		participants []tasting.Participant
+		missing      []string
+		seen         = map[uint]bool{host.ID: true}
+	)
+
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+
+		invitee, err := c.authRepo.FindUserByName(name)
+		if err != nil {
+			missing = append(missing, name)
+			continue
+		}
+
+		if seen[invitee.ID] {
+			continue
+		}
+		seen[invitee.ID] = true
+
+		participants = append(participants, tasting.Participant{UserID: invitee.ID})
+	}
+
+	return participants, missing
+}
@@ -0,0 +1,116 @@
+package tasting_controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/server"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+type inviteParticipantsRequest struct {
+	Invitees string `json:"invitees" validate:"required"`
+}
+
+func (c Controller) InviteParticipants(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	session := c.findSession(r, user)
+	if session == nil || !session.IsHost(user.ID) {
+		notFound(rw, r, user)
+		return
+	}
+
+	pageData := c.newSessionData(user, session)
+	defer func() {
+		ui.RenderUser(rw, r, pageData)
+	}()
+
+	if session.Revealed() {
+		ui.Toast(rw, ui.Warning, "The tasting has already been revealed")
+		return
+	}
+
+	var req inviteParticipantsRequest
+	if err := server.UnmarshalBody(r, &req, &pageData); err != nil {
+		ui.Toast(rw, ui.Warning, "The server did not understand the request")
+		return
+	}
+
+	if err := server.ValidateRequest(req, &pageData); err != nil {
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	participants, missing := c.findInvitees(user, req.Invitees)
+	if len(missing) > 0 {
+		pageData.SetFieldErrors(map[string][]string{
+			"invitees": {"Users not found: " + strings.Join(missing, ", ")},
+		})
+		ui.Toast(rw, ui.Warning, "Bad request")
+		return
+	}
+
+	for _, participant := range participants {
+		if session.CanAccess(participant.UserID) {
+			continue
+		}
+
+		participant.SessionID = session.ID
+		if err := c.repo.SaveParticipant(&participant); err != nil {
+			ui.Toast(rw, ui.Warning, "Failed to invite participants")
+			return
+		}
+	}
+
+	if session, _ = c.repo.FindSession(session.ID); session != nil {
+		pageData = c.newSessionData(user, session)
+	}
+
+	ui.Toast(rw, ui.Success, "Participants invited")
+}
+
+func (c Controller) RevealSession(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	session := c.findSession(r, user)
+	if session == nil || !session.IsHost(user.ID) {
+		notFound(rw, r, user)
+		return
+	}
+
+	if !session.Revealed() {
+		now := time.Now()
+		session.RevealedAt = &now
+
+		if err := c.repo.RevealSession(session); err != nil {
+			session.RevealedAt = nil
+			ui.Toast(rw, ui.Warning, "Failed to reveal tasting")
+		} else {
+			ui.Toast(rw, ui.Success, "Tasting revealed")
+		}
+	}
+
+	ui.RenderUser(rw, r, c.newSessionData(user, session))
+}
+
+func (c Controller) DeleteSession(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	session := c.findSession(r, user)
+	if session == nil || !session.IsHost(user.ID) {
+		notFound(rw, r, user)
+		return
+	}
+
+	if err := c.repo.DeleteSession(session); err != nil {
+		ui.Toast(rw, ui.Warning, "Failed to delete tasting")
+		ui.RenderUser(rw, r, c.newSessionData(user, session))
+		return
+	}
+
+	ui.Toast(rw, ui.Success, "Tasting deleted")
+	server.Redirect(rw, r, "/tastings")
+}
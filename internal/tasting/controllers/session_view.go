@@ -0,0 +1,101 @@
+package tasting_controllers
+
+import (
+	"net/http"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/coffee"
+	"github.com/indeedhat/barista/internal/tasting"
+	"github.com/indeedhat/barista/internal/ui"
+)
+
+type viewSessionsData struct {
+	ui.PageData
+	Sessions []tasting.Session
+	Coffees  []coffee.Coffee
+	Open     bool
+}
+
+func (c Controller) ViewSessions(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	pageData := c.newSessionsData(user)
+	pageData.Form = createSessionRequest{}
+
+	ui.RenderUser(rw, r, pageData)
+}
+
+func (c Controller) newSessionsData(user *auth.User) viewSessionsData {
+	return viewSessionsData{
+		PageData: ui.NewPageData("Tastings", "tastings", user),
+		Sessions: c.repo.IndexSessionsForUser(user),
+		Coffees:  c.coffeeRepo.IndexCoffeesForUser(user),
+	}
+}
+
+type viewSessionData struct {
+	ui.PageData
+	Session  *tasting.Session
+	Samples  []ui.ComponentData
+	Results  []tasting.SampleResult
+	Progress []tasting.Progress
+	IsHost   bool
+}
+
+func (c Controller) ViewSession(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+
+	session := c.findSession(r, user)
+	if session == nil {
+		notFound(rw, r, user)
+		return
+	}
+
+	ui.RenderUser(rw, r, c.newSessionData(user, session))
+}
+
+func (c Controller) newSessionData(user *auth.User, session *tasting.Session) viewSessionData {
+	pageData := viewSessionData{
+		PageData: ui.NewPageData(session.Name, "tasting", user),
+		Session:  session,
+		IsHost:   session.IsHost(user.ID),
+		Progress: session.Progress(),
+	}
+
+	if session.Revealed() {
+		pageData.Results = session.Results()
+		return pageData
+	}
+
+	flavours := c.tagFlavours()
+	for _, sample := range session.Samples {
+		pageData.Samples = append(pageData.Samples, newSampleData(session, sample, user, flavours))
+	}
+
+	return pageData
+}
+
+// newSampleData builds the data for the tasting-sample component
+func newSampleData(
+	session *tasting.Session,
+	sample tasting.Sample,
+	user *auth.User,
+	flavours []coffee.FlavourProfile,
+) ui.ComponentData {
+	req := scoreSampleRequest{Score: 5}
+	if score := sample.ScoreFor(user.ID); score != nil {
+		req = scoreSampleRequest{
+			Score:    score.Score,
+			Notes:    score.Notes,
+			Flavours: score.FlavourIds(),
+		}
+	}
+
+	return ui.NewComponentData("tasting-sample", ui.ComponentData{
+		"SessionID": session.ID,
+		"Sample":    sample,
+		"Scored":    sample.ScoreFor(user.ID) != nil,
+		"Flavours":  flavours,
+		"Form":      req,
+	})
+}
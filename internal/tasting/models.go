@@ -0,0 +1,240 @@
+package tasting
+
+import (
+	"cmp"
+	"crypto/rand"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/coffee"
+	"github.com/indeedhat/barista/internal/database/model"
+)
+
+// Session is a blind tasting run by a host for a group of invited users
+//
+// The coffees being tasted are hidden behind anonymous sample codes until the host reveals them
+type Session struct {
+	model.SoftDelete
+
+	Name       string
+	RevealedAt *time.Time
+
+	HostID uint
+	Host   auth.User
+
+	Samples      []Sample
+	Participants []Participant
+}
+
+func (Session) TableName() string {
+	return "tasting_sessions"
+}
+
+// Revealed checks if the host has revealed the identity of the samples
+func (s Session) Revealed() bool {
+	return s.RevealedAt != nil
+}
+
+// IsHost checks if the user is running the session
+func (s Session) IsHost(userId uint) bool {
+	return s.HostID == userId
+}
+
+// CanAccess checks if the user is either the host or one of the invited participants
+func (s Session) CanAccess(userId uint) bool {
+	if s.IsHost(userId) {
+		return true
+	}
+
+	return slices.ContainsFunc(s.Participants, func(p Participant) bool {
+		return p.UserID == userId
+	})
+}
+
+// Sample is a single coffee in a session identified only by its code until the session is revealed
+type Sample struct {
+	model.SoftDelete
+
+	Code string
+
+	SessionID uint `gorm:"index"`
+
+	CoffeeID uint
+	Coffee   coffee.Coffee
+
+	Scores []Score
+}
+
+func (Sample) TableName() string {
+	return "tasting_samples"
+}
+
+// ScoreFor finds the score the user has given to the sample
+func (s Sample) ScoreFor(userId uint) *Score {
+	for i := range s.Scores {
+		if s.Scores[i].UserID == userId {
+			return &s.Scores[i]
+		}
+	}
+
+	return nil
+}
+
+// Participant is a user that has been invited to score the samples in a session
+type Participant struct {
+	model.SoftDelete
+
+	SessionID uint `gorm:"uniqueIndex:idx_participant"`
+
+	UserID uint `gorm:"uniqueIndex:idx_participant"`
+	User   auth.User
+}
+
+func (Participant) TableName() string {
+	return "tasting_participants"
+}
+
+// Score is a single users assessment of a sample
+type Score struct {
+	model.SoftDelete
+
+	// Score is out of 10
+	Score uint8
+	Notes string
+
+	SampleID uint `gorm:"uniqueIndex:idx_score"`
+
+	UserID uint `gorm:"uniqueIndex:idx_score"`
+	User   auth.User
+
+	Flavours []coffee.FlavourProfile `gorm:"many2many:tasting_score_flavours;"`
+}
+
+func (Score) TableName() string {
+	return "tasting_scores"
+}
+
+// FlavourIds lists the ids of the flavours tagged on the score
+func (s Score) FlavourIds() []uint {
+	ids := make([]uint, 0, len(s.Flavours))
+	for _, flavour := range s.Flavours {
+		ids = append(ids, flavour.ID)
+	}
+
+	return ids
+}
+
+// FlavourCount is the number of participants that tagged a sample with a flavour
+type FlavourCount struct {
+	Name  string
+	Count int
+}
+
+// SampleResult is the aggregated scores for a sample
+type SampleResult struct {
+	Sample
+	Average  float64
+	Flavours []FlavourCount
+}
+
+// Results aggregates the scores of each sample, the best scoring sample is first
+func (s Session) Results() []SampleResult {
+	results := make([]SampleResult, 0, len(s.Samples))
+	for _, sample := range s.Samples {
+		result := SampleResult{Sample: sample}
+
+		counts := make(map[string]int)
+		var total int
+		for _, score := range sample.Scores {
+			total += int(score.Score)
+			for _, flavour := range score.Flavours {
+				counts[flavour.Name]++
+			}
+		}
+
+		if len(sample.Scores) > 0 {
+			result.Average = float64(total) / float64(len(sample.Scores))
+		}
+
+		for name, count := range counts {
+			result.Flavours = append(result.Flavours, FlavourCount{name, count})
+		}
+		slices.SortFunc(result.Flavours, func(a, b FlavourCount) int {
+			if a.Count != b.Count {
+				return b.Count - a.Count
+			}
+			return cmp.Compare(a.Name, b.Name)
+		})
+
+		results = append(results, result)
+	}
+
+	slices.SortStableFunc(results, func(a, b SampleResult) int {
+		return cmp.Compare(b.Average, a.Average)
+	})
+
+	return results
+}
+
+// Progress is the number of samples each participant has scored
+type Progress struct {
+	Name   string
+	Scored int
+}
+
+// Progress reports how far through the samples each participant is
+func (s Session) Progress() []Progress {
+	progress := make([]Progress, 0, len(s.Participants)+1)
+	for _, user := range append([]auth.User{s.Host}, participantUsers(s.Participants)...) {
+		p := Progress{Name: user.Name}
+		for _, sample := range s.Samples {
+			if sample.ScoreFor(user.ID) != nil {
+				p.Scored++
+			}
+		}
+
+		progress = append(progress, p)
+	}
+
+	return progress
+}
+
+func participantUsers(participants []Participant) []auth.User {
+	users := make([]auth.User, 0, len(participants))
+	for _, p := range participants {
+		users = append(users, p.User)
+	}
+
+	return users
+}
+
+// NewSamples creates a sample for each coffee with a unique random 3 digit code
+//
+// The samples are ordered by code so that the order does not give away which coffee is which
+func NewSamples(coffees []coffee.Coffee) []Sample {
+	used := make(map[string]bool)
+	samples := make([]Sample, 0, len(coffees))
+
+	for _, c := range coffees {
+		code := sampleCode()
+		for used[code] {
+			code = sampleCode()
+		}
+		used[code] = true
+
+		samples = append(samples, Sample{Code: code, CoffeeID: c.ID})
+	}
+
+	slices.SortFunc(samples, func(a, b Sample) int {
+		return cmp.Compare(a.Code, b.Code)
+	})
+
+	return samples
+}
+
+func sampleCode() string {
+	n, _ := rand.Int(rand.Reader, big.NewInt(900))
+	return big.NewInt(0).Add(n, big.NewInt(100)).String()
+}
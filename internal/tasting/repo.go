@@ -0,0 +1,102 @@
+package tasting
+
+import (
+	"github.com/indeedhat/barista/internal/auth"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	IndexSessionsForUser(*auth.User) []Session
+	FindSession(uint) (*Session, error)
+	SaveSession(*Session) error
+	DeleteSession(*Session) error
+	RevealSession(*Session) error
+
+	SaveParticipant(*Participant) error
+	SaveScore(*Score) error
+}
+
+type SqliteRepository struct {
+	db *gorm.DB
+}
+
+func NewSqliteRepo(db *gorm.DB) Repository {
+	return SqliteRepository{db}
+}
+
+// IndexSessionsForUser implements Repository.
+//
+// This includes both the sessions the user is hosting and those they have been invited to
+func (r SqliteRepository) IndexSessionsForUser(user *auth.User) []Session {
+	var sessions []Session
+
+	r.db.Preload("Host").
+		Preload("Samples").
+		Preload("Participants").
+		Where("host_id = ?", user.ID).
+		Or("id IN (?)", r.db.Model(&Participant{}).Select("session_id").Where("user_id = ?", user.ID)).
+		Order("created_at DESC").
+		Find(&sessions)
+
+	return sessions
+}
+
+// FindSession implements Repository.
+func (r SqliteRepository) FindSession(id uint) (*Session, error) {
+	var session Session
+
+	err := r.db.Preload("Host").
+		Preload("Participants.User").
+		Preload("Samples", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("code ASC")
+		}).
+		Preload("Samples.Coffee.Roaster").
+		Preload("Samples.Scores.User").
+		Preload("Samples.Scores.Flavours").
+		First(&session, id).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// SaveSession implements Repository.
+func (r SqliteRepository) SaveSession(session *Session) error {
+	return r.db.Save(session).Error
+}
+
+// DeleteSession implements Repository.
+func (r SqliteRepository) DeleteSession(session *Session) error {
+	return r.db.Delete(session).Error
+}
+
+// RevealSession implements Repository.
+func (r SqliteRepository) RevealSession(session *Session) error {
+	return r.db.Model(session).Update("revealed_at", session.RevealedAt).Error
+}
+
+// SaveParticipant implements Repository.
+func (r SqliteRepository) SaveParticipant(participant *Participant) error {
+	return r.db.Save(participant).Error
+}
+
+// SaveScore implements Repository.
+//
+// The flavours on the score replace any that were previously tagged
+func (r SqliteRepository) SaveScore(score *Score) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Flavours").Save(score).Error; err != nil {
+			return err
+		}
+
+		if len(score.Flavours) == 0 {
+			return tx.Model(score).Association("Flavours").Clear()
+		}
+
+		return tx.Model(score).Association("Flavours").Replace(score.Flavours)
+	})
+}
+
+var _ Repository = (*SqliteRepository)(nil)
@@ -1,23 +1,48 @@
 package types
 
-type BrewerType string
-
-const (
-	BrewerAeroPress BrewerType = "Aero Press"
-	BrewerCafetiere BrewerType = "Cafetiere"
-	BrewerEmersion  BrewerType = "Emersion"
-	BrewerEspresso  BrewerType = "Espresso"
-	BrewerMochaPot  BrewerType = "Mocha Pot"
-	BrewerPourOver  BrewerType = "Pour Over"
-	BrewerSiphon    BrewerType = "Siphon"
+import (
+	"github.com/indeedhat/barista/internal/database/model"
 )
 
-var Brewers = []BrewerType{
-	BrewerAeroPress,
-	BrewerCafetiere,
-	BrewerEmersion,
-	BrewerEspresso,
-	BrewerMochaPot,
-	BrewerPourOver,
-	BrewerSiphon,
+// BrewerType is the kind of equipment a brewer is
+//
+// The built in types are shared by everyone, users can add their own which are only visible to them
+type BrewerType struct {
+	model.SoftDelete
+
+	Name string
+
+	// Espresso brewers can have baskets and use the espresso recipe parameters
+	Espresso bool
+	// Filter brewers steep or pour water over the grounds and use the filter recipe parameters
+	Filter bool
+
+	// UserID is nil for the built in brewer types
+	UserID *uint `gorm:"index"`
+}
+
+// BuiltIn checks if the brewer type is one of the defaults available to everyone
+func (b BrewerType) BuiltIn() bool {
+	return b.UserID == nil
+}
+
+// IsEspresso checks if the brewer pulls espresso
+func (b BrewerType) IsEspresso() bool {
+	return b.Espresso
+}
+
+// IsFilter checks if the brewer makes filter coffee by steeping or pouring water over the grounds
+func (b BrewerType) IsFilter() bool {
+	return b.Filter
+}
+
+// DefaultBrewerTypes are seeded as the built in brewer types
+var DefaultBrewerTypes = []BrewerType{
+	{Name: "Aero Press", Filter: true},
+	{Name: "Cafetiere", Filter: true},
+	{Name: "Emersion", Filter: true},
+	{Name: "Espresso", Espresso: true},
+	{Name: "Mocha Pot"},
+	{Name: "Pour Over", Filter: true},
+	{Name: "Siphon", Filter: true},
 }
@@ -2,80 +2,81 @@ package types
 
 import (
 	"slices"
+
+	"github.com/indeedhat/barista/internal/database/model"
 )
 
-type DrinkType string
+// DrinkType is a drink that recipes can be made as
+//
+// The built in types are shared by everyone, users can add their own which are only visible to them
+type DrinkType struct {
+	model.SoftDelete
+
+	Name string
+	// Milk is set for drinks that are made with milk
+	Milk bool
+
+	// UserID is nil for the built in drink types
+	UserID *uint `gorm:"index"`
+
+	// Brewers are the types of brewer the drink can be made with, if there are none then any
+	// brewer can be used
+	Brewers []BrewerType `gorm:"many2many:drink_type_brewer_types;"`
+}
+
+// BuiltIn checks if the drink type is one of the defaults available to everyone
+func (d DrinkType) BuiltIn() bool {
+	return d.UserID == nil
+}
 
-func (d DrinkType) Brewers() []BrewerType {
-	if brewers, found := brewerAssoc[d]; found {
-		return brewers
+// BrewerIds lists the ids of the brewer types the drink can be made with
+func (d DrinkType) BrewerIds() []uint {
+	ids := make([]uint, 0, len(d.Brewers))
+	for _, brewer := range d.Brewers {
+		ids = append(ids, brewer.ID)
 	}
 
-	return brewerAssoc[DrinkOther]
+	return ids
 }
 
 func (d DrinkType) IsEspressoBased() bool {
-	return slices.Contains(d.Brewers(), BrewerEspresso)
+	return slices.ContainsFunc(d.Brewers, BrewerType.IsEspresso)
 }
 
-const (
-	DrinkAmericano  DrinkType = "Americano"
-	DrinkCafetiere  DrinkType = "Cafetiere"
-	DrinkCappuccino DrinkType = "Cappuccino"
-	DrinkCortado    DrinkType = "Cortado"
-	DrinkDoppio     DrinkType = "Doppio"
-	DrinkEspresso   DrinkType = "Espresso"
-	DrinkFlatWhite  DrinkType = "Flat White"
-	DrinkLatte      DrinkType = "Latte"
-	DrinkLungo      DrinkType = "Lungo"
-	DrinkMacchiato  DrinkType = "Macchiato"
-	DrinkMocha      DrinkType = "Mocha"
-	DrinkMochaPot   DrinkType = "Mocha Pot"
-	DrinkPourover   DrinkType = "Pourover"
-	DrinkRistretto  DrinkType = "Ristretto"
-	DrinkOther      DrinkType = "Other"
-)
+func (d DrinkType) IsFilterBased() bool {
+	return slices.ContainsFunc(d.Brewers, BrewerType.IsFilter)
+}
+
+func (d DrinkType) IsMilkBased() bool {
+	return d.Milk
+}
 
-var Drinks = []DrinkType{
-	DrinkAmericano,
-	DrinkCafetiere,
-	DrinkCappuccino,
-	DrinkCortado,
-	DrinkDoppio,
-	DrinkEspresso,
-	DrinkFlatWhite,
-	DrinkLatte,
-	DrinkLungo,
-	DrinkMacchiato,
-	DrinkMocha,
-	DrinkMochaPot,
-	DrinkPourover,
-	DrinkRistretto,
-	DrinkOther,
+// DefaultDrinkTypes are seeded as the built in drink types
+//
+// The brewers are matched to the built in brewer types by name
+var DefaultDrinkTypes = []DrinkType{
+	{Name: "Americano", Brewers: brewerTypes("Espresso")},
+	{Name: "Cafetiere", Brewers: brewerTypes("Cafetiere")},
+	{Name: "Cappuccino", Milk: true, Brewers: brewerTypes("Espresso")},
+	{Name: "Cortado", Milk: true, Brewers: brewerTypes("Espresso")},
+	{Name: "Doppio", Brewers: brewerTypes("Espresso")},
+	{Name: "Espresso", Brewers: brewerTypes("Espresso")},
+	{Name: "Flat White", Milk: true, Brewers: brewerTypes("Espresso")},
+	{Name: "Latte", Milk: true, Brewers: brewerTypes("Espresso")},
+	{Name: "Lungo", Brewers: brewerTypes("Espresso")},
+	{Name: "Macchiato", Milk: true, Brewers: brewerTypes("Espresso")},
+	{Name: "Mocha", Milk: true, Brewers: brewerTypes("Espresso")},
+	{Name: "Mocha Pot", Brewers: brewerTypes("Mocha Pot")},
+	{Name: "Pourover", Brewers: brewerTypes("Pour Over")},
+	{Name: "Ristretto", Brewers: brewerTypes("Espresso")},
+	{Name: "Other"},
 }
 
-var brewerAssoc = map[DrinkType][]BrewerType{
-	DrinkAmericano:  {BrewerEspresso},
-	DrinkCafetiere:  {BrewerCafetiere},
-	DrinkCappuccino: {BrewerEspresso},
-	DrinkCortado:    {BrewerEspresso},
-	DrinkDoppio:     {BrewerEspresso},
-	DrinkEspresso:   {BrewerEspresso},
-	DrinkFlatWhite:  {BrewerEspresso},
-	DrinkLatte:      {BrewerEspresso},
-	DrinkLungo:      {BrewerEspresso},
-	DrinkMacchiato:  {BrewerEspresso},
-	DrinkMocha:      {BrewerEspresso},
-	DrinkMochaPot:   {BrewerMochaPot},
-	DrinkPourover:   {BrewerPourOver},
-	DrinkRistretto:  {BrewerEspresso},
-	DrinkOther: {
-		BrewerEspresso,
-		BrewerEmersion,
-		BrewerPourOver,
-		BrewerAeroPress,
-		BrewerMochaPot,
-		BrewerCafetiere,
-		BrewerSiphon,
-	},
+func brewerTypes(names ...string) []BrewerType {
+	brewers := make([]BrewerType, 0, len(names))
+	for _, name := range names {
+		brewers = append(brewers, BrewerType{Name: name})
+	}
+
+	return brewers
 }
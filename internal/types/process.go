@@ -0,0 +1,25 @@
+package types
+
+type ProcessMethod string
+
+const (
+	ProcessWashed     ProcessMethod = "Washed"
+	ProcessNatural    ProcessMethod = "Natural"
+	ProcessHoney      ProcessMethod = "Honey"
+	ProcessAnaerobic  ProcessMethod = "Anaerobic"
+	ProcessCarbonic   ProcessMethod = "Carbonic Maceration"
+	ProcessWetHulled  ProcessMethod = "Wet Hulled"
+	ProcessSemiWashed ProcessMethod = "Semi Washed"
+	ProcessOther      ProcessMethod = "Other"
+)
+
+var ProcessMethods = []ProcessMethod{
+	ProcessWashed,
+	ProcessNatural,
+	ProcessHoney,
+	ProcessAnaerobic,
+	ProcessCarbonic,
+	ProcessWetHulled,
+	ProcessSemiWashed,
+	ProcessOther,
+}
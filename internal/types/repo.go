@@ -0,0 +1,236 @@
+package types
+
+import (
+	"gorm.io/gorm"
+)
+
+// Repository handles the drink and brewer types stored in the database
+//
+// Users can see the built in types along with any they have added themselves, user ids are taken
+// rather than users as the auth package depends on this one
+type Repository interface {
+	IndexBrewerTypes(userId uint) []BrewerType
+	FindBrewerType(id, userId uint) (*BrewerType, error)
+	FindBrewerTypeByName(name string, userId uint) (*BrewerType, error)
+	SaveBrewerType(*BrewerType) error
+	DeleteBrewerType(*BrewerType) error
+	SeedBrewerTypes([]BrewerType) error
+
+	IndexDrinkTypes(userId uint) []DrinkType
+	FindDrinkType(id, userId uint) (*DrinkType, error)
+	FindDrinkTypeByName(name string, userId uint) (*DrinkType, error)
+	SaveDrinkType(*DrinkType) error
+	DeleteDrinkType(*DrinkType) error
+	SeedDrinkTypes([]DrinkType) error
+}
+
+type SqliteRepository struct {
+	db *gorm.DB
+}
+
+func NewSqliteRepo(db *gorm.DB) Repository {
+	return SqliteRepository{db}
+}
+
+// visibleTo limits a query to the built in types and those added by the user
+func visibleTo(userId uint) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("user_id IS NULL OR user_id = ?", userId)
+	}
+}
+
+// IndexBrewerTypes implements Repository.
+func (r SqliteRepository) IndexBrewerTypes(userId uint) []BrewerType {
+	var brewerTypes []BrewerType
+
+	r.db.Scopes(visibleTo(userId)).
+		Order("user_id IS NOT NULL, name ASC").
+		Find(&brewerTypes)
+
+	return brewerTypes
+}
+
+// FindBrewerType implements Repository.
+func (r SqliteRepository) FindBrewerType(id, userId uint) (*BrewerType, error) {
+	var brewerType BrewerType
+
+	if err := r.db.Scopes(visibleTo(userId)).First(&brewerType, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &brewerType, nil
+}
+
+// FindBrewerTypeByName implements Repository.
+//
+// Names are matched case insensitively
+func (r SqliteRepository) FindBrewerTypeByName(name string, userId uint) (*BrewerType, error) {
+	var brewerType BrewerType
+
+	err := r.db.Scopes(visibleTo(userId)).
+		Where("LOWER(name) = LOWER(?)", name).
+		Order("user_id IS NOT NULL").
+		First(&brewerType).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &brewerType, nil
+}
+
+// SaveBrewerType implements Repository.
+func (r SqliteRepository) SaveBrewerType(brewerType *BrewerType) error {
+	return r.db.Save(brewerType).Error
+}
+
+// DeleteBrewerType implements Repository.
+//
+// The brewer type is also removed from any drink types that were made with it
+func (r SqliteRepository) DeleteBrewerType(brewerType *BrewerType) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("DELETE FROM drink_type_brewer_types WHERE brewer_type_id = ?", brewerType.ID).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(brewerType).Error
+	})
+}
+
+// SeedBrewerTypes implements Repository.
+//
+// Any of the built in brewer types that are missing are added, existing ones are left as they are
+func (r SqliteRepository) SeedBrewerTypes(brewerTypes []BrewerType) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, brewerType := range brewerTypes {
+			err := tx.Where("user_id IS NULL AND name = ?", brewerType.Name).
+				FirstOrCreate(&brewerType).
+				Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// IndexDrinkTypes implements Repository.
+func (r SqliteRepository) IndexDrinkTypes(userId uint) []DrinkType {
+	var drinkTypes []DrinkType
+
+	r.db.Preload("Brewers").
+		Scopes(visibleTo(userId)).
+		Order("user_id IS NOT NULL, name ASC").
+		Find(&drinkTypes)
+
+	return drinkTypes
+}
+
+// FindDrinkType implements Repository.
+func (r SqliteRepository) FindDrinkType(id, userId uint) (*DrinkType, error) {
+	var drinkType DrinkType
+
+	err := r.db.Preload("Brewers").
+		Scopes(visibleTo(userId)).
+		First(&drinkType, id).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &drinkType, nil
+}
+
+// FindDrinkTypeByName implements Repository.
+//
+// Names are matched case insensitively
+func (r SqliteRepository) FindDrinkTypeByName(name string, userId uint) (*DrinkType, error) {
+	var drinkType DrinkType
+
+	err := r.db.Preload("Brewers").
+		Scopes(visibleTo(userId)).
+		Where("LOWER(name) = LOWER(?)", name).
+		Order("user_id IS NOT NULL").
+		First(&drinkType).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &drinkType, nil
+}
+
+// SaveDrinkType implements Repository.
+func (r SqliteRepository) SaveDrinkType(drinkType *DrinkType) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Brewers").Save(drinkType).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(drinkType).Association("Brewers").Replace(drinkType.Brewers)
+	})
+}
+
+// DeleteDrinkType implements Repository.
+func (r SqliteRepository) DeleteDrinkType(drinkType *DrinkType) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(drinkType).Association("Brewers").Clear(); err != nil {
+			return err
+		}
+
+		return tx.Delete(drinkType).Error
+	})
+}
+
+// SeedDrinkTypes implements Repository.
+//
+// Any of the built in drink types that are missing are added along with their brewers, existing
+// ones are left as they are. The brewer types must be seeded first
+func (r SqliteRepository) SeedDrinkTypes(drinkTypes []DrinkType) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, seed := range drinkTypes {
+			var count int64
+			err := tx.Model(&DrinkType{}).
+				Where("user_id IS NULL AND name = ?", seed.Name).
+				Count(&count).
+				Error
+			if err != nil {
+				return err
+			}
+
+			if count > 0 {
+				continue
+			}
+
+			var brewers []BrewerType
+			if len(seed.Brewers) > 0 {
+				err := tx.Where("user_id IS NULL AND name IN ?", brewerNames(seed.Brewers)).
+					Find(&brewers).
+					Error
+				if err != nil {
+					return err
+				}
+			}
+
+			drinkType := DrinkType{Name: seed.Name, Milk: seed.Milk, Brewers: brewers}
+			if err := tx.Create(&drinkType).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func brewerNames(brewers []BrewerType) []string {
+	names := make([]string, 0, len(brewers))
+	for _, brewer := range brewers {
+		names = append(names, brewer.Name)
+	}
+
+	return names
+}
+
+var _ Repository = (*SqliteRepository)(nil)
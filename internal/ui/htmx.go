@@ -66,10 +66,13 @@ type PageData struct {
 	Enum        pageDataEnums
 }
 
+// pageDataEnums are the fixed lists of options used across the site
+//
+// Drink and brewer types are not included as users can add their own, they are loaded by the
+// controllers that need them
 type pageDataEnums struct {
-	Drinks    []types.DrinkType
 	CafLevels []types.CaffeineLevel
-	Brewers   []types.BrewerType
+	Processes []types.ProcessMethod
 }
 
 func NewPageData(title, page string, user ...any) PageData {
@@ -80,9 +83,8 @@ func NewPageData(title, page string, user ...any) PageData {
 		FieldErrors: make(map[string][]string),
 		Data:        make(map[string]any),
 		Enum: pageDataEnums{
-			Drinks:    types.Drinks,
 			CafLevels: types.CaffeineLevels,
-			Brewers:   types.Brewers,
+			Processes: types.ProcessMethods,
 		},
 	}
 
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/indeedhat/barista/assets/templates"
+	"github.com/indeedhat/barista/internal/images"
+	"github.com/indeedhat/barista/internal/storage"
 	"github.com/indeedhat/barista/internal/types"
 	"github.com/indeedhat/barista/internal/version"
 )
@@ -47,6 +49,10 @@ var templateFuncs = template.FuncMap{
 		}
 		return ""
 	},
+	"variant": images.VariantPath,
+	"upload": func(modelPath string) string {
+		return storage.Default().URL(storage.KeyFromModelPath(modelPath))
+	},
 	"unix": func() int {
 		return int(time.Now().Unix())
 	},
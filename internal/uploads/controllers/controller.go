@@ -0,0 +1,13 @@
+package uploads_controllers
+
+import (
+	"github.com/indeedhat/barista/internal/uploads"
+)
+
+type Controller struct {
+	resolvers uploads.Resolvers
+}
+
+func New(resolvers uploads.Resolvers) Controller {
+	return Controller{resolvers}
+}
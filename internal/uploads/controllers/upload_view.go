@@ -0,0 +1,43 @@
+package uploads_controllers
+
+import (
+	"net/http"
+
+	"github.com/indeedhat/barista/internal/auth"
+	"github.com/indeedhat/barista/internal/storage"
+	"github.com/indeedhat/barista/internal/uploads"
+)
+
+// ViewUpload streams an uploaded file if the logged in user owns it or it has been made public
+//
+// Files the user cannot see are reported as not found so that the existence of other users files
+// is not leaked
+func (c Controller) ViewUpload(rw http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*auth.User)
+	key := r.PathValue("key")
+
+	prefix, id, err := uploads.ParseKey(key)
+	if err != nil {
+		http.NotFound(rw, r)
+		return
+	}
+
+	resolve, ok := c.resolvers[prefix]
+	if !ok {
+		http.NotFound(rw, r)
+		return
+	}
+
+	owner, err := resolve(id)
+	if err != nil {
+		http.NotFound(rw, r)
+		return
+	}
+
+	if userId, public := owner.UploadOwner(); userId != user.ID && !public {
+		http.NotFound(rw, r)
+		return
+	}
+
+	storage.ServeFile(rw, r, key)
+}
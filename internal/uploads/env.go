@@ -0,0 +1,10 @@
+package uploads
+
+import "github.com/indeedhat/dotenv"
+
+const (
+	// Hours between runs of the orphaned upload cleanup, 0 disables the scheduled run
+	envGCInterval dotenv.Int = "UPLOAD_GC_INTERVAL"
+
+	defaultGCInterval = 24
+)
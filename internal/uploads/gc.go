@@ -0,0 +1,140 @@
+package uploads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/indeedhat/barista/internal/images"
+	"github.com/indeedhat/barista/internal/storage"
+	"gorm.io/gorm"
+)
+
+// files younger than this are never collected so that an upload that has been stored but not yet
+// saved against its owner is not removed
+const gcMinAge = time.Hour
+
+// GCReport is the result of a garbage collection run
+type GCReport struct {
+	Scanned int
+	Removed []storage.ObjectInfo
+	// Reclaimed is the total size of the removed files in bytes
+	Reclaimed int64
+}
+
+// GC removes uploaded files that are no longer in use
+//
+// A file is orphaned if its owner no longer exists, has been soft deleted or no longer references
+// the file. Files with a prefix that has no resolver or that were very recently uploaded are left
+// alone.
+// If dryRun is set the orphaned files are reported but not removed
+func GC(ctx context.Context, store storage.Storage, resolvers Resolvers, dryRun bool) (*GCReport, error) {
+	objects, err := store.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	report := GCReport{Scanned: len(objects)}
+	for _, obj := range objects {
+		if time.Since(obj.ModTime) < gcMinAge {
+			continue
+		}
+
+		orphaned, err := isOrphaned(obj.Key, resolvers)
+		if err != nil {
+			return &report, err
+		}
+
+		if !orphaned {
+			continue
+		}
+
+		if !dryRun {
+			if err := store.Delete(ctx, obj.Key); err != nil {
+				return &report, fmt.Errorf("failed to delete %s: %w", obj.Key, err)
+			}
+		}
+
+		report.Removed = append(report.Removed, obj)
+		report.Reclaimed += obj.Size
+	}
+
+	return &report, nil
+}
+
+// ScheduleGC runs GC on the interval set in the env until the context is cancelled
+func ScheduleGC(ctx context.Context, resolvers Resolvers) {
+	interval := envGCInterval.Get(defaultGCInterval)
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(interval) * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := GC(ctx, storage.Default(), resolvers, false)
+			if err != nil {
+				log.Printf("upload gc failed: %s", err)
+				continue
+			}
+
+			if len(report.Removed) > 0 {
+				log.Printf(
+					"upload gc removed %d files, reclaimed %s",
+					len(report.Removed),
+					FormatBytes(report.Reclaimed),
+				)
+			}
+		}
+	}
+}
+
+func isOrphaned(key string, resolvers Resolvers) (bool, error) {
+	prefix, id, err := ParseKey(key)
+	if err != nil {
+		return false, nil
+	}
+
+	resolve, ok := resolvers[prefix]
+	if !ok {
+		return false, nil
+	}
+
+	owner, err := resolve(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to find owner of %s: %w", key, err)
+	}
+
+	for _, file := range owner.UploadFiles() {
+		if file != "" && images.IsVariantOf(key, storage.KeyFromModelPath(file), images.DefaultVariants) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// FormatBytes formats a size in bytes into a human readable string
+func FormatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
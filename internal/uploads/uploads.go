@@ -0,0 +1,56 @@
+package uploads
+
+import (
+	"errors"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Owner is implemented by models that have uploaded files stored against them
+type Owner interface {
+	// UploadOwner returns the id of the user that owns the files and if they are visible to other
+	// users
+	UploadOwner() (userId uint, public bool)
+	// UploadFiles returns the model paths of the files currently in use, anything else stored
+	// against the owner is treated as an orphan
+	UploadFiles() []string
+}
+
+// Resolver finds the owner of an upload by the id in its file name
+type Resolver func(id uint) (Owner, error)
+
+// Resolve wraps a repository find method as a Resolver
+func Resolve[T Owner](find func(id uint, userId ...uint) (T, error)) Resolver {
+	return func(id uint) (Owner, error) {
+		owner, err := find(id)
+		if err != nil {
+			return nil, err
+		}
+
+		return owner, nil
+	}
+}
+
+// Resolvers maps the storage key prefix for an upload type to the resolver for its owner
+//
+// eg. coffee/ => coffee.Repository.FindCoffee
+type Resolvers map[string]Resolver
+
+// ParseKey splits a storage key into its type prefix and the id of the model that owns it
+//
+// keys are in the format <prefix>/<id>[.<variant>].<ext>
+func ParseKey(key string) (string, uint, error) {
+	prefix, name := path.Split(key)
+	if prefix == "" {
+		return "", 0, errors.New("upload key has no prefix")
+	}
+
+	id, _, _ := strings.Cut(name, ".")
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || n == 0 {
+		return "", 0, errors.New("upload key has no owner id")
+	}
+
+	return prefix, uint(n), nil
+}